@@ -17,6 +17,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/BYTE-6D65/netadapters/pkg/blobstore"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
 	nethttp "github.com/BYTE-6D65/netadapters/pkg/http"
 	"github.com/BYTE-6D65/pipeline/pkg/engine"
 	"github.com/BYTE-6D65/pipeline/pkg/event"
@@ -91,11 +93,32 @@ func main() {
 	nodeName := getEnv("NODE_NAME", "pipeline-node")
 	metricsAddr := getEnv("METRICS_ADDR", ":9090")
 
+	payloadCodec, ok := codec.Lookup(getEnv("CODEC", "json"))
+	if !ok {
+		log.Fatalf("Unknown CODEC %q (json, cbor, msgpack, binary)", getEnv("CODEC", ""))
+	}
+
 	log.Printf("🔄 RELAY NODE: %s", nodeName)
 	log.Printf("   Adapters: %s", strings.Join(adapterPorts, ", "))
 	log.Printf("   Next Hops: %s", strings.Join(nextHopList, ", "))
 	log.Printf("   Workers: %d", workerCount)
 	log.Printf("   Max Hops: %d", maxHops)
+	log.Printf("   Codec: %s", payloadCodec.Name())
+
+	// Claim check: bodies above the threshold stay on disk instead of riding the bus
+	adapterOpts := []nethttp.Option{nethttp.WithCodec(payloadCodec)}
+	var blobs blobstore.Store
+	if threshold := getEnvInt("CLAIM_CHECK_THRESHOLD", 0); threshold > 0 {
+		dir := getEnv("CLAIM_CHECK_DIR", os.TempDir()+"/relay-blobs")
+		fileStore, err := blobstore.NewFileStore(dir, blobstore.WithTTL(getDuration("CLAIM_CHECK_TTL", 5*time.Minute)))
+		if err != nil {
+			log.Fatalf("Failed to create blob store: %v", err)
+		}
+		defer fileStore.Close()
+		blobs = fileStore
+		adapterOpts = append(adapterOpts, nethttp.WithClaimCheck(blobs, int64(threshold)))
+		log.Printf("   Claim check: >%d bytes → %s", threshold, dir)
+	}
 	log.Printf("   Metrics: %s", metricsAddr)
 
 	metrics := telemetry.InitMetrics(prometheus.DefaultRegisterer)
@@ -159,7 +182,7 @@ func main() {
 			continue
 		}
 
-		srv := nethttp.NewServerAdapter(port)
+		srv := nethttp.NewServerAdapter(port, adapterOpts...)
 		if err := adapterMgr.Register(srv); err != nil {
 			log.Fatalf("Failed to register adapter %s: %v", port, err)
 		}
@@ -219,7 +242,6 @@ func main() {
 		}
 	}()
 
-	codec := event.JSONCodec{}
 	var workerWG sync.WaitGroup
 
 	processEvent := func(evt *event.Event) {
@@ -234,7 +256,7 @@ func main() {
 		relayReceived.WithLabelValues(route.id).Inc()
 
 		var payload nethttp.HTTPRequestPayload
-		if err := evt.DecodePayload(&payload, codec); err != nil {
+		if err := codec.Decode(evt, &payload); err != nil {
 			log.Printf("❌ Decode error (%s): %v", route.id, err)
 			totalStats.errors.Add(1)
 			route.stats.errors.Add(1)
@@ -243,6 +265,9 @@ func main() {
 		}
 
 		payloadSize := len(payload.Body)
+		if payload.BodyRef != nil {
+			payloadSize = int(payload.BodyRef.Size)
+		}
 		if sizeStr, ok := payload.Headers["X-Payload-Size"]; ok {
 			if v, err := strconv.ParseFloat(sizeStr, 64); err == nil {
 				payloadSize = int(v)
@@ -265,22 +290,20 @@ func main() {
 			route.stats.dropped.Add(1)
 			relayDropped.WithLabelValues(route.id).Inc()
 
-			respPayload := nethttp.HTTPResponsePayload{
-				RequestID:  payload.RequestID,
-				StatusCode: 200,
-				Headers:    map[string]string{"Content-Type": "text/plain"},
-				Body:       []byte(fmt.Sprintf("Max hops reached at %s", nodeName)),
-				Timestamp:  time.Now(),
+			respEvt, err := nethttp.Reply(evt).
+				From(nodeName).
+				Text(fmt.Sprintf("Max hops reached at %s", nodeName))
+			if err != nil {
+				log.Printf("❌ Response event error [%s]: %v", route.id, err)
+				return
 			}
-			respEvt, _ := event.NewEvent("net.http.response", nodeName, respPayload, codec)
-			respEvt.WithMetadata("request_id", payload.RequestID)
 			eng.ExternalBus().Publish(context.Background(), respEvt)
 			return
 		}
 
 		go func(p *nethttp.HTTPRequestPayload, hc int, r *adapterRoute) {
 			observer := httpEgressDuration.WithLabelValues(r.id)
-			if err := forwardRequest(r.nextHop, p, hc, nodeName, observer); err != nil {
+			if err := forwardRequest(r.nextHop, p, blobs, hc, nodeName, observer); err != nil {
 				log.Printf("❌ Forward error [%s]: %v", r.id, err)
 				totalStats.errors.Add(1)
 				r.stats.errors.Add(1)
@@ -292,24 +315,15 @@ func main() {
 			}
 		}(&payload, hopCount, route)
 
-		respPayload := nethttp.HTTPResponsePayload{
-			RequestID:  payload.RequestID,
-			StatusCode: 200,
-			Headers: map[string]string{
-				"Content-Type": "text/plain",
-				"X-Relay-Node": nodeName,
-				"X-Hop-Count":  strconv.Itoa(hopCount),
-			},
-			Body:      []byte(fmt.Sprintf("Relayed by %s (hop %d)", nodeName, hopCount)),
-			Timestamp: time.Now(),
-		}
-
-		respEvt, err := event.NewEvent("net.http.response", nodeName, respPayload, codec)
+		respEvt, err := nethttp.Reply(evt).
+			From(nodeName).
+			Header("X-Relay-Node", nodeName).
+			Header("X-Hop-Count", strconv.Itoa(hopCount)).
+			Text(fmt.Sprintf("Relayed by %s (hop %d)", nodeName, hopCount))
 		if err != nil {
 			log.Printf("❌ Response event error [%s]: %v", route.id, err)
 			return
 		}
-		respEvt.WithMetadata("request_id", payload.RequestID)
 		eng.ExternalBus().Publish(context.Background(), respEvt)
 	}
 
@@ -360,13 +374,20 @@ func main() {
 	}
 }
 
-func forwardRequest(nextHop string, payload *nethttp.HTTPRequestPayload, hopCount int, nodeName string, observer prometheus.Observer) error {
+func forwardRequest(nextHop string, payload *nethttp.HTTPRequestPayload, blobs blobstore.Store, hopCount int, nodeName string, observer prometheus.Observer) error {
 	// Create prefix without copying the entire body
 	prefix := []byte(fmt.Sprintf("[%s→hop%d] ", nodeName, hopCount))
 
+	// Claim-checked bodies stream straight from the blob store
+	body, err := payload.OpenBody(context.Background(), blobs)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
 	// Use io.MultiReader to concatenate prefix + body without copying
 	// This creates a reader that reads prefix first, then body, with zero copies
-	bodyReader := io.MultiReader(bytes.NewReader(prefix), bytes.NewReader(payload.Body))
+	bodyReader := io.MultiReader(bytes.NewReader(prefix), body)
 
 	req, err := http.NewRequest("POST", nextHop+payload.Path, bodyReader)
 	if err != nil {
@@ -421,3 +442,12 @@ func parseCSV(value string) []string {
 	}
 	return out
 }
+
+func getDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
@@ -0,0 +1,37 @@
+// Package backoff computes the reconnect delays of the adapters and emitters
+// that redial their peers.
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// StableAfter is how long a connection must stay up before a drop counts as a
+// fresh failure. A peer that accepts and then drops sooner keeps escalating
+// the delay instead of being redialed at the minimum forever.
+const StableAfter = 10 * time.Second
+
+// Policy is an exponential delay range. The zero value is not usable; see Valid.
+type Policy struct {
+	Min time.Duration // Delay after the first failed attempt
+	Max time.Duration // Upper bound of the doubling
+}
+
+// Valid reports whether the range is positive and ordered
+func (p Policy) Valid() bool {
+	return p.Min > 0 && p.Max >= p.Min
+}
+
+// Delay returns the delay before the next dial after attempt failed attempts.
+// It starts at Min, doubles with every attempt up to Max, and has ±20% jitter.
+func (p Policy) Delay(attempt int) time.Duration {
+	delay := p.Min
+	for i := 1; i < attempt && delay < p.Max; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.Max)
+
+	jitter := (rand.Float64()*0.4 - 0.2) * float64(delay)
+	return delay + time.Duration(jitter)
+}
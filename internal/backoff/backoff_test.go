@@ -0,0 +1,44 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicy_Delay(t *testing.T) {
+	p := Policy{Min: 100 * time.Millisecond, Max: time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 4, want: 800 * time.Millisecond},
+		{attempt: 10, want: time.Second},
+	}
+	for _, tt := range tests {
+		got := p.Delay(tt.attempt)
+		lo, hi := tt.want*8/10, tt.want*12/10
+		if got < lo || got > hi {
+			t.Errorf("attempt %d: expected %v ±20%%, got %v", tt.attempt, tt.want, got)
+		}
+	}
+}
+
+func TestPolicy_Valid(t *testing.T) {
+	tests := []struct {
+		policy Policy
+		valid  bool
+	}{
+		{Policy{Min: time.Second, Max: time.Minute}, true},
+		{Policy{Min: time.Second, Max: time.Second}, true},
+		{Policy{Min: 0, Max: time.Minute}, false},
+		{Policy{Min: time.Minute, Max: time.Second}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Valid(); got != tt.valid {
+			t.Errorf("%+v: expected valid=%v, got %v", tt.policy, tt.valid, got)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Package blobstore holds large payload bodies outside of events.
+//
+// Adapters offload bodies above a size threshold to a Store and publish a Ref
+// instead (the claim-check pattern); emitters resolve the Ref when writing.
+// Blobs that are not accessed within the store's TTL are garbage-collected.
+package blobstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+)
+
+// ErrNotFound is returned when a blob does not exist or has expired
+var ErrNotFound = errors.New("blob not found")
+
+// DefaultTTL is how long an unaccessed blob is kept when no TTL is configured
+const DefaultTTL = 10 * time.Minute
+
+// Ref is a claim check for a blob: the event carries this instead of the body
+type Ref struct {
+	Key    string `json:"key"`    // Store-specific blob key
+	Size   int64  `json:"size"`   // Body size in bytes
+	SHA256 string `json:"sha256"` // Hex-encoded SHA-256 of the body
+}
+
+// Store persists blobs for later retrieval by Ref
+type Store interface {
+	// Put stores the contents of r and returns its reference
+	Put(ctx context.Context, r io.Reader) (Ref, error)
+
+	// Get opens a blob; reading it to EOF verifies size and hash
+	Get(ctx context.Context, ref Ref) (io.ReadCloser, error)
+
+	// Delete removes a blob (deleting a missing blob is not an error)
+	Delete(ctx context.Context, ref Ref) error
+
+	// Close stops garbage collection and releases resources
+	Close() error
+}
+
+// Option configures a Store
+type Option func(*options)
+
+// options holds the settings shared by store implementations
+type options struct {
+	ttl           time.Duration
+	sweepInterval time.Duration
+	clk           clock.Clock
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{ttl: DefaultTTL, clk: clock.System()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.sweepInterval <= 0 {
+		o.sweepInterval = o.ttl / 2
+	}
+	return o
+}
+
+// WithTTL sets how long a blob is kept after it was last stored or read
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		if ttl > 0 {
+			o.ttl = ttl
+		}
+	}
+}
+
+// WithSweepInterval sets how often expired blobs are collected (defaults to TTL/2)
+func WithSweepInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.sweepInterval = d
+	}
+}
+
+// WithClock sets the clock access times and the garbage collector's
+// schedule are taken from (defaults to the system clock)
+func WithClock(clk clock.Clock) Option {
+	return func(o *options) {
+		if clk != nil {
+			o.clk = clk
+		}
+	}
+}
+
+// hashingReader counts and hashes bytes as they are read
+type hashingReader struct {
+	r    io.Reader
+	h    hash.Hash
+	size int64
+}
+
+func newHashingReader(r io.Reader) *hashingReader {
+	return &hashingReader{r: r, h: sha256.New()}
+}
+
+func (hr *hashingReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.h.Write(p[:n])
+	hr.size += int64(n)
+	return n, err
+}
+
+// sum returns the hex-encoded digest of everything read so far
+func (hr *hashingReader) sum() string {
+	return hex.EncodeToString(hr.h.Sum(nil))
+}
+
+// verifyingReader returns an error at EOF if the blob does not match its Ref
+type verifyingReader struct {
+	*hashingReader
+	closer io.Closer
+	ref    Ref
+}
+
+func newVerifyingReader(rc io.ReadCloser, ref Ref) io.ReadCloser {
+	return &verifyingReader{hashingReader: newHashingReader(rc), closer: rc, ref: ref}
+}
+
+func (vr *verifyingReader) Read(p []byte) (int, error) {
+	n, err := vr.hashingReader.Read(p)
+	if err == io.EOF {
+		if vr.size != vr.ref.Size {
+			return n, fmt.Errorf("blob %s: size mismatch: expected %d, got %d", vr.ref.Key, vr.ref.Size, vr.size)
+		}
+		if vr.ref.SHA256 != "" && vr.sum() != vr.ref.SHA256 {
+			return n, fmt.Errorf("blob %s: hash mismatch", vr.ref.Key)
+		}
+	}
+	return n, err
+}
+
+func (vr *verifyingReader) Close() error {
+	return vr.closer.Close()
+}
+
+// runJanitor calls sweep every interval of clk until stop is closed
+func runJanitor(clk clock.Clock, interval time.Duration, stop <-chan struct{}, sweep func(now time.Time) int) {
+	ticker := clk.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			sweep(clk.Wall())
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+)
+
+// sweeper is implemented by both stores
+type sweeper interface {
+	Store
+	Sweep(now time.Time) int
+}
+
+func newTestStores(t *testing.T) map[string]sweeper {
+	t.Helper()
+
+	fileStore, err := NewFileStore(t.TempDir(), WithTTL(time.Minute), WithSweepInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	t.Cleanup(func() { fileStore.Close() })
+
+	memStore := NewMemoryStore(WithTTL(time.Minute), WithSweepInterval(time.Hour))
+	t.Cleanup(func() { memStore.Close() })
+
+	return map[string]sweeper{"memory": memStore, "file": fileStore}
+}
+
+func TestStores_PutGet(t *testing.T) {
+	data := bytes.Repeat([]byte("claim-check "), 1000)
+
+	for name, store := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ref, err := store.Put(context.Background(), bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Failed to put blob: %v", err)
+			}
+			if ref.Size != int64(len(data)) {
+				t.Errorf("Expected size %d, got %d", len(data), ref.Size)
+			}
+			if len(ref.SHA256) != 64 {
+				t.Errorf("Expected hex SHA-256, got %q", ref.SHA256)
+			}
+
+			rc, err := store.Get(context.Background(), ref)
+			if err != nil {
+				t.Fatalf("Failed to get blob: %v", err)
+			}
+			defer rc.Close()
+
+			got, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("Failed to read blob: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Error("Blob contents mismatch")
+			}
+		})
+	}
+}
+
+func TestStores_VerifyMismatch(t *testing.T) {
+	for name, store := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ref, err := store.Put(context.Background(), strings.NewReader("payload"))
+			if err != nil {
+				t.Fatalf("Failed to put blob: %v", err)
+			}
+
+			tampered := ref
+			tampered.SHA256 = strings.Repeat("0", 64)
+			rc, err := store.Get(context.Background(), tampered)
+			if err != nil {
+				t.Fatalf("Failed to get blob: %v", err)
+			}
+			defer rc.Close()
+
+			if _, err := io.ReadAll(rc); err == nil {
+				t.Error("Expected hash mismatch error, got nil")
+			}
+		})
+	}
+}
+
+func TestStores_DeleteAndNotFound(t *testing.T) {
+	for name, store := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ref, err := store.Put(context.Background(), strings.NewReader("payload"))
+			if err != nil {
+				t.Fatalf("Failed to put blob: %v", err)
+			}
+			if err := store.Delete(context.Background(), ref); err != nil {
+				t.Fatalf("Failed to delete blob: %v", err)
+			}
+			if err := store.Delete(context.Background(), ref); err != nil {
+				t.Errorf("Expected deleting a missing blob to succeed, got %v", err)
+			}
+
+			if _, err := store.Get(context.Background(), ref); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStores_SweepByTTL(t *testing.T) {
+	for name, store := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ref, err := store.Put(context.Background(), strings.NewReader("payload"))
+			if err != nil {
+				t.Fatalf("Failed to put blob: %v", err)
+			}
+
+			if removed := store.Sweep(time.Now()); removed != 0 {
+				t.Errorf("Expected fresh blob to survive, removed %d", removed)
+			}
+			if removed := store.Sweep(time.Now().Add(2 * time.Minute)); removed != 1 {
+				t.Errorf("Expected expired blob to be removed, removed %d", removed)
+			}
+			if _, err := store.Get(context.Background(), ref); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound after sweep, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStores_JanitorUsesClock(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC))
+	fileStore, err := NewFileStore(t.TempDir(), WithTTL(time.Minute), WithSweepInterval(30*time.Second), WithClock(clk))
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	defer fileStore.Close()
+	memStore := NewMemoryStore(WithTTL(time.Minute), WithSweepInterval(30*time.Second), WithClock(clk))
+	defer memStore.Close()
+
+	// Counted without Get, which would refresh the TTL
+	stored := map[string]func() int{
+		"memory": memStore.Len,
+		"file": func() int {
+			entries, _ := os.ReadDir(fileStore.Dir())
+			return len(entries)
+		},
+	}
+	for _, store := range []Store{memStore, fileStore} {
+		if _, err := store.Put(context.Background(), strings.NewReader("payload")); err != nil {
+			t.Fatalf("Failed to put blob: %v", err)
+		}
+	}
+
+	// Both janitors tick on the fake clock; a minute later the blobs expire
+	clk.BlockUntil(2)
+	clk.Advance(time.Minute)
+
+	for name, count := range stored {
+		deadline := time.Now().Add(2 * time.Second)
+		for count() != 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if n := count(); n != 0 {
+			t.Errorf("Expected the %s store's janitor to remove the blob, %d left", name, n)
+		}
+	}
+}
+
+func TestFileStore_RejectsPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	defer store.Close()
+
+	os.WriteFile(filepath.Join(dir, "secret.blob"), []byte("secret"), 0o600)
+
+	if _, err := store.Get(context.Background(), Ref{Key: "../secret"}); err == nil {
+		t.Error("Expected error for key escaping the store directory, got nil")
+	}
+}
@@ -0,0 +1,156 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// blobSuffix marks completed blob files; partial writes use a temp name
+const blobSuffix = ".blob"
+
+// FileStore keeps blobs as files in a local directory.
+// A blob's modification time records its last access, so TTLs survive restarts.
+type FileStore struct {
+	dir  string
+	opts options
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewFileStore creates a store rooted at dir and starts its garbage collector
+func NewFileStore(dir string, opts ...Option) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	s := &FileStore{
+		dir:  dir,
+		opts: newOptions(opts),
+		stop: make(chan struct{}),
+	}
+	go runJanitor(s.opts.clk, s.opts.sweepInterval, s.stop, s.Sweep)
+	return s, nil
+}
+
+// Dir returns the directory holding the blobs
+func (s *FileStore) Dir() string {
+	return s.dir
+}
+
+// path returns the file path for a blob key, rejecting keys that escape the directory
+func (s *FileStore) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, key+blobSuffix), nil
+}
+
+// Put streams the contents of r to a new file
+func (s *FileStore) Put(ctx context.Context, r io.Reader) (Ref, error) {
+	tmp, err := os.CreateTemp(s.dir, "put-*.tmp")
+	if err != nil {
+		return Ref{}, fmt.Errorf("failed to create blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op after a successful rename
+
+	hr := newHashingReader(r)
+	if _, err := io.Copy(tmp, hr); err != nil {
+		tmp.Close()
+		return Ref{}, fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return Ref{}, fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	ref := Ref{
+		Key:    uuid.New().String(),
+		Size:   hr.size,
+		SHA256: hr.sum(),
+	}
+
+	path, _ := s.path(ref.Key)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Ref{}, fmt.Errorf("failed to commit blob: %w", err)
+	}
+	now := s.opts.clk.Wall()
+	os.Chtimes(path, now, now)
+	return ref, nil
+}
+
+// Get opens a blob file and refreshes its TTL
+func (s *FileStore) Get(ctx context.Context, ref Ref) (io.ReadCloser, error) {
+	path, err := s.path(ref.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	now := s.opts.clk.Wall()
+	os.Chtimes(path, now, now)
+
+	return newVerifyingReader(f, ref), nil
+}
+
+// Delete removes a blob file
+func (s *FileStore) Delete(ctx context.Context, ref Ref) error {
+	path, err := s.path(ref.Key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
+
+// Sweep removes blobs not accessed within the TTL and returns how many were removed.
+// Abandoned temp files from interrupted writes are removed as well.
+func (s *FileStore) Sweep(now time.Time) int {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, blobSuffix) || strings.HasSuffix(name, ".tmp")) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < s.opts.ttl {
+			continue
+		}
+		if os.Remove(filepath.Join(s.dir, name)) == nil && strings.HasSuffix(name, blobSuffix) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// Close stops the garbage collector; stored blobs remain on disk
+func (s *FileStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
@@ -0,0 +1,117 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryBlob is a stored body and its last access time
+type memoryBlob struct {
+	data     []byte
+	accessed time.Time
+}
+
+// MemoryStore keeps blobs in process memory
+type MemoryStore struct {
+	opts options
+
+	mu    sync.Mutex
+	blobs map[string]*memoryBlob
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMemoryStore creates an in-memory store and starts its garbage collector
+func NewMemoryStore(opts ...Option) *MemoryStore {
+	s := &MemoryStore{
+		opts:  newOptions(opts),
+		blobs: make(map[string]*memoryBlob),
+		stop:  make(chan struct{}),
+	}
+	go runJanitor(s.opts.clk, s.opts.sweepInterval, s.stop, s.Sweep)
+	return s
+}
+
+// Put stores the contents of r
+func (s *MemoryStore) Put(ctx context.Context, r io.Reader) (Ref, error) {
+	hr := newHashingReader(r)
+	data, err := io.ReadAll(hr)
+	if err != nil {
+		return Ref{}, fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	ref := Ref{
+		Key:    uuid.New().String(),
+		Size:   hr.size,
+		SHA256: hr.sum(),
+	}
+
+	s.mu.Lock()
+	s.blobs[ref.Key] = &memoryBlob{data: data, accessed: s.opts.clk.Wall()}
+	s.mu.Unlock()
+
+	return ref, nil
+}
+
+// Get opens a blob and refreshes its TTL
+func (s *MemoryStore) Get(ctx context.Context, ref Ref) (io.ReadCloser, error) {
+	s.mu.Lock()
+	blob, ok := s.blobs[ref.Key]
+	if ok {
+		blob.accessed = s.opts.clk.Wall()
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref.Key)
+	}
+	return newVerifyingReader(io.NopCloser(bytes.NewReader(blob.data)), ref), nil
+}
+
+// Delete removes a blob
+func (s *MemoryStore) Delete(ctx context.Context, ref Ref) error {
+	s.mu.Lock()
+	delete(s.blobs, ref.Key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of stored blobs
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.blobs)
+}
+
+// Sweep removes blobs not accessed within the TTL and returns how many were removed
+func (s *MemoryStore) Sweep(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for key, blob := range s.blobs {
+		if now.Sub(blob.accessed) >= s.opts.ttl {
+			delete(s.blobs, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Close stops the garbage collector and drops all blobs
+func (s *MemoryStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		s.mu.Lock()
+		s.blobs = make(map[string]*memoryBlob)
+		s.mu.Unlock()
+	})
+	return nil
+}
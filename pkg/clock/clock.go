@@ -0,0 +1,114 @@
+// Package clock extends the pipeline's monotonic clock with wall time, timers
+// and tickers, so adapters can take every timestamp, timeout and interval from
+// the clock they are started with.
+//
+// Adapters receive a pipeline clock.Clock in Start and convert it with From.
+// A Fake is a Clock that only moves when advanced, for testing timeouts and
+// periodic work without waiting on real time.
+package clock
+
+import (
+	"time"
+
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+)
+
+// MonoTime is a monotonic reading, in nanoseconds, from a Clock
+type MonoTime = pipeclock.MonoTime
+
+// ToDuration converts a difference between two MonoTimes to a duration
+func ToDuration(m MonoTime) time.Duration {
+	return pipeclock.ToDuration(m)
+}
+
+// Clock is a pipeline clock that also tells wall time and creates timers.
+// Implementations are safe for concurrent use.
+type Clock interface {
+	pipeclock.Clock
+
+	// Wall returns the current wall-clock time, for timestamps
+	Wall() time.Time
+
+	// NewTimer creates a Timer that fires once after d
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker creates a Ticker that fires every d; d must be positive
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event, like time.Timer
+type Timer interface {
+	// C returns the channel the current time is sent on when the timer fires
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing and reports whether it was active
+	Stop() bool
+
+	// Reset rearms the timer to fire after d and reports whether it was active
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker. Ticks are dropped
+// while the previous one has not been received.
+type Ticker interface {
+	// C returns the channel ticks are sent on
+	C() <-chan time.Time
+
+	// Stop turns the ticker off
+	Stop()
+
+	// Reset stops the ticker and restarts it with period d
+	Reset(d time.Duration)
+}
+
+// System returns a Clock backed by the system clock
+func System() Clock {
+	return systemClock{Clock: pipeclock.NewSystemClock()}
+}
+
+// From returns c as a Clock. A c that already implements Clock is returned as
+// is, nil gives System, and any other pipeline clock keeps its monotonic
+// readings with system wall time and timers.
+func From(c pipeclock.Clock) Clock {
+	switch c := c.(type) {
+	case nil:
+		return System()
+	case Clock:
+		return c
+	default:
+		return systemClock{Clock: c}
+	}
+}
+
+// systemClock takes wall time and timers from package time
+type systemClock struct {
+	pipeclock.Clock
+}
+
+func (systemClock) Wall() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,120 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+)
+
+var _ Clock = (*Fake)(nil)
+
+func TestFake_Timer(t *testing.T) {
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	timer := f.NewTimer(time.Second)
+	f.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("Timer fired early")
+	default:
+	}
+
+	f.Advance(time.Millisecond)
+	select {
+	case at := <-timer.C():
+		if !at.Equal(start.Add(time.Second)) {
+			t.Errorf("Expected fire time %v, got %v", start.Add(time.Second), at)
+		}
+	default:
+		t.Fatal("Timer did not fire")
+	}
+	if timer.Stop() {
+		t.Error("Expected Stop on a fired timer to report inactive")
+	}
+
+	if timer.Reset(time.Second) {
+		t.Error("Expected Reset on a fired timer to report inactive")
+	}
+	if !timer.Stop() {
+		t.Error("Expected Stop on a reset timer to report active")
+	}
+	f.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Error("Stopped timer fired")
+	default:
+	}
+
+	if got := f.Since(0); got != time.Hour+time.Second {
+		t.Errorf("Expected %v elapsed, got %v", time.Hour+time.Second, got)
+	}
+	if got := f.Wall(); !got.Equal(start.Add(time.Hour + time.Second)) {
+		t.Errorf("Unexpected wall time %v", got)
+	}
+}
+
+func TestFake_Ticker(t *testing.T) {
+	f := NewFake(time.Time{})
+	ticker := f.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	// Ticks are dropped while the channel is full, like time.Ticker
+	f.Advance(35 * time.Millisecond)
+	if at := <-ticker.C(); at != (time.Time{}).Add(10*time.Millisecond) {
+		t.Errorf("Expected the first tick at 10ms, got %v", at)
+	}
+	select {
+	case <-ticker.C():
+		t.Error("Expected later ticks to be dropped")
+	default:
+	}
+
+	f.Advance(5 * time.Millisecond)
+	select {
+	case <-ticker.C():
+	default:
+		t.Error("Expected a tick at 40ms")
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	f := NewFake(time.Time{})
+	fired := make(chan struct{})
+	go func() {
+		<-f.NewTimer(time.Minute).C()
+		close(fired)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("Timer did not fire after BlockUntil and Advance")
+	}
+}
+
+func TestFrom(t *testing.T) {
+	if _, ok := From(nil).(systemClock); !ok {
+		t.Error("Expected From(nil) to return the system clock")
+	}
+
+	f := NewFake(time.Time{})
+	if From(f) != Clock(f) {
+		t.Error("Expected From to return a Clock unchanged")
+	}
+
+	mono := pipeclock.NewSystemClock()
+	c := From(mono)
+	if c.Since(c.Now()) < 0 {
+		t.Error("Expected monotonic readings from the wrapped clock")
+	}
+	timer := c.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Error("System timer did not fire")
+	}
+}
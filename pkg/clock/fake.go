@@ -0,0 +1,197 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that stands still until Advance is called. Timers and
+// tickers fire during Advance, in deadline order, as their deadlines pass.
+//
+// Code under test usually creates its timers on another goroutine; call
+// BlockUntil before Advance so the timers exist when time moves.
+type Fake struct {
+	mu      sync.Mutex
+	start   time.Time
+	elapsed time.Duration
+	waiters []*fakeWaiter
+	changed chan struct{} // Closed and replaced when waiters are added
+}
+
+// NewFake creates a fake clock whose wall time starts at start
+func NewFake(start time.Time) *Fake {
+	return &Fake{start: start, changed: make(chan struct{})}
+}
+
+// Now returns the time elapsed since the fake was created
+func (f *Fake) Now() MonoTime {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return MonoTime(f.elapsed)
+}
+
+// Since returns the fake time elapsed since t
+func (f *Fake) Since(t MonoTime) time.Duration {
+	return ToDuration(f.Now() - t)
+}
+
+// Wall returns the start time plus the time advanced
+func (f *Fake) Wall() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.start.Add(f.elapsed)
+}
+
+// NewTimer creates a timer that fires once Advance passes d from now
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{f: f, c: make(chan time.Time, 1)}
+	w.reset(d, 0)
+	return fakeTimer{w}
+}
+
+// NewTicker creates a ticker that fires each time Advance passes another d
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{f: f, c: make(chan time.Time, 1)}
+	w.reset(d, d)
+	return fakeTicker{w}
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker whose
+// deadline is reached on the way
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	target := f.elapsed + d
+	for {
+		next := f.nextDue(target)
+		if next == nil {
+			break
+		}
+		f.elapsed = next.deadline
+		next.fire(f.start.Add(f.elapsed))
+	}
+	f.elapsed = target
+}
+
+// BlockUntil waits until at least n timers and tickers are active
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		active, changed := len(f.waiters), f.changed
+		f.mu.Unlock()
+		if active >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+// nextDue returns the waiter with the earliest deadline at or before target
+func (f *Fake) nextDue(target time.Duration) *fakeWaiter {
+	var next *fakeWaiter
+	for _, w := range f.waiters {
+		if w.deadline <= target && (next == nil || w.deadline < next.deadline) {
+			next = w
+		}
+	}
+	return next
+}
+
+// fakeWaiter is a pending timer or ticker; its fields are guarded by f.mu
+type fakeWaiter struct {
+	f        *Fake
+	c        chan time.Time
+	deadline time.Duration // Elapsed time at which it fires
+	period   time.Duration // Ticker period, 0 for timers
+	active   bool
+}
+
+// fire sends now without blocking and rearms a ticker or retires a timer
+func (w *fakeWaiter) fire(now time.Time) {
+	select {
+	case w.c <- now:
+	default:
+	}
+	if w.period > 0 {
+		w.deadline += w.period
+		return
+	}
+	w.stop()
+}
+
+// reset arms the waiter d from now and reports whether it was active
+func (w *fakeWaiter) reset(d, period time.Duration) bool {
+	w.f.mu.Lock()
+	defer w.f.mu.Unlock()
+
+	wasActive := w.active
+	w.deadline = w.f.elapsed + max(d, 0)
+	w.period = period
+	if !w.active {
+		w.active = true
+		w.f.waiters = append(w.f.waiters, w)
+		close(w.f.changed)
+		w.f.changed = make(chan struct{})
+	}
+	return wasActive
+}
+
+// stop disarms the waiter and reports whether it was active; f.mu must be held
+func (w *fakeWaiter) stop() bool {
+	if !w.active {
+		return false
+	}
+	w.active = false
+	w.f.waiters = slices.DeleteFunc(w.f.waiters, func(o *fakeWaiter) bool { return o == w })
+	return true
+}
+
+// lockedStop is stop for callers outside the fake
+func (w *fakeWaiter) lockedStop() bool {
+	w.f.mu.Lock()
+	defer w.f.mu.Unlock()
+
+	return w.stop()
+}
+
+type fakeTimer struct {
+	w *fakeWaiter
+}
+
+func (t fakeTimer) C() <-chan time.Time {
+	return t.w.c
+}
+
+func (t fakeTimer) Stop() bool {
+	return t.w.lockedStop()
+}
+
+func (t fakeTimer) Reset(d time.Duration) bool {
+	return t.w.reset(d, 0)
+}
+
+type fakeTicker struct {
+	w *fakeWaiter
+}
+
+func (t fakeTicker) C() <-chan time.Time {
+	return t.w.c
+}
+
+func (t fakeTicker) Stop() {
+	t.w.lockedStop()
+}
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.w.reset(d, d)
+}
@@ -0,0 +1,219 @@
+// Package codec provides named payload codecs for network events.
+//
+// The codec used to encode an event is recorded in its "codec" metadata so
+// that decoders can select the matching codec automatically with ForEvent.
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MetadataKey is the event metadata key holding the codec name
+const MetadataKey = "codec"
+
+// Codec is an event.Codec with a registered name
+type Codec interface {
+	event.Codec
+	Name() string
+}
+
+// Registry of codecs by name
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Codec{}
+)
+
+func init() {
+	Register(JSON{})
+	Register(CBOR{})
+	Register(MsgPack{})
+	Register(Binary{})
+}
+
+// Register makes a codec available to Lookup and ForEvent
+func Register(c Codec) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[c.Name()] = c
+}
+
+// Lookup returns the codec registered under name
+func Lookup(name string) (Codec, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := registry[name]
+	return c, ok
+}
+
+// ForEvent returns the codec named in the event's metadata.
+// Events without codec metadata use fallback, or JSON if fallback is nil.
+func ForEvent(evt *event.Event, fallback Codec) (Codec, error) {
+	name := evt.Metadata[MetadataKey]
+	if name == "" {
+		if fallback == nil {
+			return JSON{}, nil
+		}
+		return fallback, nil
+	}
+
+	c, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %q", name)
+	}
+	return c, nil
+}
+
+// NewEvent creates an event encoded with c and records the codec in its metadata
+func NewEvent(eventType, source string, payload any, c Codec) (*event.Event, error) {
+	evt, err := event.NewEvent(eventType, source, payload, c)
+	if err != nil {
+		return nil, err
+	}
+	evt.WithMetadata(MetadataKey, c.Name())
+	return evt, nil
+}
+
+// Decode decodes an event's payload with the codec named in its metadata
+func Decode(evt *event.Event, v any) error {
+	c, err := ForEvent(evt, nil)
+	if err != nil {
+		return err
+	}
+	return evt.DecodePayload(v, c)
+}
+
+// JSON is the pipeline's JSON codec (byte slices are base64-encoded)
+type JSON struct {
+	event.JSONCodec
+}
+
+// Name returns "json"
+func (JSON) Name() string { return "json" }
+
+// cborEnc preserves nanosecond timestamps, which the default mode truncates
+var cborEnc = func() cbor.EncMode {
+	mode, err := cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}()
+
+// CBOR encodes payloads as RFC 8949 CBOR (byte slices are stored raw)
+type CBOR struct{}
+
+// Name returns "cbor"
+func (CBOR) Name() string { return "cbor" }
+
+// Marshal encodes v as CBOR
+func (CBOR) Marshal(v any) ([]byte, error) {
+	return cborEnc.Marshal(v)
+}
+
+// Unmarshal decodes CBOR data into v
+func (CBOR) Unmarshal(data []byte, v any) error {
+	return cbor.Unmarshal(data, v)
+}
+
+// MsgPack encodes payloads as MessagePack, honoring json struct tags
+type MsgPack struct{}
+
+// Name returns "msgpack"
+func (MsgPack) Name() string { return "msgpack" }
+
+// Marshal encodes v as MessagePack
+func (MsgPack) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes MessagePack data into v
+func (MsgPack) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// Binary is a compact framing that stores a struct's Body []byte field raw.
+//
+// Frame layout: uvarint header length, CBOR header (the payload with Body
+// cleared), then the body bytes. Payloads without a Body field are framed
+// with an empty body. Decoded bodies alias the frame, avoiding a copy.
+type Binary struct{}
+
+// Name returns "binary"
+func (Binary) Name() string { return "binary" }
+
+// Marshal frames v with its body stored raw
+func (Binary) Marshal(v any) ([]byte, error) {
+	header := v
+	var body []byte
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if field, ok := bodyField(rv); ok {
+		body = field.Bytes()
+		clone := reflect.New(rv.Type()).Elem()
+		clone.Set(rv)
+		clone.FieldByName("Body").SetBytes(nil)
+		header = clone.Interface()
+	}
+
+	head, err := cborEnc.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := make([]byte, 0, binary.MaxVarintLen64+len(head)+len(body))
+	frame = binary.AppendUvarint(frame, uint64(len(head)))
+	frame = append(frame, head...)
+	frame = append(frame, body...)
+	return frame, nil
+}
+
+// Unmarshal decodes a frame into v, restoring its Body field
+func (Binary) Unmarshal(data []byte, v any) error {
+	headLen, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < headLen {
+		return fmt.Errorf("binary codec: malformed frame header")
+	}
+	head := data[n : n+int(headLen)]
+	body := data[n+int(headLen):]
+
+	if err := cbor.Unmarshal(head, v); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer {
+		return nil
+	}
+	if field, ok := bodyField(rv.Elem()); ok && len(body) > 0 {
+		field.SetBytes(body)
+	}
+	return nil
+}
+
+// bodyField returns a struct's settable-or-readable Body []byte field
+func bodyField(rv reflect.Value) (reflect.Value, bool) {
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	field := rv.FieldByName("Body")
+	if !field.IsValid() || field.Kind() != reflect.Slice || field.Type().Elem().Kind() != reflect.Uint8 {
+		return reflect.Value{}, false
+	}
+	return field, true
+}
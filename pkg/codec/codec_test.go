@@ -0,0 +1,166 @@
+package codec_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	nethttp "github.com/BYTE-6D65/netadapters/pkg/http"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+var allCodecs = []codec.Codec{codec.JSON{}, codec.CBOR{}, codec.MsgPack{}, codec.Binary{}}
+
+func samplePayload(size int) nethttp.HTTPRequestPayload {
+	body := make([]byte, size)
+	rand.Read(body)
+
+	return nethttp.HTTPRequestPayload{
+		RequestID: "3f1c2b9e-6a8d-4d0e-9b7f-2c1d5e8a9f00",
+		Method:    "POST",
+		Path:      "/api/test",
+		Query:     map[string]string{"trace": "1"},
+		Headers: map[string]string{
+			"Content-Type":   "application/octet-stream",
+			"X-Request-Id":   "req-1",
+			"X-Payload-Size": fmt.Sprint(size),
+		},
+		Body:       body,
+		RemoteAddr: "10.0.0.7:53122",
+		LocalAddr:  ":8080",
+		Timestamp:  time.Date(2025, 10, 15, 12, 30, 45, 123456789, time.UTC),
+	}
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	want := samplePayload(1024)
+
+	for _, c := range allCodecs {
+		t.Run(c.Name(), func(t *testing.T) {
+			evt, err := codec.NewEvent("net.http.request", "test", want, c)
+			if err != nil {
+				t.Fatalf("Failed to create event: %v", err)
+			}
+			if evt.Metadata[codec.MetadataKey] != c.Name() {
+				t.Errorf("Expected codec metadata %s, got %s", c.Name(), evt.Metadata[codec.MetadataKey])
+			}
+
+			var got nethttp.HTTPRequestPayload
+			if err := codec.Decode(evt, &got); err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+
+			if got.RequestID != want.RequestID || got.Method != want.Method || got.Path != want.Path {
+				t.Errorf("Scalar fields mismatch: got %+v", got)
+			}
+			if got.Headers["X-Request-Id"] != "req-1" || got.Query["trace"] != "1" {
+				t.Errorf("Map fields mismatch: headers=%v query=%v", got.Headers, got.Query)
+			}
+			if !bytes.Equal(got.Body, want.Body) {
+				t.Error("Body mismatch after round trip")
+			}
+			if !got.Timestamp.Equal(want.Timestamp) {
+				t.Errorf("Expected timestamp %v, got %v", want.Timestamp, got.Timestamp)
+			}
+		})
+	}
+}
+
+func TestBinary_NoBodyField(t *testing.T) {
+	type ping struct {
+		Seq int `json:"seq"`
+	}
+
+	data, err := codec.Binary{}.Marshal(ping{Seq: 7})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var got ping
+	if err := (codec.Binary{}).Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if got.Seq != 7 {
+		t.Errorf("Expected seq 7, got %d", got.Seq)
+	}
+}
+
+func TestBinary_MalformedFrame(t *testing.T) {
+	var got nethttp.HTTPRequestPayload
+	if err := (codec.Binary{}).Unmarshal([]byte{0xff}, &got); err == nil {
+		t.Error("Expected error for malformed frame, got nil")
+	}
+	if err := (codec.Binary{}).Unmarshal([]byte{0x10, 0x01}, &got); err == nil {
+		t.Error("Expected error for truncated header, got nil")
+	}
+}
+
+func TestForEvent(t *testing.T) {
+	evt := &event.Event{Metadata: map[string]string{}}
+
+	c, err := codec.ForEvent(evt, nil)
+	if err != nil || c.Name() != "json" {
+		t.Errorf("Expected JSON default, got %v (err %v)", c, err)
+	}
+
+	c, err = codec.ForEvent(evt, codec.CBOR{})
+	if err != nil || c.Name() != "cbor" {
+		t.Errorf("Expected fallback codec cbor, got %v (err %v)", c, err)
+	}
+
+	evt.Metadata[codec.MetadataKey] = "msgpack"
+	c, err = codec.ForEvent(evt, codec.CBOR{})
+	if err != nil || c.Name() != "msgpack" {
+		t.Errorf("Expected metadata codec msgpack, got %v (err %v)", c, err)
+	}
+
+	evt.Metadata[codec.MetadataKey] = "avro"
+	if _, err := codec.ForEvent(evt, nil); err == nil {
+		t.Error("Expected error for unknown codec, got nil")
+	}
+}
+
+// Payload sizes from the relay-initiator ramp (PAYLOAD_START=1KB up to PAYLOAD_MAX=100MB)
+var benchSizes = []struct {
+	name string
+	size int
+}{
+	{"1KB", 1 << 10},
+	{"64KB", 64 << 10},
+	{"1MB", 1 << 20},
+	{"16MB", 16 << 20},
+	{"100MB", 100 << 20},
+}
+
+// BenchmarkRequestCodecs measures encode + decode of one request event.
+// The wire-bytes/op metric is the encoded event size.
+func BenchmarkRequestCodecs(b *testing.B) {
+	for _, size := range benchSizes {
+		payload := samplePayload(size.size)
+
+		for _, c := range allCodecs {
+			b.Run(fmt.Sprintf("%s/%s", size.name, c.Name()), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(size.size))
+
+				var wire int
+				for i := 0; i < b.N; i++ {
+					data, err := c.Marshal(payload)
+					if err != nil {
+						b.Fatal(err)
+					}
+					wire = len(data)
+
+					var out nethttp.HTTPRequestPayload
+					if err := c.Unmarshal(data, &out); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(wire), "wire-bytes/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package correlate matches replies to the requests waiting for them.
+//
+// A Table holds pending requests by correlation ID. It serves two patterns.
+// In the first, a caller waits for a reply to be delivered to it. The caller
+// registers a handle, such as an HTTP response writer, and blocks in Wait. A
+// replier looks the entry up, claims it, writes through the handle and
+// finishes it. In the second, a Requester publishes a request event and
+// returns the reply event correlated with it.
+//
+// Each entry completes at most once. Claim, Cancel and an expiring deadline
+// race for it, and exactly one of them wins.
+package correlate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+)
+
+var (
+	// ErrTimeout completes an entry whose deadline passed before it was claimed
+	ErrTimeout = errors.New("correlate: no reply before deadline")
+
+	// ErrCanceled completes an entry canceled before it was claimed
+	ErrCanceled = errors.New("correlate: request canceled")
+
+	// ErrDuplicate is returned when registering an ID that is already pending
+	ErrDuplicate = errors.New("correlate: duplicate correlation ID")
+)
+
+// Table holds pending requests, each carrying a handle of type T
+type Table[T any] struct {
+	opts options
+
+	mu      sync.Mutex
+	pending map[string]*Pending[T]
+	stats   Stats
+}
+
+// Stats counts a table's entries since it was created. It is the
+// NetworkMetrics Replies section, so adapters can report it as is.
+type Stats = metrics.ReplyStats
+
+// NewTable creates an empty table. Deadlines run on the clock set with
+// WithClock (the system clock by default).
+func NewTable[T any](opts ...Option) *Table[T] {
+	return &Table[T]{
+		opts:    newOptions(opts),
+		pending: make(map[string]*Pending[T]),
+	}
+}
+
+// Register adds a pending entry for id carrying handle. If timeout is
+// positive, the entry expires with ErrTimeout unless it is claimed first.
+func (t *Table[T]) Register(id string, handle T, timeout time.Duration) (*Pending[T], error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.pending[id]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicate, id)
+	}
+
+	p := &Pending[T]{
+		table:      t,
+		id:         id,
+		handle:     handle,
+		registered: t.opts.clk.Now(),
+		done:       make(chan struct{}),
+	}
+	t.pending[id] = p
+	t.stats.Registered++
+
+	if timeout > 0 {
+		p.timer = t.opts.clk.NewTimer(timeout)
+		p.stop = make(chan struct{})
+		go p.expire()
+	}
+	return p, nil
+}
+
+// Lookup returns the entry pending for id. A miss is counted as unmatched.
+func (t *Table[T]) Lookup(id string) (*Pending[T], bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.pending[id]
+	if !ok {
+		t.stats.Unmatched++
+	}
+	return p, ok
+}
+
+// Peek returns the entry pending for id like Lookup, but does not count a
+// miss. It suits searching several tables for the one holding id.
+func (t *Table[T]) Peek(id string) (*Pending[T], bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.pending[id]
+	return p, ok
+}
+
+// CancelAll cancels every open entry with cause (ErrCanceled if nil), e.g. on shutdown
+func (t *Table[T]) CancelAll(cause error) {
+	t.mu.Lock()
+	pending := make([]*Pending[T], 0, len(t.pending))
+	for _, p := range t.pending {
+		pending = append(pending, p)
+	}
+	t.mu.Unlock()
+
+	for _, p := range pending {
+		p.Cancel(cause)
+	}
+}
+
+// Len returns the number of pending entries
+func (t *Table[T]) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.pending)
+}
+
+// Stats returns the table's counters
+func (t *Table[T]) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := t.stats
+	stats.Pending = len(t.pending)
+	return stats
+}
+
+// Pending is a request waiting for its reply
+type Pending[T any] struct {
+	table      *Table[T]
+	id         string
+	handle     T
+	registered clock.MonoTime
+	timer      clock.Timer   // nil without a deadline
+	stop       chan struct{} // Closed to stop the deadline goroutine
+
+	// Guarded by table.mu
+	state state
+	err   error
+
+	done chan struct{} // Closed once finished, canceled or expired
+}
+
+// state is where an entry is in its life
+type state int
+
+const (
+	open    state = iota // Waiting for a reply
+	claimed              // A replier is delivering the reply
+	closed               // Completed
+)
+
+// ID returns the correlation ID
+func (p *Pending[T]) ID() string {
+	return p.id
+}
+
+// Handle returns the handle given to Register
+func (p *Pending[T]) Handle() T {
+	return p.handle
+}
+
+// Claim reserves the entry for the caller, who must then call Finish. It
+// removes the entry from the table and stops its deadline. Claim reports
+// false if the entry was already claimed, canceled or expired; the reply is
+// then counted as unmatched and must be dropped.
+func (p *Pending[T]) Claim() bool {
+	t := p.table
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p.state != open {
+		t.stats.Unmatched++
+		return false
+	}
+	p.state = claimed
+	p.release()
+
+	latency := int64(t.opts.clk.Since(p.registered))
+	t.stats.Latency.Count++
+	t.stats.Latency.TotalNs += latency
+	t.stats.Latency.MaxNs = max(t.stats.Latency.MaxNs, latency)
+	return true
+}
+
+// Finish completes a claimed entry with the outcome of delivering the reply
+func (p *Pending[T]) Finish(err error) {
+	t := p.table
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p.state != claimed {
+		return
+	}
+	if err != nil {
+		t.stats.Failed++
+	} else {
+		t.stats.Completed++
+	}
+	p.close(err)
+}
+
+// Cancel completes an open entry with cause (ErrCanceled if nil) and reports
+// whether it did; it fails once the entry has been claimed or completed
+func (p *Pending[T]) Cancel(cause error) bool {
+	if cause == nil {
+		cause = ErrCanceled
+	}
+
+	t := p.table
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p.state != open {
+		return false
+	}
+	if errors.Is(cause, ErrTimeout) || errors.Is(cause, context.DeadlineExceeded) {
+		t.stats.TimedOut++
+	} else {
+		t.stats.Canceled++
+	}
+	p.release()
+	p.close(cause)
+	return true
+}
+
+// Done returns a channel closed once the entry is completed
+func (p *Pending[T]) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns nil while the entry is pending or after a successful Finish.
+// Otherwise it returns the error the entry completed with.
+func (p *Pending[T]) Err() error {
+	p.table.mu.Lock()
+	defer p.table.mu.Unlock()
+
+	return p.err
+}
+
+// Wait blocks until the entry is completed and returns its error. If ctx is
+// done first, the entry is canceled with the context's error. If it was
+// already claimed, Wait keeps waiting for the reply to finish.
+func (p *Pending[T]) Wait(ctx context.Context) error {
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		if !p.Cancel(ctx.Err()) {
+			<-p.done
+		}
+	}
+	return p.Err()
+}
+
+// release removes the entry from the table and stops its deadline; table.mu must be held
+func (p *Pending[T]) release() {
+	delete(p.table.pending, p.id)
+	if p.timer != nil {
+		p.timer.Stop()
+		close(p.stop)
+	}
+}
+
+// close completes the entry; table.mu must be held
+func (p *Pending[T]) close(err error) {
+	p.state = closed
+	p.err = err
+	close(p.done)
+}
+
+// expire cancels the entry with ErrTimeout when its deadline passes
+func (p *Pending[T]) expire() {
+	select {
+	case <-p.timer.C():
+		p.Cancel(ErrTimeout)
+	case <-p.stop:
+	}
+}
@@ -0,0 +1,124 @@
+package correlate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+)
+
+func TestTable_ClaimOnce(t *testing.T) {
+	table := NewTable[string]()
+	p, err := table.Register("req-1", "writer", 0)
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	if _, err := table.Register("req-1", "other", 0); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate, got %v", err)
+	}
+
+	found, ok := table.Lookup("req-1")
+	if !ok || found.Handle() != "writer" {
+		t.Fatalf("Expected to find the pending entry, got %v", ok)
+	}
+
+	// Concurrent repliers and a cancel race; exactly one wins
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if p.Claim() {
+				wins.Add(1)
+				p.Finish(nil)
+			}
+		}()
+	}
+	wg.Wait()
+	if wins.Load() != 1 {
+		t.Errorf("Expected exactly one claim to win, got %d", wins.Load())
+	}
+	if p.Cancel(nil) {
+		t.Error("Expected Cancel to fail after completion")
+	}
+
+	if err := p.Wait(context.Background()); err != nil {
+		t.Errorf("Expected a successful completion, got %v", err)
+	}
+	if _, ok := table.Lookup("req-1"); ok {
+		t.Error("Expected the entry to leave the table once claimed")
+	}
+
+	stats := table.Stats()
+	if stats.Registered != 1 || stats.Completed != 1 || stats.Pending != 0 || stats.Latency.Count != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	// 7 losing claims and the lookup after completion
+	if stats.Unmatched != 8 {
+		t.Errorf("Expected 8 unmatched replies, got %d", stats.Unmatched)
+	}
+}
+
+func TestTable_Deadline(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	table := NewTable[int](WithClock(clk))
+
+	expiring, _ := table.Register("slow", 1, time.Second)
+	answered, _ := table.Register("fast", 2, time.Second)
+	clk.BlockUntil(2)
+
+	clk.Advance(500 * time.Millisecond)
+	if !answered.Claim() {
+		t.Fatal("Expected to claim before the deadline")
+	}
+	answered.Finish(errors.New("write failed"))
+
+	clk.Advance(500 * time.Millisecond)
+	if err := expiring.Wait(context.Background()); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got %v", err)
+	}
+	if expiring.Claim() {
+		t.Error("Expected a late reply not to claim an expired entry")
+	}
+	if err := answered.Err(); err == nil || err.Error() != "write failed" {
+		t.Errorf("Expected the delivery error, got %v", err)
+	}
+
+	stats := table.Stats()
+	if stats.TimedOut != 1 || stats.Failed != 1 || stats.Unmatched != 1 || stats.Pending != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.Latency.MaxNs != int64(500*time.Millisecond) {
+		t.Errorf("Expected a 500ms claim latency, got %v", time.Duration(stats.Latency.MaxNs))
+	}
+}
+
+func TestPending_WaitCanceled(t *testing.T) {
+	table := NewTable[struct{}]()
+	p, _ := table.Register("req-1", struct{}{}, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if table.Len() != 0 || table.Stats().Canceled != 1 {
+		t.Errorf("Expected the canceled entry to be removed and counted, got %+v", table.Stats())
+	}
+
+	// Waiting on a claimed entry outlasts the context
+	p, _ = table.Register("req-2", struct{}{}, 0)
+	p.Claim()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		p.Finish(nil)
+	}()
+	if err := p.Wait(ctx); err != nil {
+		t.Errorf("Expected the claimed reply to finish, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package correlate
+
+import (
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// Option configures a Table or a Requester
+type Option func(*options)
+
+// options holds the settings shared by tables and requesters
+type options struct {
+	clk     clock.Clock
+	timeout time.Duration
+	replies event.Filter
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		clk:     clock.System(),
+		timeout: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithClock sets the clock deadlines and latencies are measured with
+func WithClock(clk clock.Clock) Option {
+	return func(o *options) {
+		if clk != nil {
+			o.clk = clk
+		}
+	}
+}
+
+// WithTimeout sets how long a Requester waits for a reply when the request
+// context has no earlier deadline (defaults to 30 seconds; 0 waits on the
+// context alone)
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = max(d, 0)
+	}
+}
+
+// WithReplies limits the events a Requester inspects for replies, e.g. to
+// their types. By default it inspects every event on the bus.
+func WithReplies(filter event.Filter) Option {
+	return func(o *options) {
+		o.replies = filter
+	}
+}
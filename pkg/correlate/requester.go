@@ -0,0 +1,163 @@
+package correlate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+const (
+	// CorrelationKey is the request event metadata key holding the correlation ID
+	CorrelationKey = "correlation_id"
+
+	// ReplyKey is the reply event metadata key naming the request's correlation ID
+	ReplyKey = "in_reply_to"
+)
+
+// ReplyTo marks reply as the answer to request, copying the request's
+// correlation ID into the reply's "in_reply_to" metadata
+func ReplyTo(request, reply *event.Event) *event.Event {
+	id := request.Metadata[CorrelationKey]
+	if id == "" {
+		id = request.ID
+	}
+	return reply.WithMetadata(ReplyKey, id)
+}
+
+// ErrNotStarted is returned by Request before Start or after Stop
+var ErrNotStarted = errors.New("correlate: requester not started")
+
+// Requester publishes request events and returns the reply correlated with
+// each one. Services answering its requests mark their replies with ReplyTo.
+type Requester struct {
+	bus   event.Bus
+	opts  options
+	table *Table[*reply]
+
+	mu      sync.Mutex
+	sub     event.Subscription
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+}
+
+// reply is the slot a matching reply event is delivered into
+type reply struct {
+	evt *event.Event
+}
+
+// NewRequester creates a requester that publishes to and reads replies from bus
+func NewRequester(bus event.Bus, opts ...Option) *Requester {
+	o := newOptions(opts)
+	return &Requester{
+		bus:   bus,
+		opts:  o,
+		table: NewTable[*reply](WithClock(o.clk)),
+	}
+}
+
+// Start subscribes to replies
+func (r *Requester) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return fmt.Errorf("requester already running")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	sub, err := r.bus.Subscribe(ctx, r.opts.replies)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	r.sub = sub
+	r.cancel = cancel
+	r.running = true
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for evt := range sub.Events() {
+			r.deliver(evt)
+		}
+	}()
+	return nil
+}
+
+// Stop closes the subscription; requests still waiting fail with ErrCanceled
+func (r *Requester) Stop() error {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return nil
+	}
+	r.running = false
+	sub, cancel := r.sub, r.cancel
+	r.mu.Unlock()
+
+	err := sub.Close()
+	cancel()
+	r.wg.Wait()
+
+	r.table.CancelAll(ErrCanceled)
+	return err
+}
+
+// Request publishes evt and waits for the event replying to it. The
+// correlation ID is evt's "correlation_id" metadata, which is set to the
+// event ID if missing. Request gives up when ctx is done or the timeout set
+// with WithTimeout passes, whichever is first. It fails with ErrNotStarted
+// unless the requester is running, since no reply could be received.
+func (r *Requester) Request(ctx context.Context, evt *event.Event) (*event.Event, error) {
+	id := evt.Metadata[CorrelationKey]
+	if id == "" {
+		id = evt.ID
+		evt.WithMetadata(CorrelationKey, id)
+	}
+
+	// Registering under the lock keeps Stop from missing the entry
+	slot := &reply{}
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return nil, ErrNotStarted
+	}
+	p, err := r.table.Register(id, slot, r.opts.timeout)
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.bus.Publish(ctx, evt); err != nil {
+		p.Cancel(err)
+		return nil, fmt.Errorf("failed to publish request: %w", err)
+	}
+	if err := p.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return slot.evt, nil
+}
+
+// Stats returns the requester's correlation counters
+func (r *Requester) Stats() Stats {
+	return r.table.Stats()
+}
+
+// deliver completes the request an event replies to, if any
+func (r *Requester) deliver(evt *event.Event) {
+	id, ok := evt.Metadata[ReplyKey]
+	if !ok {
+		return
+	}
+	p, ok := r.table.Lookup(id)
+	if !ok || !p.Claim() {
+		return
+	}
+	p.Handle().evt = evt
+	p.Finish(nil)
+}
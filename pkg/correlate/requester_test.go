@@ -0,0 +1,135 @@
+package correlate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+type quote struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price,omitempty"`
+}
+
+func TestRequester_Request(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+	bus := eng.ExternalBus()
+
+	// A service answering "quote.request" events
+	requests, err := bus.Subscribe(context.Background(), event.Filter{Types: []string{"quote.request"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer requests.Close()
+	go func() {
+		for evt := range requests.Events() {
+			var q quote
+			if err := codec.Decode(evt, &q); err != nil {
+				continue
+			}
+			q.Price = 42.5
+			reply, err := codec.NewEvent("quote.reply", "quotes", q, codec.JSON{})
+			if err != nil {
+				continue
+			}
+			bus.Publish(context.Background(), ReplyTo(evt, reply))
+		}
+	}()
+
+	requester := NewRequester(bus, WithReplies(event.Filter{Types: []string{"quote.reply"}}))
+	if err := requester.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start requester: %v", err)
+	}
+	defer requester.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	for _, symbol := range []string{"ACME", "INIT"} {
+		req, _ := codec.NewEvent("quote.request", "test", quote{Symbol: symbol}, codec.JSON{})
+		reply, err := requester.Request(ctx, req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+
+		var q quote
+		if err := codec.Decode(reply, &q); err != nil {
+			t.Fatalf("Failed to decode reply: %v", err)
+		}
+		if q.Symbol != symbol || q.Price != 42.5 {
+			t.Errorf("Expected the quote for %s, got %+v", symbol, q)
+		}
+		if reply.Metadata[ReplyKey] != req.Metadata[CorrelationKey] || req.Metadata[CorrelationKey] != req.ID {
+			t.Errorf("Expected the reply to carry the request's correlation ID, got %v", reply.Metadata)
+		}
+	}
+
+	if stats := requester.Stats(); stats.Completed != 2 || stats.Pending != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestRequester_Timeout(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	clk := clock.NewFake(time.Now())
+	requester := NewRequester(eng.ExternalBus(), WithClock(clk), WithTimeout(5*time.Second),
+		WithReplies(event.Filter{Types: []string{"quote.reply"}}))
+	if err := requester.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start requester: %v", err)
+	}
+	defer requester.Stop()
+
+	// Nobody answers; the deadline passes on the fake clock
+	done := make(chan error, 1)
+	go func() {
+		req, _ := codec.NewEvent("quote.request", "test", quote{Symbol: "NONE"}, codec.JSON{})
+		_, err := requester.Request(context.Background(), req)
+		done <- err
+	}()
+	clk.BlockUntil(1)
+	clk.Advance(5 * time.Second)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("Expected ErrTimeout, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Request did not time out")
+	}
+	if stats := requester.Stats(); stats.TimedOut != 1 {
+		t.Errorf("Expected one timed out request, got %+v", stats)
+	}
+}
+
+func TestRequester_NotStarted(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	// Without a subscription no reply could arrive, so Request fails at once
+	requester := NewRequester(eng.ExternalBus(), WithTimeout(time.Hour))
+	req, _ := codec.NewEvent("quote.request", "test", quote{Symbol: "ACME"}, codec.JSON{})
+	if _, err := requester.Request(context.Background(), req); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("Expected ErrNotStarted before Start, got %v", err)
+	}
+
+	if err := requester.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start requester: %v", err)
+	}
+	requester.Stop()
+	if _, err := requester.Request(context.Background(), req); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("Expected ErrNotStarted after Stop, got %v", err)
+	}
+	if stats := requester.Stats(); stats.Registered != 0 {
+		t.Errorf("Expected no registered requests, got %+v", stats)
+	}
+}
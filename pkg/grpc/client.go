@@ -0,0 +1,359 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails" // Standard status details decode to JSON
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// errTooManyTargets fails calls to a new target while the connection pool is full
+var errTooManyTargets = errors.New("grpc target pool full")
+
+// ClientEmitter makes unary gRPC calls described by "net.grpc.call" events,
+// without generated stubs. Requests are raw protobuf or, with descriptors
+// set by WithDescriptors, protobuf JSON.
+//
+// Each result is published to the bus: "net.grpc.reply" for a reply and
+// "net.grpc.error" with the status code and details for a failed call,
+// both with the call ID in the "call_id" metadata. Connections are opened
+// per target on first use. A target with no calls for the idle timeout is
+// closed and dropped from the pool, and the pool holds at most
+// WithMaxTargets targets.
+type ClientEmitter struct {
+	id    string
+	bus   event.Bus
+	opts  options
+	stats metrics.Counters
+
+	mu       sync.Mutex
+	conns    map[string]*clientConn // target → connection
+	evicting bool                   // Whether the idle eviction loop is running
+	closed   bool
+	stop     chan struct{} // Closed by Close to end idle eviction
+	wg       sync.WaitGroup
+}
+
+// clientConn is a pooled connection to one target. Its fields are guarded
+// by the emitter's mu.
+type clientConn struct {
+	*grpc.ClientConn
+	active   int   // Calls in flight
+	lastUsed int64 // Clock reading (MonoTime) of the last call
+}
+
+// NewClientEmitter creates a gRPC emitter that publishes replies and errors to bus
+func NewClientEmitter(bus event.Bus, opts ...Option) *ClientEmitter {
+	return &ClientEmitter{
+		id:    "grpc-client-emitter",
+		bus:   bus,
+		opts:  newOptions(opts),
+		conns: make(map[string]*clientConn),
+		stop:  make(chan struct{}),
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *ClientEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *ClientEmitter) Type() string {
+	return "grpc-client"
+}
+
+// Emit makes the call and publishes its outcome. It returns an error when
+// the call fails, after publishing the "net.grpc.error" event.
+func (e *ClientEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var payload GRPCCallPayload
+	if err := evt.DecodePayload(&payload, c); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if payload.Target == "" || payload.Method == "" {
+		return fmt.Errorf("call %s needs a target and a method", payload.CallID)
+	}
+
+	start := e.opts.clk.Now()
+	reply, header, trailer, err := e.call(ctx, payload)
+	duration := e.opts.clk.Since(start)
+
+	if err != nil {
+		st := status.Convert(err)
+		e.publish("net.grpc.error", payload, GRPCErrorPayload{
+			CallID:     payload.CallID,
+			Target:     payload.Target,
+			Method:     payload.Method,
+			Code:       uint32(st.Code()),
+			CodeName:   st.Code().String(),
+			Message:    st.Message(),
+			Details:    e.details(st),
+			Headers:    metadataMap(header),
+			Trailers:   metadataMap(trailer),
+			Timestamp:  e.opts.clk.Wall(),
+			DurationNs: duration.Nanoseconds(),
+		})
+		return fmt.Errorf("call %s to %s failed: %w", payload.CallID, payload.Method, err)
+	}
+
+	replyPayload := GRPCReplyPayload{
+		CallID:     payload.CallID,
+		Target:     payload.Target,
+		Method:     payload.Method,
+		Message:    reply,
+		Headers:    metadataMap(header),
+		Trailers:   metadataMap(trailer),
+		Timestamp:  e.opts.clk.Wall(),
+		DurationNs: duration.Nanoseconds(),
+	}
+	if desc := e.method(payload.Method); desc != nil {
+		// A reply the schema cannot decode is still delivered raw
+		replyPayload.JSON, _ = e.opts.descriptors.ToJSON(desc.Output(), reply)
+	}
+	e.publish("net.grpc.reply", payload, replyPayload)
+	return nil
+}
+
+// call encodes the request and makes the unary call
+func (e *ClientEmitter) call(ctx context.Context, payload GRPCCallPayload) (rawMessage, metadata.MD, metadata.MD, error) {
+	desc := e.method(payload.Method)
+	if desc != nil && (desc.IsStreamingClient() || desc.IsStreamingServer()) {
+		return nil, nil, nil, status.Errorf(codes.Unimplemented, "streaming method %s is not supported", payload.Method)
+	}
+
+	req := rawMessage(payload.Message)
+	if len(req) == 0 && len(payload.JSON) > 0 {
+		if desc == nil {
+			return nil, nil, nil, status.Errorf(codes.InvalidArgument, "no descriptor to encode the JSON request for %s", payload.Method)
+		}
+		msg, err := e.opts.descriptors.FromJSON(desc.Input(), payload.JSON)
+		if err != nil {
+			return nil, nil, nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		req = msg
+	}
+
+	conn, err := e.conn(payload.Target)
+	if errors.Is(err, errTooManyTargets) {
+		return nil, nil, nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		return nil, nil, nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer e.release(conn)
+
+	timeout := e.opts.timeout
+	if payload.TimeoutMs > 0 {
+		timeout = time.Duration(payload.TimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if len(payload.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadataFrom(payload.Metadata))
+	}
+
+	var reply rawMessage
+	var header, trailer metadata.MD
+	err = conn.Invoke(ctx, payload.Method, &req, &reply, grpc.Header(&header), grpc.Trailer(&trailer))
+	return reply, header, trailer, err
+}
+
+// method returns the method's descriptor, or nil without one
+func (e *ClientEmitter) method(fullMethod string) protoreflect.MethodDescriptor {
+	if e.opts.descriptors == nil {
+		return nil
+	}
+	desc, err := e.opts.descriptors.Method(fullMethod)
+	if err != nil {
+		return nil
+	}
+	return desc
+}
+
+// conn returns the connection for target, creating it on first use, and
+// holds it open until release
+func (e *ClientEmitter) conn(target string) (*clientConn, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return nil, fmt.Errorf("emitter closed")
+	}
+	if conn, ok := e.conns[target]; ok {
+		conn.active++
+		return conn, nil
+	}
+	if e.opts.maxTargets > 0 && len(e.conns) >= e.opts.maxTargets {
+		return nil, fmt.Errorf("%w: %d targets", errTooManyTargets, len(e.conns))
+	}
+
+	creds := insecure.NewCredentials()
+	if e.opts.tlsConfig != nil {
+		creds = credentials.NewTLS(e.opts.tlsConfig)
+	}
+	var dialer net.Dialer
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			nc, err := dialer.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			return metrics.NewConn(nc, &e.stats), nil
+		}),
+		grpc.WithDefaultCallOptions(
+			grpc.ForceCodec(rawCodec{}),
+			grpc.MaxCallRecvMsgSize(e.opts.maxMessageSize),
+		),
+	}, e.opts.dialOptions...)
+
+	cc, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for %s: %w", target, err)
+	}
+	conn := &clientConn{ClientConn: cc, active: 1}
+	e.conns[target] = conn
+
+	if e.opts.idleTimeout > 0 && !e.evicting {
+		e.evicting = true
+		e.wg.Add(1)
+		go e.evictIdle()
+	}
+	return conn, nil
+}
+
+// release ends a call on conn, starting its idle time
+func (e *ClientEmitter) release(conn *clientConn) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	conn.active--
+	conn.lastUsed = int64(e.opts.clk.Now())
+}
+
+// evictIdle closes and forgets connections that have had no calls for the
+// idle timeout, until the emitter is closed
+func (e *ClientEmitter) evictIdle() {
+	defer e.wg.Done()
+
+	ticker := e.opts.clk.NewTicker(max(e.opts.idleTimeout/2, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C():
+		}
+
+		cutoff := int64(e.opts.clk.Now()) - int64(e.opts.idleTimeout)
+		e.mu.Lock()
+		for target, conn := range e.conns {
+			if conn.active == 0 && conn.lastUsed < cutoff {
+				delete(e.conns, target)
+				conn.Close()
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+// Metrics returns the emitter's traffic counters. Bytes are counted on the
+// socket, including HTTP/2 framing, unless a dial option replaces the dialer;
+// errors are calls that failed.
+func (e *ClientEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "grpc", e.opts.clk.Wall())
+}
+
+// details converts status details, decoding types known to the descriptors
+// or linked into the binary to JSON
+func (e *ClientEmitter) details(st *status.Status) []GRPCStatusDetail {
+	anys := st.Proto().GetDetails()
+	if len(anys) == 0 {
+		return nil
+	}
+
+	details := make([]GRPCStatusDetail, len(anys))
+	for i, a := range anys {
+		details[i] = GRPCStatusDetail{Type: a.GetTypeUrl(), Value: a.GetValue()}
+
+		mt, err := protoregistry.GlobalTypes.FindMessageByURL(a.GetTypeUrl())
+		if err != nil && e.opts.descriptors != nil {
+			mt, err = e.opts.descriptors.types.FindMessageByURL(a.GetTypeUrl())
+		}
+		if err != nil {
+			continue
+		}
+		msg := mt.New().Interface()
+		if proto.Unmarshal(a.GetValue(), msg) != nil {
+			continue
+		}
+		if js, err := protojson.Marshal(msg); err == nil {
+			details[i].JSON = js
+		}
+	}
+	return details
+}
+
+// publish encodes payload with the emitter codec and publishes it
+func (e *ClientEmitter) publish(eventType string, call GRPCCallPayload, payload any) {
+	if e.bus == nil {
+		return
+	}
+	evt, err := codec.NewEvent(eventType, e.id, payload, e.opts.codec)
+	if err != nil {
+		return
+	}
+	evt.WithMetadata("emitter_id", e.id).
+		WithMetadata("method", call.Method).
+		WithMetadata("target", call.Target)
+	if call.CallID != "" {
+		evt.WithMetadata("call_id", call.CallID)
+	}
+
+	e.bus.Publish(context.Background(), evt)
+}
+
+// Close closes every connection
+func (e *ClientEmitter) Close() error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.stop)
+	}
+	for target, conn := range e.conns {
+		conn.Close()
+		delete(e.conns, target)
+	}
+	e.mu.Unlock()
+
+	e.wg.Wait()
+	return nil
+}
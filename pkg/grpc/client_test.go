@@ -0,0 +1,261 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startTarget runs a plain gRPC server that answers every method with handler
+func startTarget(t *testing.T, handler func(ctx context.Context, method string, req []byte) ([]byte, error)) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			var req rawMessage
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			reply, err := handler(stream.Context(), method, req)
+			if err != nil {
+				return err
+			}
+			msg := rawMessage(reply)
+			return stream.SendMsg(&msg)
+		}),
+	)
+	go server.Serve(ln)
+	t.Cleanup(server.Stop)
+	return ln.Addr().String()
+}
+
+// startClient creates a ClientEmitter and subscribes to its results
+func startClient(t *testing.T, opts ...Option) (*ClientEmitter, event.Subscription) {
+	t.Helper()
+
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.grpc.reply", "net.grpc.error"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+
+	emitter := NewClientEmitter(eng.ExternalBus(), opts...)
+	t.Cleanup(func() { emitter.Close() })
+	return emitter, sub
+}
+
+func callEvent(t *testing.T, payload GRPCCallPayload) *event.Event {
+	t.Helper()
+
+	evt, err := codec.NewEvent("net.grpc.call", "test", payload, codec.JSON{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	return evt
+}
+
+// nextResult waits for the next reply or error event
+func nextResult(t *testing.T, sub event.Subscription, payload any) *event.Event {
+	t.Helper()
+
+	select {
+	case evt := <-sub.Events():
+		if err := codec.Decode(evt, payload); err != nil {
+			t.Fatalf("Failed to decode %s: %v", evt.Type, err)
+		}
+		return evt
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for result")
+		return nil
+	}
+}
+
+func TestClientEmitter_JSONCall(t *testing.T) {
+	d := testDescriptors(t)
+	target := startTarget(t, func(ctx context.Context, method string, req []byte) ([]byte, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if got := md.Get("x-tenant"); len(got) != 1 || got[0] != "acme" {
+			return nil, status.Error(codes.PermissionDenied, "missing tenant")
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, status.Error(codes.InvalidArgument, "missing deadline")
+		}
+		return ping(t, d, "pong", 7), nil
+	})
+	emitter, sub := startClient(t, WithDescriptors(d))
+
+	err := emitter.Emit(context.Background(), callEvent(t, GRPCCallPayload{
+		CallID:    "call-1",
+		Target:    target,
+		Method:    "/test.Echo/Say",
+		Metadata:  map[string]string{"X-Tenant": "acme"},
+		JSON:      json.RawMessage(`{"text": "ping"}`),
+		TimeoutMs: 2000,
+	}))
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+
+	var reply GRPCReplyPayload
+	evt := nextResult(t, sub, &reply)
+	if evt.Type != "net.grpc.reply" || evt.Metadata["call_id"] != "call-1" {
+		t.Fatalf("Expected reply for call-1, got %s %v", evt.Type, evt.Metadata)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(reply.JSON, &out); err != nil || out["text"] != "pong" || out["count"] != float64(7) {
+		t.Errorf("Unexpected reply JSON %s", reply.JSON)
+	}
+	if len(reply.Message) == 0 || reply.DurationNs <= 0 {
+		t.Errorf("Expected raw message and duration, got %+v", reply)
+	}
+}
+
+func TestClientEmitter_ErrorDetails(t *testing.T) {
+	target := startTarget(t, func(ctx context.Context, method string, req []byte) ([]byte, error) {
+		st, _ := status.New(codes.FailedPrecondition, "quota exhausted").WithDetails(&errdetails.ErrorInfo{
+			Reason: "QUOTA",
+			Domain: "example.com",
+		})
+		return nil, st.Err()
+	})
+	emitter, sub := startClient(t)
+
+	err := emitter.Emit(context.Background(), callEvent(t, GRPCCallPayload{
+		CallID:  "call-2",
+		Target:  target,
+		Method:  "/billing.Billing/Charge",
+		Message: []byte("\x08\x01"),
+	}))
+	if err == nil {
+		t.Error("Expected Emit to report the failed call")
+	}
+
+	var failure GRPCErrorPayload
+	evt := nextResult(t, sub, &failure)
+	if evt.Type != "net.grpc.error" {
+		t.Fatalf("Expected error event, got %s", evt.Type)
+	}
+	if failure.Code != uint32(codes.FailedPrecondition) || failure.CodeName != "FailedPrecondition" || failure.Message != "quota exhausted" {
+		t.Errorf("Unexpected status %+v", failure)
+	}
+	if len(failure.Details) != 1 || !strings.HasSuffix(failure.Details[0].Type, "google.rpc.ErrorInfo") {
+		t.Fatalf("Expected ErrorInfo detail, got %+v", failure.Details)
+	}
+	if !strings.Contains(string(failure.Details[0].JSON), `"QUOTA"`) {
+		t.Errorf("Expected detail JSON, got %s", failure.Details[0].JSON)
+	}
+}
+
+func TestClientEmitter_Deadline(t *testing.T) {
+	target := startTarget(t, func(ctx context.Context, method string, req []byte) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	emitter, sub := startClient(t)
+
+	emitter.Emit(context.Background(), callEvent(t, GRPCCallPayload{
+		Target:    target,
+		Method:    "/slow.Slow/Wait",
+		TimeoutMs: 50,
+	}))
+
+	var failure GRPCErrorPayload
+	nextResult(t, sub, &failure)
+	if codes.Code(failure.Code) != codes.DeadlineExceeded {
+		t.Errorf("Expected DEADLINE_EXCEEDED, got %+v", failure)
+	}
+}
+
+func TestClientEmitter_IdleEvictionAndMaxTargets(t *testing.T) {
+	answer := func(ctx context.Context, method string, req []byte) ([]byte, error) {
+		return req, nil
+	}
+	targets := []string{startTarget(t, answer), startTarget(t, answer)}
+
+	clk := clock.NewFake(time.Now())
+	emitter, sub := startClient(t,
+		WithIdleTimeout(100*time.Millisecond),
+		WithMaxTargets(1),
+		WithClock(clk),
+	)
+	call := func(target string) error {
+		err := emitter.Emit(context.Background(), callEvent(t, GRPCCallPayload{Target: target, Method: "/echo.Echo/Say"}))
+		var result map[string]any
+		nextResult(t, sub, &result)
+		return err
+	}
+
+	if err := call(targets[0]); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if err := call(targets[1]); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected RESOURCE_EXHAUSTED with the pool full, got %v", err)
+	}
+
+	// The idle connection is closed on the emitter's clock, freeing its slot
+	clk.BlockUntil(1)
+	clk.Advance(150 * time.Millisecond)
+	pooled := 1
+	for deadline := time.Now().Add(2 * time.Second); pooled != 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		emitter.mu.Lock()
+		pooled = len(emitter.conns)
+		emitter.mu.Unlock()
+	}
+	if pooled != 0 {
+		t.Errorf("Expected the idle connection to be evicted, %d pooled", pooled)
+	}
+	if err := call(targets[1]); err != nil {
+		t.Errorf("Expected the freed slot to take a new target, got %v", err)
+	}
+}
+
+func TestClientEmitter_InvalidCalls(t *testing.T) {
+	d := testDescriptors(t)
+	emitter, sub := startClient(t, WithDescriptors(d))
+
+	// Rejected before any connection is made
+	calls := []GRPCCallPayload{
+		{Target: "127.0.0.1:1", Method: "/other.Svc/Do", JSON: json.RawMessage(`{}`)},
+		{Target: "127.0.0.1:1", Method: "/test.Echo/Say", JSON: json.RawMessage(`{"nope": 1}`)},
+		{Target: "127.0.0.1:1", Method: "/test.Echo/Watch"},
+	}
+	for _, call := range calls {
+		if err := emitter.Emit(context.Background(), callEvent(t, call)); err == nil {
+			t.Errorf("Expected error for %+v", call)
+		}
+		var failure GRPCErrorPayload
+		nextResult(t, sub, &failure)
+		if c := codes.Code(failure.Code); c != codes.InvalidArgument && c != codes.Unimplemented {
+			t.Errorf("Unexpected code %v for %s", c, call.Method)
+		}
+	}
+
+	if err := emitter.Emit(context.Background(), callEvent(t, GRPCCallPayload{Method: "/test.Echo/Say"})); err == nil {
+		t.Error("Expected error without a target")
+	}
+}
@@ -0,0 +1,144 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ErrUnknownMethod is returned when a method is not in the descriptor set
+var ErrUnknownMethod = errors.New("grpc method not in descriptor set")
+
+// Descriptors holds protobuf schemas loaded from FileDescriptorSets, as
+// produced by "protoc --include_imports --descriptor_set_out". They let
+// messages be translated between the wire format and protobuf JSON without
+// generated Go types.
+type Descriptors struct {
+	files  *protoregistry.Files
+	types  *dynamicpb.Types
+	routes []httpRoute // From google.api.http annotations
+}
+
+// LoadDescriptorSet reads a serialized FileDescriptorSet from path
+func LoadDescriptorSet(path string) (*Descriptors, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+	return ParseDescriptorSet(data)
+}
+
+// ParseDescriptorSet parses a serialized FileDescriptorSet
+func ParseDescriptorSet(data []byte) (*Descriptors, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+	return NewDescriptors(&set)
+}
+
+// NewDescriptors builds Descriptors from a FileDescriptorSet. Imports
+// missing from the set are resolved from the well-known types linked into
+// the binary, so sets built without --include_imports work as long as they
+// only import those. Methods annotated with google.api.http are also served
+// as REST calls by ServerAdapter.ServeGateway.
+func NewDescriptors(set *descriptorpb.FileDescriptorSet) (*Descriptors, error) {
+	files := new(protoregistry.Files)
+	pending := set.GetFile()
+
+	// Register files once their imports are available, in any order
+	for len(pending) > 0 {
+		var retry []*descriptorpb.FileDescriptorProto
+		var lastErr error
+		for _, fd := range pending {
+			file, err := protodesc.NewFile(fd, resolver{files})
+			if err != nil {
+				retry = append(retry, fd)
+				lastErr = err
+				continue
+			}
+			if err := files.RegisterFile(file); err != nil {
+				return nil, fmt.Errorf("failed to register %s: %w", fd.GetName(), err)
+			}
+		}
+		if len(retry) == len(pending) {
+			return nil, fmt.Errorf("failed to build descriptors: %w", lastErr)
+		}
+		pending = retry
+	}
+
+	routes, err := httpRoutes(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Descriptors{files: files, types: dynamicpb.NewTypes(files), routes: routes}, nil
+}
+
+// Method looks up a method by its full name (/package.Service/Method)
+func (d *Descriptors) Method(fullMethod string) (protoreflect.MethodDescriptor, error) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid method name %q", fullMethod)
+	}
+
+	desc, err := d.files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, fullMethod)
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a service", ErrUnknownMethod, service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, fullMethod)
+	}
+	return md, nil
+}
+
+// ToJSON converts a wire-format message of type desc to protobuf JSON
+func (d *Descriptors) ToJSON(desc protoreflect.MessageDescriptor, wire []byte) ([]byte, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if err := (proto.UnmarshalOptions{Resolver: d.types}).Unmarshal(wire, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", desc.FullName(), err)
+	}
+	return protojson.MarshalOptions{Resolver: d.types}.Marshal(msg)
+}
+
+// FromJSON converts a protobuf JSON message of type desc to the wire format
+func (d *Descriptors) FromJSON(desc protoreflect.MessageDescriptor, data []byte) ([]byte, error) {
+	msg := dynamicpb.NewMessage(desc)
+	if err := (protojson.UnmarshalOptions{Resolver: d.types}).Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode %s JSON: %w", desc.FullName(), err)
+	}
+	return proto.Marshal(msg)
+}
+
+// resolver finds imports among the files registered so far, then among the
+// descriptors linked into the binary
+type resolver struct {
+	files *protoregistry.Files
+}
+
+func (r resolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if fd, err := r.files.FindFileByPath(path); err == nil {
+		return fd, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r resolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if d, err := r.files.FindDescriptorByName(name); err == nil {
+		return d, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
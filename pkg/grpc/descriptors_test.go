@@ -0,0 +1,139 @@
+package grpc
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// testDescriptorSet describes:
+//
+//	package test;
+//	message Ping { string text = 1; int32 count = 2; }
+//	service Echo {
+//	  rpc Say(Ping) returns (Ping);
+//	  rpc Watch(Ping) returns (stream Ping);
+//	  rpc Upload(stream Ping) returns (Ping);
+//	}
+func testDescriptorSet() *descriptorpb.FileDescriptorSet {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+	method := func(name string, clientStreaming, serverStreaming bool) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name:            proto.String(name),
+			InputType:       proto.String(".test.Ping"),
+			OutputType:      proto.String(".test.Ping"),
+			ClientStreaming: proto.Bool(clientStreaming),
+			ServerStreaming: proto.Bool(serverStreaming),
+		}
+	}
+
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("test.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Ping"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("text", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("count", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Echo"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Say", false, false),
+				method("Watch", false, true),
+				method("Upload", true, false),
+			},
+		}},
+	}}}
+}
+
+// testDescriptors parses testDescriptorSet
+func testDescriptors(t *testing.T) *Descriptors {
+	t.Helper()
+
+	data, err := proto.Marshal(testDescriptorSet())
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor set: %v", err)
+	}
+	d, err := ParseDescriptorSet(data)
+	if err != nil {
+		t.Fatalf("Failed to parse descriptor set: %v", err)
+	}
+	return d
+}
+
+// ping encodes a test.Ping message
+func ping(t *testing.T, d *Descriptors, text string, count int32) []byte {
+	t.Helper()
+
+	md, err := d.Method("/test.Echo/Say")
+	if err != nil {
+		t.Fatalf("Failed to find method: %v", err)
+	}
+	msg := dynamicpb.NewMessage(md.Input())
+	msg.Set(md.Input().Fields().ByName("text"), protoreflect.ValueOfString(text))
+	msg.Set(md.Input().Fields().ByName("count"), protoreflect.ValueOfInt32(count))
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Failed to marshal ping: %v", err)
+	}
+	return b
+}
+
+func TestDescriptors_Method(t *testing.T) {
+	d := testDescriptors(t)
+
+	md, err := d.Method("/test.Echo/Watch")
+	if err != nil {
+		t.Fatalf("Failed to find method: %v", err)
+	}
+	if !md.IsStreamingServer() || md.Input().FullName() != "test.Ping" {
+		t.Errorf("Unexpected method descriptor %v", md.FullName())
+	}
+
+	for _, name := range []string{"/test.Echo/Missing", "/test.Other/Say", "/test.Ping/Say"} {
+		if _, err := d.Method(name); !errors.Is(err, ErrUnknownMethod) {
+			t.Errorf("Method(%q): expected ErrUnknownMethod, got %v", name, err)
+		}
+	}
+	if _, err := d.Method("no-slash"); err == nil {
+		t.Error("Expected error for malformed method name")
+	}
+}
+
+func TestDescriptors_JSONRoundTrip(t *testing.T) {
+	d := testDescriptors(t)
+	md, _ := d.Method("/test.Echo/Say")
+
+	js, err := d.ToJSON(md.Input(), ping(t, d, "hello", 3))
+	if err != nil {
+		t.Fatalf("Failed to convert to JSON: %v", err)
+	}
+
+	wire, err := d.FromJSON(md.Input(), js)
+	if err != nil {
+		t.Fatalf("Failed to convert from JSON: %v", err)
+	}
+	back, _ := d.ToJSON(md.Input(), wire)
+	if string(back) != string(js) {
+		t.Errorf("Round trip changed message: %s -> %s", js, back)
+	}
+
+	if _, err := d.FromJSON(md.Input(), []byte(`{"unknown": 1}`)); err == nil {
+		t.Error("Expected error for unknown JSON field")
+	}
+}
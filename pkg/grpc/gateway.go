@@ -0,0 +1,299 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Match reports whether r is a call the adapter serves over HTTP: a
+// gRPC-Web request, or a REST request matching an http annotation in the
+// descriptors set by WithDescriptors.
+func (a *ServerAdapter) Match(r *http.Request) bool {
+	if isGRPCWeb(r) {
+		return true
+	}
+	_, _, ok := a.opts.descriptors.route(r.Method, r.URL.EscapedPath())
+	return ok
+}
+
+// ServeGateway serves a call handed over by another server (such as the HTTP
+// ServerAdapter's WithGateway) as a "net.grpc.request" event, just like a
+// native call, and translates the responses back:
+//
+//   - gRPC-Web (application/grpc-web and application/grpc-web-text) calls
+//     carry the raw request message; the status is sent in a trailer frame.
+//   - REST calls are transcoded with google.api.http annotations: path
+//     variables, query parameters and the JSON body fill in the request
+//     message, and responses are returned as JSON. Server-streaming
+//     responses are written as newline-delimited JSON.
+//
+// metadata's "request_id" becomes the call's request ID; its "principal",
+// "http_adapter_id" and "http_connection_id" are added to the event
+// metadata. An adapter created with an empty address serves gateway calls
+// only.
+func (a *ServerAdapter) ServeGateway(w http.ResponseWriter, r *http.Request, metadata map[string]string) {
+	a.mu.Lock()
+	running := a.running
+	a.mu.Unlock()
+	if !running {
+		http.Error(w, "gRPC adapter not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	payload := GRPCRequestPayload{
+		RequestID:  metadata["request_id"],
+		Metadata:   headerMetadata(r.Header),
+		RemoteAddr: r.RemoteAddr,
+		LocalAddr:  a.addr,
+		TLS:        r.TLS != nil,
+	}
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		payload.LocalAddr = addr.String()
+	}
+	extra := make(map[string]string)
+	for _, key := range []string{"principal", "http_adapter_id", "http_connection_id"} {
+		if value, ok := metadata[key]; ok {
+			extra[key] = value
+		}
+	}
+
+	if isGRPCWeb(r) {
+		a.serveWeb(w, r, payload, extra)
+		return
+	}
+	a.serveREST(w, r, payload, extra)
+}
+
+// isGRPCWeb reports whether r is a gRPC-Web call
+func isGRPCWeb(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web")
+}
+
+// serveWeb serves a gRPC-Web call
+func (a *ServerAdapter) serveWeb(w http.ResponseWriter, r *http.Request, payload GRPCRequestPayload, extra map[string]string) {
+	text := strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web-text")
+	sink := &webSink{w: w, text: text}
+
+	payload.Method = r.URL.Path
+	payload.Protocol = "grpc-web"
+	if text {
+		payload.Protocol = "grpc-web-text"
+	}
+
+	desc, err := a.lookup(payload.Method)
+	if err != nil {
+		sink.end(status.Convert(err), nil)
+		return
+	}
+	msg, err := a.readWebMessage(r.Body, text)
+	if err != nil {
+		sink.end(status.Convert(err), nil)
+		return
+	}
+	payload.Message = msg
+
+	ctx := r.Context()
+	if timeout, ok := parseTimeout(r.Header.Get("Grpc-Timeout")); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	st, trailers := a.serveCall(ctx, payload, desc, sink, extra)
+	sink.end(st, trailers)
+}
+
+// readWebMessage reads the first message frame of a gRPC-Web request body
+func (a *ServerAdapter) readWebMessage(body io.Reader, text bool) ([]byte, error) {
+	limit := int64(a.opts.maxMessageSize) + 5
+	if text {
+		limit = limit*4/3 + 4
+	}
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read request: %v", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, status.Errorf(codes.ResourceExhausted, "request larger than %d bytes", a.opts.maxMessageSize)
+	}
+	if text {
+		if data, err = decodeWebText(data); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid grpc-web-text body: %v", err)
+		}
+	}
+
+	if len(data) < 5 {
+		return nil, status.Error(codes.InvalidArgument, "missing request message")
+	}
+	if data[0]&0x01 != 0 {
+		return nil, status.Error(codes.Unimplemented, "compressed grpc-web messages are not supported")
+	}
+	length := binary.BigEndian.Uint32(data[1:5])
+	if uint64(length) > uint64(len(data)-5) {
+		return nil, status.Error(codes.InvalidArgument, "truncated request message")
+	}
+	return data[5 : 5+length], nil
+}
+
+// decodeWebText decodes a grpc-web-text body. Clients may send several
+// padded base64 chunks back to back, so each 4-byte group is decoded alone.
+func decodeWebText(data []byte) ([]byte, error) {
+	data = bytes.Join(bytes.Fields(data), nil)
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("length %d is not a multiple of 4", len(data))
+	}
+	out := make([]byte, 0, len(data)/4*3)
+	var group [3]byte
+	for i := 0; i < len(data); i += 4 {
+		n, err := base64.StdEncoding.Decode(group[:], data[i:i+4])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, group[:n]...)
+	}
+	return out, nil
+}
+
+// webSink answers a gRPC-Web call with length-prefixed frames
+type webSink struct {
+	w       http.ResponseWriter
+	text    bool
+	started bool
+}
+
+func (s *webSink) header(md metadata.MD) {
+	setHeaders(s.w.Header(), md)
+}
+
+func (s *webSink) send(msg []byte) error {
+	return s.frame(0x00, msg)
+}
+
+// end writes the trailer frame carrying the status and trailers
+func (s *webSink) end(st *status.Status, trailers metadata.MD) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "grpc-status: %d\r\n", st.Code())
+	if msg := st.Message(); msg != "" {
+		fmt.Fprintf(&buf, "grpc-message: %s\r\n", encodeGRPCMessage(msg))
+	}
+	if len(st.Proto().GetDetails()) > 0 {
+		if b, err := proto.Marshal(st.Proto()); err == nil {
+			fmt.Fprintf(&buf, "grpc-status-details-bin: %s\r\n", base64.RawStdEncoding.EncodeToString(b))
+		}
+	}
+	for key, values := range trailers {
+		for _, value := range values {
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, headerValue(key, value))
+		}
+	}
+	s.frame(0x80, buf.Bytes())
+}
+
+// frame writes one frame, starting the response on the first
+func (s *webSink) frame(flag byte, data []byte) error {
+	if !s.started {
+		s.started = true
+		contentType := "application/grpc-web+proto"
+		if s.text {
+			contentType = "application/grpc-web-text+proto"
+		}
+		s.w.Header().Set("Content-Type", contentType)
+		s.w.WriteHeader(http.StatusOK)
+	}
+
+	buf := make([]byte, 5+len(data))
+	buf[0] = flag
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(data)))
+	copy(buf[5:], data)
+	if s.text {
+		buf = []byte(base64.StdEncoding.EncodeToString(buf))
+	}
+	if _, err := s.w.Write(buf); err != nil {
+		return status.Errorf(codes.Unavailable, "failed to write response: %v", err)
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// headerMetadata converts HTTP headers to request metadata the way native
+// calls carry it: lowercase keys, first values, "-bin" values in base64
+func headerMetadata(h http.Header) map[string]string {
+	m := make(map[string]string, len(h))
+	for key, values := range h {
+		if len(values) > 0 {
+			m[strings.ToLower(key)] = values[0] // Take first value
+		}
+	}
+	return m
+}
+
+// setHeaders copies response metadata to HTTP headers
+func setHeaders(h http.Header, md metadata.MD) {
+	for key, values := range md {
+		for _, value := range values {
+			h.Add(key, headerValue(key, value))
+		}
+	}
+}
+
+// headerValue encodes a metadata value for an HTTP header
+func headerValue(key, value string) string {
+	if strings.HasSuffix(key, "-bin") {
+		return base64.RawStdEncoding.EncodeToString([]byte(value))
+	}
+	return value
+}
+
+// encodeGRPCMessage percent-encodes a status message for the grpc-message field
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// parseTimeout parses a grpc-timeout header value such as "250m"
+func parseTimeout(s string) (time.Duration, bool) {
+	if len(s) < 2 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	unit, ok := units[s[len(s)-1]]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	nethttp "github.com/BYTE-6D65/netadapters/pkg/http"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"google.golang.org/grpc/codes"
+)
+
+// startGateway runs a gateway-only ServerAdapter behind an HTTP
+// ServerAdapter on addr and returns the base URL
+func startGateway(t *testing.T, addr string, handler func(GRPCRequestPayload) []GRPCResponsePayload, opts ...Option) string {
+	t.Helper()
+
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+	answerRequests(t, eng, handler)
+
+	adapter := NewServerAdapter("", opts...)
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Register(nethttp.NewServerAdapter(addr, nethttp.WithGateway(adapter))); err != nil {
+		t.Fatalf("Failed to register HTTP adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	t.Cleanup(func() { adapterMgr.Stop() })
+
+	time.Sleep(100 * time.Millisecond)
+	return "http://localhost" + addr
+}
+
+// webFrames splits a gRPC-Web response body into data messages and trailers
+func webFrames(t *testing.T, body []byte) ([]string, string) {
+	t.Helper()
+
+	var messages []string
+	for len(body) >= 5 {
+		n := binary.BigEndian.Uint32(body[1:5])
+		data := string(body[5 : 5+n])
+		if body[0]&0x80 != 0 {
+			return messages, data
+		}
+		messages = append(messages, data)
+		body = body[5+n:]
+	}
+	t.Fatalf("Missing trailer frame")
+	return nil, ""
+}
+
+func webFrame(msg []byte) []byte {
+	buf := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(msg)))
+	copy(buf[5:], msg)
+	return buf
+}
+
+func TestServerAdapter_GRPCWeb(t *testing.T) {
+	var seen GRPCRequestPayload
+	base := startGateway(t, ":18097", func(req GRPCRequestPayload) []GRPCResponsePayload {
+		seen = req
+		if string(req.Message) == "fail" {
+			return []GRPCResponsePayload{{Code: uint32(codes.NotFound), StatusMessage: "no such thing: 100%"}}
+		}
+		return []GRPCResponsePayload{
+			{Message: []byte("one"), More: true, Headers: map[string]string{"x-handler": "pipeline"}},
+			{Message: []byte("two"), Trailers: map[string]string{"x-trace": "abc"}},
+		}
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, base+"/feed.Feed/Watch", bytes.NewReader(webFrame([]byte("sub"))))
+		req.Header.Set("Content-Type", "application/grpc-web+proto")
+		req.Header.Set("X-Tenant", "acme")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+
+		if resp.Header.Get("Content-Type") != "application/grpc-web+proto" || resp.Header.Get("X-Handler") != "pipeline" {
+			t.Errorf("Unexpected headers %v", resp.Header)
+		}
+		messages, trailers := webFrames(t, body)
+		if len(messages) != 2 || messages[0] != "one" || messages[1] != "two" {
+			t.Errorf("Unexpected messages %q", messages)
+		}
+		if !strings.Contains(trailers, "grpc-status: 0\r\n") || !strings.Contains(trailers, "x-trace: abc\r\n") {
+			t.Errorf("Unexpected trailers %q", trailers)
+		}
+		if seen.Protocol != "grpc-web" || seen.Method != "/feed.Feed/Watch" || string(seen.Message) != "sub" || seen.Metadata["x-tenant"] != "acme" {
+			t.Errorf("Unexpected request payload %+v", seen)
+		}
+	})
+
+	t.Run("text with error", func(t *testing.T) {
+		body := base64.StdEncoding.EncodeToString(webFrame([]byte("fail")))
+		req, _ := http.NewRequest(http.MethodPost, base+"/feed.Feed/Get", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/grpc-web-text")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		raw, _ := io.ReadAll(resp.Body)
+
+		decoded, err := decodeWebText(raw)
+		if err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		messages, trailers := webFrames(t, decoded)
+		if len(messages) != 0 {
+			t.Errorf("Expected no messages for a failed call, got %q", messages)
+		}
+		if !strings.Contains(trailers, "grpc-status: 5\r\n") || !strings.Contains(trailers, "grpc-message: no such thing: 100%25\r\n") {
+			t.Errorf("Unexpected trailers %q", trailers)
+		}
+		if seen.Protocol != "grpc-web-text" {
+			t.Errorf("Expected grpc-web-text protocol, got %q", seen.Protocol)
+		}
+	})
+}
+
+func TestDecodeWebText(t *testing.T) {
+	// Two independently padded chunks
+	chunks := base64.StdEncoding.EncodeToString([]byte("ab")) + base64.StdEncoding.EncodeToString([]byte("cde"))
+	got, err := decodeWebText([]byte(chunks))
+	if err != nil || string(got) != "abcde" {
+		t.Errorf("decodeWebText(%q) = %q, %v", chunks, got, err)
+	}
+	if _, err := decodeWebText([]byte("abc")); err == nil {
+		t.Error("Expected error for truncated base64")
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := map[string]time.Duration{"250m": 250 * time.Millisecond, "2S": 2 * time.Second, "1H": time.Hour}
+	for in, want := range tests {
+		if got, ok := parseTimeout(in); !ok || got != want {
+			t.Errorf("parseTimeout(%q) = %v, %v", in, got, ok)
+		}
+	}
+	for _, in := range []string{"", "5", "5x", "-1S"} {
+		if _, ok := parseTimeout(in); ok {
+			t.Errorf("parseTimeout(%q) should fail", in)
+		}
+	}
+}
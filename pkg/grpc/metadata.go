@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataMap flattens gRPC metadata to its first values. Binary ("-bin")
+// values are base64 encoded so every codec can carry them.
+func metadataMap(md metadata.MD) map[string]string {
+	m := make(map[string]string, len(md))
+	for key, values := range md {
+		if len(values) == 0 {
+			continue
+		}
+		if strings.HasSuffix(key, "-bin") {
+			m[key] = base64.StdEncoding.EncodeToString([]byte(values[0]))
+		} else {
+			m[key] = values[0] // Take first value
+		}
+	}
+	return m
+}
+
+// metadataFrom builds gRPC metadata from a map, decoding base64 "-bin"
+// values. Values that are not valid base64 are sent as they are.
+func metadataFrom(m map[string]string) metadata.MD {
+	md := make(metadata.MD, len(m))
+	for key, value := range m {
+		key = strings.ToLower(key)
+		if strings.HasSuffix(key, "-bin") {
+			if b, err := base64.StdEncoding.DecodeString(value); err == nil {
+				value = string(b)
+			}
+		}
+		md.Append(key, value)
+	}
+	return md
+}
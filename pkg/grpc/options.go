@@ -0,0 +1,156 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"google.golang.org/grpc"
+)
+
+// Option configures a ServerAdapter or an emitter in this package
+type Option func(*options)
+
+// options holds the settings shared by adapters and emitters
+type options struct {
+	codec codec.Codec
+
+	// Schemas
+	descriptors *Descriptors
+
+	// Server and client
+	timeout        time.Duration
+	tlsConfig      *tls.Config
+	maxMessageSize int
+	serverOptions  []grpc.ServerOption
+	dialOptions    []grpc.DialOption
+
+	// Client connection pool
+	idleTimeout time.Duration
+	maxTargets  int
+
+	clk    clock.Clock
+	logger *slog.Logger
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		codec:          codec.JSON{},
+		timeout:        30 * time.Second,
+		maxMessageSize: 4 << 20, // 4MB, the gRPC default
+		idleTimeout:    5 * time.Minute,
+		maxTargets:     1024,
+		clk:            clock.System(),
+		logger:         slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCodec sets the payload codec.
+// The ServerAdapter encodes request events with it; emitters use it to decode
+// events that carry no "codec" metadata.
+func WithCodec(c codec.Codec) Option {
+	return func(o *options) {
+		if c != nil {
+			o.codec = c
+		}
+	}
+}
+
+// WithDescriptors sets the protobuf schemas used to translate messages to and
+// from JSON. Methods missing from d are still served with raw messages.
+func WithDescriptors(d *Descriptors) Option {
+	return func(o *options) {
+		o.descriptors = d
+	}
+}
+
+// WithTimeout sets how long the ServerAdapter waits for a response event
+// (default 30s). For server-streaming calls the wait restarts with every
+// response. Calls that time out fail with DEADLINE_EXCEEDED.
+// It is also the ClientEmitter's deadline for calls that set no TimeoutMs.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// WithTLSConfig makes the ServerAdapter accept only TLS connections and the
+// ClientEmitter dial targets with TLS (plaintext otherwise)
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithMaxMessageSize limits the size of received requests and replies (default 4MB)
+func WithMaxMessageSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxMessageSize = n
+		}
+	}
+}
+
+// WithServerOptions passes extra options to the underlying grpc.Server,
+// such as interceptors or keepalive policies
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(o *options) {
+		o.serverOptions = append(o.serverOptions, opts...)
+	}
+}
+
+// WithDialOptions passes extra options to the ClientEmitter's connections,
+// such as interceptors or per-RPC credentials
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// WithIdleTimeout sets how long the ClientEmitter keeps a connection to a
+// target that has no calls (default 5 minutes). A later call dials it afresh.
+// Zero keeps connections until the emitter is closed.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.idleTimeout = max(d, 0)
+	}
+}
+
+// WithMaxTargets limits how many targets the ClientEmitter keeps connections
+// to (default 1024). Calls to a new target beyond the limit fail with
+// RESOURCE_EXHAUSTED until idle connections are evicted. Zero means no limit.
+func WithMaxTargets(n int) Option {
+	return func(o *options) {
+		o.maxTargets = max(n, 0)
+	}
+}
+
+// WithClock sets the clock the ClientEmitter timestamps and times calls and
+// evicts idle connections with (defaults to the system clock). The ServerAdapter uses the clock passed to
+// Start.
+func WithClock(clk clock.Clock) Option {
+	return func(o *options) {
+		if clk != nil {
+			o.clk = clk
+		}
+	}
+}
+
+// WithLogger sets the logger the ServerAdapter writes server errors to
+// (defaults to slog.Default())
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package grpc
+
+import "fmt"
+
+// rawMessage is a protobuf message kept in its wire encoding
+type rawMessage []byte
+
+// rawCodec passes messages through as bytes, so any method can be served or
+// called without generated types. It replaces the "proto" codec on the
+// connections that use it.
+type rawCodec struct{}
+
+// Marshal returns the bytes of a *rawMessage
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("raw codec: cannot marshal %T", v)
+	}
+	return *m, nil
+}
+
+// Unmarshal copies data into a *rawMessage; gRPC may reuse data afterwards
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("raw codec: cannot unmarshal into %T", v)
+	}
+	*m = append((*m)[:0], data...)
+	return nil
+}
+
+// Name identifies the codec as "proto" so peers see the standard content type
+func (rawCodec) Name() string {
+	return "proto"
+}
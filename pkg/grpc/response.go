@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// ResponseEmitter answers calls held by a ServerAdapter. It consumes
+// "net.grpc.response" events carrying a GRPCResponsePayload.
+type ResponseEmitter struct {
+	id    string
+	opts  options
+	stats metrics.Counters
+}
+
+// NewResponseEmitter creates a new gRPC response emitter
+func NewResponseEmitter(opts ...Option) *ResponseEmitter {
+	return &ResponseEmitter{
+		id:   "grpc-response-emitter",
+		opts: newOptions(opts),
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *ResponseEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *ResponseEmitter) Type() string {
+	return "grpc-response"
+}
+
+// Emit writes the response to the call waiting under its request ID
+func (e *ResponseEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	// Decode response payload with the codec named in its metadata
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var payload GRPCResponsePayload
+	if err := evt.DecodePayload(&payload, c); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	// Get response stream from registry by request ID
+	rs, ok := lookupResponseStream(payload.RequestID)
+	if !ok {
+		return fmt.Errorf("no response stream found for request ID %s", payload.RequestID)
+	}
+	if err := rs.WriteResponse(payload); err != nil {
+		return err
+	}
+	e.stats.Sent(len(payload.Message) + len(payload.JSON))
+	return nil
+}
+
+// Metrics returns the emitter's counters: response message bytes (raw or
+// JSON, as given) and responses that could not be written
+func (e *ResponseEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "grpc", e.opts.clk.Wall())
+}
+
+// Close closes the emitter (no-op for the response emitter)
+func (e *ResponseEmitter) Close() error {
+	return nil
+}
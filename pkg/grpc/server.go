@@ -0,0 +1,473 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/correlate"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ServerAdapter serves gRPC calls to any service and method and publishes
+// each call as a "net.grpc.request" event carrying the raw request message.
+// Responses come back as "net.grpc.response" events through a
+// ResponseEmitter, correlated by request ID. Unary and server-streaming
+// calls are supported; client-streaming calls see only their first message.
+//
+// The adapter is also a GatewayHandler for the HTTP ServerAdapter (see
+// ServeGateway), so gRPC-Web and REST calls reach the same handlers.
+type ServerAdapter struct {
+	id     string
+	addr   string
+	ln     net.Listener
+	server *grpc.Server
+	bus    event.Bus
+	clk    clock.Clock
+	ctx    context.Context
+	opts   options
+	stats  metrics.Counters
+
+	// Calls waiting for a response, by request ID; replaced by each Start
+	responses atomic.Pointer[correlate.Table[*responseStream]]
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewServerAdapter creates a new gRPC server adapter
+func NewServerAdapter(addr string, opts ...Option) *ServerAdapter {
+	return &ServerAdapter{
+		id:   fmt.Sprintf("grpc-server-%s", addr),
+		addr: addr,
+		clk:  clock.System(),
+		opts: newOptions(opts),
+	}
+}
+
+// ID returns the adapter's unique identifier
+func (a *ServerAdapter) ID() string {
+	return a.id
+}
+
+// Type returns the adapter type
+func (a *ServerAdapter) Type() string {
+	return "grpc-server"
+}
+
+// Start begins serving gRPC calls. Timestamps, the response timeout and the
+// shutdown grace period are taken from clk (see clock.From).
+func (a *ServerAdapter) Start(ctx context.Context, bus event.Bus, clk pipeclock.Clock) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		return fmt.Errorf("adapter already running")
+	}
+
+	// Without an address, calls arrive through ServeGateway only
+	var ln net.Listener
+	if a.addr != "" {
+		var err error
+		if ln, err = net.Listen("tcp", a.addr); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", a.addr, err)
+		}
+	}
+
+	a.bus = bus
+	a.clk = clock.From(clk)
+	a.ctx = ctx
+	a.responses.Store(correlate.NewTable[*responseStream](correlate.WithClock(a.clk)))
+	servers.Store(a, struct{}{})
+
+	if ln == nil {
+		a.running = true
+		return nil
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.UnknownServiceHandler(a.handleStream),
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.MaxRecvMsgSize(a.opts.maxMessageSize),
+	}
+	if a.opts.tlsConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(a.opts.tlsConfig)))
+	}
+	serverOpts = append(serverOpts, a.opts.serverOptions...)
+
+	a.ln = ln
+	a.server = grpc.NewServer(serverOpts...)
+
+	server := a.server
+	go func() {
+		if err := server.Serve(metrics.NewListener(ln, &a.stats)); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			a.opts.logger.Error("gRPC server failed", "adapter_id", a.id, "error", err)
+		}
+	}()
+
+	a.running = true
+	return nil
+}
+
+// Stop shuts down the server, giving in-flight calls up to 5 seconds to
+// finish. Calls still waiting for their final response end with Unavailable.
+func (a *ServerAdapter) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.running {
+		return nil
+	}
+	a.running = false
+	defer servers.Delete(a)
+
+	// Release waiting calls so the graceful stop need not wait out their timeout
+	a.responses.Load().CancelAll(correlate.ErrCanceled)
+	if a.server == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		a.server.GracefulStop()
+		close(done)
+	}()
+	grace := a.clk.NewTimer(5 * time.Second)
+	defer grace.Stop()
+	select {
+	case <-done:
+	case <-grace.C():
+		a.server.Stop()
+	}
+	return nil
+}
+
+// Addr returns the listening address, or nil before Start
+func (a *ServerAdapter) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ln == nil {
+		return nil
+	}
+	return a.ln.Addr()
+}
+
+// Metrics returns the adapter's traffic counters. Bytes are counted on the
+// socket, including HTTP/2 framing; calls served through ServeGateway are
+// counted by the HTTP adapter instead. Errors are calls that could not be
+// handed to the pipeline or got no response in time. Replies counts the calls
+// waiting for a response since the last Start.
+func (a *ServerAdapter) Metrics() metrics.NetworkMetrics {
+	snapshot := a.stats.Snapshot(a.id, a.Type(), "grpc", a.clk.Wall())
+	if responses := a.responses.Load(); responses != nil {
+		replies := responses.Stats()
+		snapshot.Replies = &replies
+	}
+	return snapshot
+}
+
+// handleStream serves a native gRPC call
+func (a *ServerAdapter) handleStream(_ any, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	method, _ := grpc.MethodFromServerStream(stream)
+
+	// Look up the schema first so unsupported calls fail before reading
+	desc, err := a.lookup(method)
+	if err != nil {
+		return err
+	}
+
+	var msg rawMessage
+	if err := stream.RecvMsg(&msg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.InvalidArgument, "missing request message")
+		}
+		return err
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	payload := GRPCRequestPayload{
+		Method:    method,
+		Protocol:  "grpc",
+		Metadata:  metadataMap(md),
+		Message:   msg,
+		LocalAddr: a.addr,
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		payload.RemoteAddr = p.Addr.String()
+		if p.LocalAddr != nil {
+			payload.LocalAddr = p.LocalAddr.String()
+		}
+		_, payload.TLS = p.AuthInfo.(credentials.TLSInfo)
+	}
+
+	sink := &streamSink{stream: stream}
+	st, trailers := a.serveCall(ctx, payload, desc, sink, nil)
+	sink.end(st, trailers)
+	return st.Err()
+}
+
+// lookup returns the method's descriptor (nil when unknown or without
+// descriptors), rejecting client-streaming methods
+func (a *ServerAdapter) lookup(method string) (protoreflect.MethodDescriptor, error) {
+	if a.opts.descriptors == nil {
+		return nil, nil
+	}
+	desc, err := a.opts.descriptors.Method(method)
+	if err != nil {
+		return nil, nil
+	}
+	if desc.IsStreamingClient() {
+		return nil, status.Errorf(codes.Unimplemented, "client-streaming method %s is not supported", method)
+	}
+	return desc, nil
+}
+
+// serveCall publishes a call as an event and relays its responses to sink
+// until the final response or a timeout. It returns the call's status and
+// trailers; the caller ends the sink with them. extra is copied into the
+// event metadata.
+func (a *ServerAdapter) serveCall(ctx context.Context, payload GRPCRequestPayload, desc protoreflect.MethodDescriptor, sink callSink, extra map[string]string) (*status.Status, metadata.MD) {
+	// Generate request ID unless the transport already assigned one
+	if payload.RequestID == "" {
+		payload.RequestID = uuid.New().String()
+	}
+	payload.Timestamp = a.clk.Wall()
+	if deadline, ok := ctx.Deadline(); ok {
+		payload.Deadline = deadline
+	}
+	if desc != nil && payload.JSON == nil {
+		js, err := a.opts.descriptors.ToJSON(desc.Input(), payload.Message)
+		if err != nil {
+			return status.New(codes.InvalidArgument, err.Error()), nil
+		}
+		payload.JSON = js
+	}
+
+	// Create event with the configured codec (recorded in metadata)
+	evt, err := codec.NewEvent("net.grpc.request", a.id, payload, a.opts.codec)
+	if err != nil {
+		a.stats.Error()
+		return status.New(codes.Internal, "failed to create event"), nil
+	}
+	evt.WithMetadata("adapter_id", a.id).
+		WithMetadata("request_id", payload.RequestID).
+		WithMetadata("method", payload.Method)
+	for key, value := range extra {
+		evt.WithMetadata(key, value)
+	}
+
+	// Register the call for the emitter to find by request ID
+	rs := &responseStream{
+		sink:        sink,
+		requestID:   payload.RequestID,
+		method:      desc,
+		descriptors: a.opts.descriptors,
+		progress:    make(chan struct{}, 1),
+	}
+	pending, err := a.responses.Load().Register(payload.RequestID, rs, 0)
+	if err != nil {
+		a.stats.Error()
+		return status.New(codes.Internal, "failed to process request"), nil
+	}
+	rs.pending = pending
+
+	if err := a.bus.Publish(a.ctx, evt); err != nil {
+		pending.Cancel(err)
+		a.stats.Error()
+		return status.New(codes.Unavailable, "failed to process request"), nil
+	}
+
+	// Wait for the final response; each streamed message restarts the timeout
+	timer := a.clk.NewTimer(a.opts.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-pending.Done():
+			return rs.result()
+		case <-rs.progress:
+			timer.Reset(a.opts.timeout)
+		case <-ctx.Done():
+			if rs.cancel(ctx.Err()) {
+				return status.FromContextError(ctx.Err()), nil
+			}
+			<-pending.Done() // The emitter claimed the final response first
+			return rs.result()
+		case <-timer.C():
+			if rs.cancel(correlate.ErrTimeout) {
+				a.stats.Error()
+				return status.New(codes.DeadlineExceeded, "no response from pipeline"), nil
+			}
+			<-pending.Done()
+			return rs.result()
+		}
+	}
+}
+
+// callSink carries a call's responses back over one wire format.
+// header and send are called by WriteResponse; end is called once by the
+// serving goroutine after the last send.
+type callSink interface {
+	header(md metadata.MD)
+	send(msg []byte) error
+	end(st *status.Status, trailers metadata.MD)
+}
+
+// streamSink answers a native gRPC call
+type streamSink struct {
+	stream grpc.ServerStream
+}
+
+func (s *streamSink) header(md metadata.MD) {
+	s.stream.SetHeader(md)
+}
+
+func (s *streamSink) send(msg []byte) error {
+	m := rawMessage(msg)
+	return s.stream.SendMsg(&m)
+}
+
+// end sets the trailers; the handler returns the status itself
+func (s *streamSink) end(_ *status.Status, trailers metadata.MD) {
+	if len(trailers) > 0 {
+		s.stream.SetTrailer(trailers)
+	}
+}
+
+// responseStream writes response events to a waiting call
+type responseStream struct {
+	sink        callSink
+	requestID   string
+	method      protoreflect.MethodDescriptor // nil without descriptors
+	descriptors *Descriptors
+	progress    chan struct{}
+	pending     *correlate.Pending[*responseStream] // Claimed once, by the writer of the final response
+
+	// Held while sending, so a canceled call sends nothing once cancel returns
+	mu       sync.Mutex
+	sent     int            // Messages sent
+	status   *status.Status // Call status, set before the entry is finished
+	trailers metadata.MD    // Call trailers, set before the entry is finished
+}
+
+// WriteResponse sends a response message and, unless More is set, ends the
+// call with the response's status (called by emitter)
+func (rs *responseStream) WriteResponse(resp GRPCResponsePayload) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	select {
+	case <-rs.pending.Done():
+		return fmt.Errorf("response for request %s already written or timed out", rs.requestID)
+	default:
+	}
+
+	if rs.sent == 0 && len(resp.Headers) > 0 {
+		rs.sink.header(metadataFrom(resp.Headers))
+	}
+
+	// A failed call carries no message. A unary call needs exactly one,
+	// even if it is empty; streams only send what they are given.
+	failed := !resp.More && codes.Code(resp.Code) != codes.OK
+	if !failed && (resp.More || rs.sent == 0 || len(resp.Message) > 0 || len(resp.JSON) > 0) {
+		msg, err := rs.encode(resp)
+		if err != nil {
+			rs.finish(status.New(codes.Internal, err.Error()), nil, err)
+			return err
+		}
+		if err := rs.sink.send(msg); err != nil {
+			rs.finish(status.Convert(err), nil, err)
+			return err
+		}
+		rs.sent++
+	}
+
+	if resp.More {
+		select {
+		case rs.progress <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+
+	return rs.finish(status.New(codes.Code(resp.Code), resp.StatusMessage), metadataFrom(resp.Trailers), nil)
+}
+
+// encode returns the response message, converting JSON with the descriptors
+func (rs *responseStream) encode(resp GRPCResponsePayload) ([]byte, error) {
+	if len(resp.Message) > 0 || len(resp.JSON) == 0 {
+		return resp.Message, nil
+	}
+	if rs.method == nil {
+		return nil, fmt.Errorf("no descriptor to encode the JSON response for request %s", rs.requestID)
+	}
+	return rs.descriptors.FromJSON(rs.method.Output(), resp.JSON)
+}
+
+// finish claims the call, records its outcome and releases the waiting
+// handler. cause is the error that ended delivery early, if any. It fails
+// once the call was canceled or timed out. Called with mu held.
+func (rs *responseStream) finish(st *status.Status, trailers metadata.MD, cause error) error {
+	if !rs.pending.Claim() {
+		return fmt.Errorf("call for request %s ended before its response", rs.requestID)
+	}
+	rs.status, rs.trailers = st, trailers
+	rs.pending.Finish(cause)
+	return nil
+}
+
+// cancel ends the wait with cause unless the final response was claimed first
+func (rs *responseStream) cancel(cause error) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.pending.Cancel(cause)
+}
+
+// result returns the status and trailers of a completed call
+func (rs *responseStream) result() (*status.Status, metadata.MD) {
+	// Stop cancels without mu; wait out a message still being sent
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if errors.Is(rs.pending.Err(), correlate.ErrCanceled) {
+		return status.New(codes.Unavailable, "server shutting down"), nil
+	}
+	return rs.status, rs.trailers
+}
+
+// servers holds the running adapters, whose response tables
+// lookupResponseStream searches
+var servers sync.Map // *ServerAdapter -> struct{}
+
+// lookupResponseStream retrieves a waiting call by request ID from the running
+// adapter that received it
+func lookupResponseStream(requestID string) (*responseStream, bool) {
+	var rs *responseStream
+	servers.Range(func(key, _ any) bool {
+		if p, ok := key.(*ServerAdapter).responses.Load().Peek(requestID); ok {
+			rs = p.Handle()
+			return false
+		}
+		return true
+	})
+	return rs, rs != nil
+}
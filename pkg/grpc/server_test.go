@@ -0,0 +1,395 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startServer runs a ServerAdapter whose calls are answered by handler and
+// returns a client connection to it
+func startServer(t *testing.T, handler func(GRPCRequestPayload) []GRPCResponsePayload, opts ...Option) *grpc.ClientConn {
+	t.Helper()
+
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+	answerRequests(t, eng, handler)
+
+	adapter := NewServerAdapter("127.0.0.1:0", opts...)
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	t.Cleanup(func() { adapterMgr.Stop() })
+
+	conn, err := grpc.NewClient(adapter.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// answerRequests answers request events with handler, the way a pipeline
+// handler would
+func answerRequests(t *testing.T, eng *engine.Engine, handler func(GRPCRequestPayload) []GRPCResponsePayload) {
+	t.Helper()
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.grpc.request"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+
+	emitter := NewResponseEmitter()
+	go func() {
+		for evt := range sub.Events() {
+			var req GRPCRequestPayload
+			if err := codec.Decode(evt, &req); err != nil {
+				continue
+			}
+			for _, resp := range handler(req) {
+				resp.RequestID = req.RequestID
+				respEvt, err := codec.NewEvent("net.grpc.response", "test", resp, codec.JSON{})
+				if err != nil {
+					continue
+				}
+				emitter.Emit(context.Background(), respEvt)
+			}
+		}
+	}()
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestServerAdapter_UnaryRaw(t *testing.T) {
+	var seen GRPCRequestPayload
+	conn := startServer(t, func(req GRPCRequestPayload) []GRPCResponsePayload {
+		seen = req
+		return []GRPCResponsePayload{{
+			Message:  append([]byte("echo:"), req.Message...),
+			Headers:  map[string]string{"x-handler": "pipeline"},
+			Trailers: map[string]string{"x-trace-bin": "AQI="},
+		}}
+	})
+
+	ctx := metadata.AppendToOutgoingContext(testContext(t), "x-tenant", "acme")
+	req, reply := rawMessage("\x0a\x02hi"), rawMessage(nil)
+	var header, trailer metadata.MD
+	err := conn.Invoke(ctx, "/any.Service/Do", &req, &reply, grpc.Header(&header), grpc.Trailer(&trailer))
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+
+	if string(reply) != "echo:\x0a\x02hi" {
+		t.Errorf("Unexpected reply %q", reply)
+	}
+	if seen.Method != "/any.Service/Do" || seen.Metadata["x-tenant"] != "acme" || seen.RemoteAddr == "" {
+		t.Errorf("Unexpected request payload %+v", seen)
+	}
+	if len(seen.JSON) != 0 {
+		t.Errorf("Expected no JSON without descriptors, got %s", seen.JSON)
+	}
+	if got := header.Get("x-handler"); len(got) != 1 || got[0] != "pipeline" {
+		t.Errorf("Expected response header, got %v", header)
+	}
+	if got := trailer.Get("x-trace-bin"); len(got) != 1 || got[0] != "\x01\x02" {
+		t.Errorf("Expected decoded binary trailer, got %v", trailer)
+	}
+}
+
+func TestServerAdapter_ServerStreaming(t *testing.T) {
+	conn := startServer(t, func(req GRPCRequestPayload) []GRPCResponsePayload {
+		return []GRPCResponsePayload{
+			{Message: []byte("one"), More: true},
+			{Message: []byte("two"), More: true},
+			{Message: []byte("three")},
+		}
+	})
+
+	stream, err := conn.NewStream(testContext(t), &grpc.StreamDesc{ServerStreams: true}, "/feed.Feed/Watch")
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	req := rawMessage("sub")
+	if err := stream.SendMsg(&req); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	stream.CloseSend()
+
+	var got []string
+	for {
+		var msg rawMessage
+		err := stream.RecvMsg(&msg)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Stream failed: %v", err)
+		}
+		got = append(got, string(msg))
+	}
+	if len(got) != 3 || got[0] != "one" || got[2] != "three" {
+		t.Errorf("Unexpected stream messages %q", got)
+	}
+}
+
+func TestServerAdapter_ErrorStatus(t *testing.T) {
+	conn := startServer(t, func(req GRPCRequestPayload) []GRPCResponsePayload {
+		return []GRPCResponsePayload{{Code: uint32(codes.NotFound), StatusMessage: "no such user"}}
+	})
+
+	req, reply := rawMessage("x"), rawMessage(nil)
+	err := conn.Invoke(testContext(t), "/users.Users/Get", &req, &reply)
+	if st := status.Convert(err); st.Code() != codes.NotFound || st.Message() != "no such user" {
+		t.Errorf("Expected NOT_FOUND, got %v", err)
+	}
+}
+
+func TestServerAdapter_Timeout(t *testing.T) {
+	conn := startServer(t, func(req GRPCRequestPayload) []GRPCResponsePayload {
+		return nil // Never answers
+	}, WithTimeout(50*time.Millisecond))
+
+	req, reply := rawMessage("x"), rawMessage(nil)
+	err := conn.Invoke(testContext(t), "/slow.Slow/Wait", &req, &reply)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected DEADLINE_EXCEEDED, got %v", err)
+	}
+}
+
+func TestServerAdapter_TimeoutOnClock(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.grpc.request"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	// Nothing answers, so the call waits out the response timeout on the fake clock
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	adapter := NewServerAdapter("127.0.0.1:0")
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), clk); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	conn, err := grpc.NewClient(adapter.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		req, reply := rawMessage("x"), rawMessage(nil)
+		done <- conn.Invoke(testContext(t), "/slow.Slow/Wait", &req, &reply)
+	}()
+
+	select {
+	case evt := <-sub.Events():
+		var req GRPCRequestPayload
+		if err := codec.Decode(evt, &req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if !req.Timestamp.Equal(start) {
+			t.Errorf("Expected timestamp %v from the clock, got %v", start, req.Timestamp)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for request event")
+	}
+
+	clk.BlockUntil(1)
+	clk.Advance(30 * time.Second)
+
+	select {
+	case err := <-done:
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("Expected DEADLINE_EXCEEDED, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Call did not time out after advancing the clock")
+	}
+	if replies := adapter.Metrics().Replies; replies == nil || replies.TimedOut != 1 || replies.Pending != 0 {
+		t.Errorf("Expected one timed out call in the adapter's table, got %+v", replies)
+	}
+}
+
+func TestServerAdapter_StopCancelsWaitingCalls(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.grpc.request"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	adapter := NewServerAdapter("127.0.0.1:0")
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), clock.System()); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	conn, err := grpc.NewClient(adapter.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		req, reply := rawMessage("x"), rawMessage(nil)
+		done <- conn.Invoke(testContext(t), "/slow.Slow/Wait", &req, &reply)
+	}()
+
+	// Nothing answers; Stop must not wait for the response timeout
+	select {
+	case <-sub.Events():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for request event")
+	}
+
+	start := time.Now()
+	adapter.Stop()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Stop to return promptly, took %v", elapsed)
+	}
+
+	select {
+	case err := <-done:
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("Expected UNAVAILABLE, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Call did not end after Stop")
+	}
+	if replies := adapter.Metrics().Replies; replies == nil || replies.Canceled != 1 {
+		t.Errorf("Expected one canceled call, got %+v", replies)
+	}
+}
+
+func TestServerAdapter_Descriptors(t *testing.T) {
+	d := testDescriptors(t)
+	conn := startServer(t, func(req GRPCRequestPayload) []GRPCResponsePayload {
+		var in struct {
+			Text  string `json:"text"`
+			Count int    `json:"count"`
+		}
+		if err := json.Unmarshal(req.JSON, &in); err != nil {
+			return []GRPCResponsePayload{{Code: uint32(codes.InvalidArgument), StatusMessage: err.Error()}}
+		}
+		out, _ := json.Marshal(map[string]any{"text": in.Text + "!", "count": in.Count + 1})
+		return []GRPCResponsePayload{{JSON: out}}
+	}, WithDescriptors(d))
+
+	req, reply := rawMessage(ping(t, d, "hello", 1)), rawMessage(nil)
+	if err := conn.Invoke(testContext(t), "/test.Echo/Say", &req, &reply); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	md, _ := d.Method("/test.Echo/Say")
+	got, err := d.ToJSON(md.Output(), reply)
+	if err != nil {
+		t.Fatalf("Failed to decode reply: %v", err)
+	}
+	var out map[string]any
+	json.Unmarshal(got, &out)
+	if out["text"] != "hello!" || out["count"] != float64(2) {
+		t.Errorf("Unexpected reply %s", got)
+	}
+
+	// Client streaming needs every request message, which one event cannot carry
+	stream, err := conn.NewStream(testContext(t), &grpc.StreamDesc{ClientStreams: true}, "/test.Echo/Upload")
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	stream.CloseSend()
+	if err := stream.RecvMsg(&reply); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected UNIMPLEMENTED for client streaming, got %v", err)
+	}
+}
+
+func TestResponseEmitter_UnknownRequest(t *testing.T) {
+	evt, err := codec.NewEvent("net.grpc.response", "test", GRPCResponsePayload{RequestID: "missing"}, codec.JSON{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if err := NewResponseEmitter().Emit(context.Background(), evt); err == nil {
+		t.Error("Expected error for unknown request ID")
+	}
+}
+
+func TestServerAdapter_LogsServerErrors(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	var logs syncBuffer
+	adapter := NewServerAdapter("127.0.0.1:0", WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), nil); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	// Closing the listener under the server makes Serve fail
+	adapter.ln.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	if !strings.Contains(logs.String(), "gRPC server failed") {
+		t.Errorf("Expected the serve error to be logged, got %q", logs.String())
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
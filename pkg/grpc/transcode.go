@@ -0,0 +1,582 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// httpRoute maps an HTTP method and path template to a gRPC method, as
+// declared by a google.api.http annotation
+type httpRoute struct {
+	verb         string // HTTP method
+	template     *pathTemplate
+	body         string // "*", a request field, or "" for no body
+	responseBody string // Response field returned instead of the whole message
+	method       protoreflect.MethodDescriptor
+}
+
+// httpRoutes collects the routes declared on every method in files
+func httpRoutes(files *protoregistry.Files) ([]httpRoute, error) {
+	var routes []httpRoute
+	var err error
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				md := methods.Get(j)
+				rule := httpRule(md)
+				if rule == nil {
+					continue
+				}
+				for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+					route, rerr := newHTTPRoute(md, r)
+					if rerr != nil {
+						err = fmt.Errorf("invalid http annotation on %s: %w", md.FullName(), rerr)
+						return false
+					}
+					routes = append(routes, route)
+				}
+			}
+		}
+		return true
+	})
+
+	// Custom verbs are more specific than a variable that would take the
+	// whole last segment, so those templates are tried first
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].template.verb != "" && routes[j].template.verb == ""
+	})
+	return routes, err
+}
+
+// httpRule returns the method's google.api.http annotation, if any
+func httpRule(md protoreflect.MethodDescriptor) *annotations.HttpRule {
+	opts, ok := md.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil {
+		return nil
+	}
+
+	// Options parsed before the extension was linked keep it as unknown fields
+	if len(opts.ProtoReflect().GetUnknown()) > 0 {
+		b, err := proto.Marshal(opts)
+		if err != nil {
+			return nil
+		}
+		opts = new(descriptorpb.MethodOptions)
+		if proto.Unmarshal(b, opts) != nil {
+			return nil
+		}
+	}
+
+	rule, _ := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+	return rule
+}
+
+// newHTTPRoute builds a route from one rule (or additional binding)
+func newHTTPRoute(md protoreflect.MethodDescriptor, rule *annotations.HttpRule) (httpRoute, error) {
+	route := httpRoute{
+		body:         rule.GetBody(),
+		responseBody: rule.GetResponseBody(),
+		method:       md,
+	}
+
+	var path string
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		route.verb, path = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		route.verb, path = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		route.verb, path = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		route.verb, path = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		route.verb, path = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		route.verb, path = p.Custom.GetKind(), p.Custom.GetPath()
+	default:
+		return route, fmt.Errorf("missing pattern")
+	}
+
+	template, err := parseTemplate(path)
+	if err != nil {
+		return route, err
+	}
+	route.template = template
+
+	if route.body != "" && route.body != "*" && md.Input().Fields().ByName(protoreflect.Name(route.body)) == nil {
+		return route, fmt.Errorf("unknown body field %q", route.body)
+	}
+	if route.responseBody != "" && md.Output().Fields().ByName(protoreflect.Name(route.responseBody)) == nil {
+		return route, fmt.Errorf("unknown response_body field %q", route.responseBody)
+	}
+	return route, nil
+}
+
+// route finds the route matching an HTTP method and escaped path, returning
+// its path variables
+func (d *Descriptors) route(verb, path string) (httpRoute, map[string]string, bool) {
+	if d == nil {
+		return httpRoute{}, nil, false
+	}
+	for _, route := range d.routes {
+		if route.verb != verb {
+			continue
+		}
+		if vars, ok := route.template.match(path); ok {
+			return route, vars, true
+		}
+	}
+	return httpRoute{}, nil, false
+}
+
+// pathTemplate is a parsed google.api.http path template, such as
+// "/v1/{name=shelves/*/books/*}:publish"
+type pathTemplate struct {
+	segments []string // Literal, "*" or "**"
+	vars     []templateVar
+	verb     string
+	deep     int // Index of the "**" segment, or -1
+}
+
+// templateVar binds segments [start, end) to a request field path
+type templateVar struct {
+	field      string
+	start, end int
+}
+
+// parseTemplate parses a path template
+func parseTemplate(s string) (*pathTemplate, error) {
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("path template %q must start with /", s)
+	}
+	t := &pathTemplate{deep: -1}
+	rest := s[1:]
+
+	// Split on slashes outside variables and cut the trailing ":verb"
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				parts = append(parts, rest[start:i])
+				start = i + 1
+			}
+		case ':':
+			if depth == 0 {
+				t.verb = rest[i+1:]
+				rest = rest[:i]
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced braces in path template %q", s)
+	}
+	if rest != "" {
+		parts = append(parts, rest[start:])
+	}
+
+	for _, part := range parts {
+		if !strings.HasPrefix(part, "{") {
+			t.segments = append(t.segments, part)
+			continue
+		}
+		if !strings.HasSuffix(part, "}") {
+			return nil, fmt.Errorf("invalid variable %q in path template %q", part, s)
+		}
+		field, pattern, ok := strings.Cut(part[1:len(part)-1], "=")
+		if !ok {
+			pattern = "*"
+		}
+		v := templateVar{field: field, start: len(t.segments)}
+		t.segments = append(t.segments, strings.Split(pattern, "/")...)
+		v.end = len(t.segments)
+		t.vars = append(t.vars, v)
+	}
+
+	for i, seg := range t.segments {
+		switch {
+		case seg == "":
+			return nil, fmt.Errorf("empty segment in path template %q", s)
+		case seg == "**" && t.deep >= 0:
+			return nil, fmt.Errorf("more than one ** in path template %q", s)
+		case seg == "**":
+			t.deep = i
+		}
+	}
+	return t, nil
+}
+
+// match matches an escaped request path, returning the unescaped variables
+func (t *pathTemplate) match(path string) (map[string]string, bool) {
+	rest := strings.TrimPrefix(path, "/")
+	if t.verb != "" {
+		var ok bool
+		if rest, ok = strings.CutSuffix(rest, ":"+t.verb); !ok {
+			return nil, false
+		}
+	}
+	var parts []string
+	if rest != "" {
+		parts = strings.Split(rest, "/")
+	}
+
+	// pos[i] is the first path part matched by template segment i
+	n := len(t.segments)
+	pos := make([]int, n+1)
+	if t.deep < 0 && len(parts) != n || t.deep >= 0 && len(parts) < n-1 {
+		return nil, false
+	}
+	for i, p := 0, 0; i < n; i++ {
+		pos[i] = p
+		switch seg := t.segments[i]; seg {
+		case "**":
+			p += len(parts) - (n - 1)
+		case "*":
+			if parts[p] == "" {
+				return nil, false
+			}
+			p++
+		default:
+			if unescapePath(parts[p]) != seg {
+				return nil, false
+			}
+			p++
+		}
+	}
+	pos[n] = len(parts)
+
+	vars := make(map[string]string, len(t.vars))
+	for _, v := range t.vars {
+		values := make([]string, 0, pos[v.end]-pos[v.start])
+		for _, part := range parts[pos[v.start]:pos[v.end]] {
+			values = append(values, unescapePath(part))
+		}
+		vars[v.field] = strings.Join(values, "/")
+	}
+	return vars, true
+}
+
+// serveREST serves a REST call transcoded to its gRPC method
+func (a *ServerAdapter) serveREST(w http.ResponseWriter, r *http.Request, payload GRPCRequestPayload, extra map[string]string) {
+	route, vars, ok := a.opts.descriptors.route(r.Method, r.URL.EscapedPath())
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	sink := &restSink{w: w, route: route, descriptors: a.opts.descriptors}
+
+	payload.Method = fmt.Sprintf("/%s/%s", route.method.Parent().FullName(), route.method.Name())
+	payload.Protocol = "rest"
+
+	if route.method.IsStreamingClient() {
+		sink.end(status.Newf(codes.Unimplemented, "client-streaming method %s is not supported", payload.Method), nil)
+		return
+	}
+	msg, err := a.restRequest(r, route, vars)
+	if err != nil {
+		sink.end(status.Convert(err), nil)
+		return
+	}
+	payload.Message = msg
+
+	st, trailers := a.serveCall(r.Context(), payload, route.method, sink, extra)
+	sink.end(st, trailers)
+}
+
+// restRequest builds the request message from the body, path variables and
+// query parameters. Query parameters that name no field are ignored.
+func (a *ServerAdapter) restRequest(r *http.Request, route httpRoute, vars map[string]string) ([]byte, error) {
+	msg := dynamicpb.NewMessage(route.method.Input())
+	unmarshal := protojson.UnmarshalOptions{Resolver: a.opts.descriptors.types}
+
+	// The body goes first: unmarshaling resets the message
+	if route.body != "" {
+		body, err := io.ReadAll(io.LimitReader(r.Body, int64(a.opts.maxMessageSize)+1))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to read request: %v", err)
+		}
+		if len(body) > a.opts.maxMessageSize {
+			return nil, status.Errorf(codes.ResourceExhausted, "request larger than %d bytes", a.opts.maxMessageSize)
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			if route.body != "*" {
+				fd := msg.Descriptor().Fields().ByName(protoreflect.Name(route.body))
+				key, _ := json.Marshal(fd.JSONName())
+				body = fmt.Appendf(nil, "{%s:%s}", key, body)
+			}
+			if err := unmarshal.Unmarshal(body, msg); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+			}
+		}
+	}
+
+	for field, value := range vars {
+		if err := setField(msg, field, []string{value}); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid path variable %s: %v", field, err)
+		}
+	}
+
+	if route.body != "*" {
+		for key, values := range r.URL.Query() {
+			if _, bound := vars[key]; bound || key == route.body {
+				continue
+			}
+			if err := setField(msg, key, values); err != nil && !errors.Is(err, errNoField) {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid query parameter %s: %v", key, err)
+			}
+		}
+	}
+
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode request: %v", err)
+	}
+	return b, nil
+}
+
+// errNoField is returned by setField for a path that names no field
+var errNoField = errors.New("no such field")
+
+// setField sets a scalar field by its dotted path of proto or JSON names.
+// Repeated fields take every value; others take the last.
+func setField(msg protoreflect.Message, path string, values []string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fields := msg.Descriptor().Fields()
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = fields.ByJSONName(name)
+		}
+		if fd == nil {
+			return errNoField
+		}
+
+		if i < len(names)-1 {
+			if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+				return errNoField
+			}
+			msg = msg.Mutable(fd).Message()
+			continue
+		}
+
+		if fd.IsMap() {
+			return fmt.Errorf("map fields cannot be set from the URL")
+		}
+		if fd.IsList() {
+			list := msg.Mutable(fd).List()
+			for _, s := range values {
+				v, err := scalarValue(fd, s)
+				if err != nil {
+					return err
+				}
+				list.Append(v)
+			}
+			return nil
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		v, err := scalarValue(fd, values[len(values)-1])
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, v)
+	}
+	return nil
+}
+
+// scalarValue parses s as a value of the field's scalar kind
+func scalarValue(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(b), err
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("%s fields cannot be set from the URL", fd.Kind())
+	}
+}
+
+// restSink answers a REST call with JSON
+type restSink struct {
+	w           http.ResponseWriter
+	route       httpRoute
+	descriptors *Descriptors
+	started     bool
+}
+
+func (s *restSink) header(md metadata.MD) {
+	setHeaders(s.w.Header(), md)
+}
+
+// send writes a response message as JSON; streamed messages are one per line
+func (s *restSink) send(msg []byte) error {
+	js, err := s.responseJSON(msg)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if s.route.method.IsStreamingServer() {
+		js = append(js, '\n')
+	}
+	s.start(http.StatusOK)
+	if _, err := s.w.Write(js); err != nil {
+		return status.Errorf(codes.Unavailable, "failed to write response: %v", err)
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// responseJSON converts a response message, selecting response_body if set
+func (s *restSink) responseJSON(msg []byte) ([]byte, error) {
+	out := s.route.method.Output()
+	if s.route.responseBody == "" {
+		return s.descriptors.ToJSON(out, msg)
+	}
+
+	m := dynamicpb.NewMessage(out)
+	if err := (proto.UnmarshalOptions{Resolver: s.descriptors.types}).Unmarshal(msg, m); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", out.FullName(), err)
+	}
+	js, err := protojson.MarshalOptions{Resolver: s.descriptors.types, EmitUnpopulated: true}.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(js, &fields); err != nil {
+		return nil, err
+	}
+	return fields[out.Fields().ByName(protoreflect.Name(s.route.responseBody)).JSONName()], nil
+}
+
+// end sends trailers, or the error as a google.rpc.Status JSON object. A
+// stream that already started reports its error as a final line.
+func (s *restSink) end(st *status.Status, trailers metadata.MD) {
+	if st.Code() == codes.OK {
+		s.start(http.StatusOK)
+		for key, values := range trailers {
+			for _, value := range values {
+				s.w.Header().Add(http.TrailerPrefix+key, headerValue(key, value))
+			}
+		}
+		return
+	}
+
+	js, err := protojson.Marshal(st.Proto())
+	if err != nil {
+		js, _ = json.Marshal(map[string]any{"code": st.Code(), "message": st.Message()})
+	}
+	if s.started {
+		s.w.Write(fmt.Appendf(nil, "{\"error\":%s}\n", js))
+		return
+	}
+	s.start(httpStatus(st.Code()))
+	s.w.Write(js)
+}
+
+// start writes the response header once
+func (s *restSink) start(code int) {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.w.Header().Set("Content-Type", "application/json")
+	s.w.WriteHeader(code)
+}
+
+// httpStatus maps a gRPC status code to its HTTP equivalent
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // Client Closed Request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// unescapePath decodes a path segment, keeping it as is when malformed
+func unescapePath(s string) string {
+	if v, err := url.PathUnescape(s); err == nil {
+		return v
+	}
+	return s
+}
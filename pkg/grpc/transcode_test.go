@@ -0,0 +1,167 @@
+package grpc
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// annotatedDescriptors returns testDescriptorSet with http annotations:
+//
+//	Say:   get: "/v1/pings/{text}"
+//	       additional_bindings { post: "/v1/pings" body: "*" }
+//	       additional_bindings { get: "/v1/texts/{text=**}" response_body: "text" }
+//	Watch: get: "/v1/pings/{text}:watch"
+func annotatedDescriptors(t *testing.T) *Descriptors {
+	t.Helper()
+
+	set := testDescriptorSet()
+	annotate := func(md *descriptorpb.MethodDescriptorProto, rule *annotations.HttpRule) {
+		md.Options = &descriptorpb.MethodOptions{}
+		proto.SetExtension(md.Options, annotations.E_Http, rule)
+	}
+	methods := set.File[0].Service[0].Method
+	annotate(methods[0], &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/pings/{text}"},
+		AdditionalBindings: []*annotations.HttpRule{
+			{Pattern: &annotations.HttpRule_Post{Post: "/v1/pings"}, Body: "*"},
+			{Pattern: &annotations.HttpRule_Get{Get: "/v1/texts/{text=**}"}, ResponseBody: "text"},
+		},
+	})
+	annotate(methods[1], &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/pings/{text}:watch"},
+	})
+
+	// Round trip through the wire format, as a descriptor set file would be
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatalf("Failed to marshal descriptor set: %v", err)
+	}
+	d, err := ParseDescriptorSet(data)
+	if err != nil {
+		t.Fatalf("Failed to parse descriptor set: %v", err)
+	}
+	return d
+}
+
+func TestPathTemplate(t *testing.T) {
+	tests := []struct {
+		template, path string
+		vars           map[string]string // nil when the path must not match
+	}{
+		{"/v1/pings/{text}", "/v1/pings/hi%20there", map[string]string{"text": "hi there"}},
+		{"/v1/pings/{text}", "/v1/pings/a/b", nil},
+		{"/v1/{name=shelves/*/books/*}", "/v1/shelves/1/books/2", map[string]string{"name": "shelves/1/books/2"}},
+		{"/v1/{name=shelves/*/books/*}", "/v1/shelves/1/novels/2", nil},
+		{"/v1/files/{path=**}", "/v1/files/a/b/c", map[string]string{"path": "a/b/c"}},
+		{"/v1/{name=items/*}:publish", "/v1/items/7:publish", map[string]string{"name": "items/7"}},
+		{"/v1/{name=items/*}:publish", "/v1/items/7", nil},
+		{"/v1/*/status", "/v1/anything/status", map[string]string{}},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseTemplate(tt.template)
+		if err != nil {
+			t.Fatalf("parseTemplate(%q): %v", tt.template, err)
+		}
+		vars, ok := tmpl.match(tt.path)
+		if ok != (tt.vars != nil) {
+			t.Errorf("%s matching %s: got %v, want match %v", tt.template, tt.path, ok, tt.vars != nil)
+			continue
+		}
+		for k, v := range tt.vars {
+			if vars[k] != v {
+				t.Errorf("%s matching %s: %s = %q, want %q", tt.template, tt.path, k, vars[k], v)
+			}
+		}
+	}
+
+	for _, bad := range []string{"v1/x", "/v1/{name", "/v1/**/**", "/v1//x"} {
+		if _, err := parseTemplate(bad); err == nil {
+			t.Errorf("parseTemplate(%q) should fail", bad)
+		}
+	}
+}
+
+func TestServerAdapter_REST(t *testing.T) {
+	d := annotatedDescriptors(t)
+	var seen GRPCRequestPayload
+	base := startGateway(t, ":18098", func(req GRPCRequestPayload) []GRPCResponsePayload {
+		seen = req
+		var in struct {
+			Text  string `json:"text"`
+			Count int    `json:"count"`
+		}
+		json.Unmarshal(req.JSON, &in)
+		if in.Text == "missing" {
+			return []GRPCResponsePayload{{Code: uint32(codes.NotFound), StatusMessage: "no such ping"}}
+		}
+		out, _ := json.Marshal(map[string]any{"text": in.Text + "!", "count": in.Count + 1})
+		if req.Method == "/test.Echo/Watch" {
+			return []GRPCResponsePayload{{JSON: out, More: true}, {JSON: out}}
+		}
+		return []GRPCResponsePayload{{JSON: out}}
+	}, WithDescriptors(d))
+
+	call := func(method, path, body string) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, base+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	t.Run("path and query", func(t *testing.T) {
+		code, body := call(http.MethodGet, "/v1/pings/hello?count=4&unknown=x", "")
+		if code != http.StatusOK || !strings.Contains(body, `"text":"hello!"`) || !strings.Contains(body, `"count":5`) {
+			t.Errorf("Unexpected response %d %s", code, body)
+		}
+		if seen.Protocol != "rest" || seen.Method != "/test.Echo/Say" {
+			t.Errorf("Unexpected request payload %+v", seen)
+		}
+	})
+
+	t.Run("body", func(t *testing.T) {
+		code, body := call(http.MethodPost, "/v1/pings", `{"text": "posted", "count": 1}`)
+		if code != http.StatusOK || !strings.Contains(body, `"text":"posted!"`) {
+			t.Errorf("Unexpected response %d %s", code, body)
+		}
+	})
+
+	t.Run("response body", func(t *testing.T) {
+		code, body := call(http.MethodGet, "/v1/texts/a/b", "")
+		if code != http.StatusOK || body != `"a/b!"` {
+			t.Errorf("Unexpected response %d %s", code, body)
+		}
+	})
+
+	t.Run("server streaming", func(t *testing.T) {
+		code, body := call(http.MethodGet, "/v1/pings/tick:watch", "")
+		if lines := strings.Split(strings.TrimSpace(body), "\n"); code != http.StatusOK || len(lines) != 2 {
+			t.Errorf("Expected two JSON lines, got %d %q", code, body)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		code, body := call(http.MethodGet, "/v1/pings/missing", "")
+		if code != http.StatusNotFound || !strings.Contains(body, `"no such ping"`) {
+			t.Errorf("Expected 404 status JSON, got %d %s", code, body)
+		}
+		if code, _ := call(http.MethodGet, "/v1/pings/x?count=many", ""); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for a bad query parameter, got %d", code)
+		}
+		if code, _ := call(http.MethodPost, "/v1/pings", `{"nope": 1}`); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for a bad body, got %d", code)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GRPCRequestPayload represents a "net.grpc.request" event
+type GRPCRequestPayload struct {
+	// Identity
+	RequestID string `json:"request_id"` // UUID for correlation
+
+	// Call data
+	Method   string            `json:"method"`         // Full method name: /package.Service/Method
+	Protocol string            `json:"protocol"`       // grpc, grpc-web, grpc-web-text or rest
+	Metadata map[string]string `json:"metadata"`       // Request metadata; "-bin" values are base64
+	Message  []byte            `json:"message"`        // Raw protobuf request message
+	JSON     json.RawMessage   `json:"json,omitempty"` // Message as protobuf JSON (with descriptors)
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Client IP:port
+	LocalAddr  string `json:"local_addr"`  // Server IP:port
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"`         // When received
+	Deadline  time.Time `json:"deadline,omitzero"` // Client deadline, if any
+	TLS       bool      `json:"tls"`               // Transport security?
+}
+
+// GRPCResponsePayload represents a "net.grpc.response" event.
+// A unary call takes a single response. A server-streaming call takes one
+// response per message with More set, then a final response that may carry
+// a last message and sets the status.
+type GRPCResponsePayload struct {
+	// Correlation
+	RequestID string `json:"request_id"` // Match to request
+
+	// Response data
+	Message []byte          `json:"message,omitempty"` // Raw protobuf response message
+	JSON    json.RawMessage `json:"json,omitempty"`    // Message as protobuf JSON (needs descriptors)
+	More    bool            `json:"more,omitempty"`    // Further responses follow
+
+	// Status (final response only)
+	Code          uint32 `json:"code"`                     // gRPC status code; 0 is OK
+	StatusMessage string `json:"status_message,omitempty"` // Error description
+
+	// Metadata
+	Headers   map[string]string `json:"headers,omitempty"`  // Sent with the first response
+	Trailers  map[string]string `json:"trailers,omitempty"` // Sent with the status
+	Timestamp time.Time         `json:"timestamp"`          // When sent
+}
+
+// GRPCCallPayload represents a "net.grpc.call" event for the ClientEmitter.
+// The request is Message, or JSON encoded with the emitter's descriptors.
+type GRPCCallPayload struct {
+	// Identity
+	CallID string `json:"call_id"` // UUID for correlation; copied to the reply
+
+	// Call data
+	Target   string            `json:"target"`             // host:port or a gRPC target URI (dns:///svc:443)
+	Method   string            `json:"method"`             // Full method name: /package.Service/Method
+	Metadata map[string]string `json:"metadata,omitempty"` // Request metadata; "-bin" values are base64
+	Message  []byte            `json:"message,omitempty"`  // Raw protobuf request message
+	JSON     json.RawMessage   `json:"json,omitempty"`     // Request as protobuf JSON (needs descriptors)
+
+	// Deadline
+	TimeoutMs int64 `json:"timeout_ms,omitempty"` // Overrides the emitter's default timeout
+}
+
+// GRPCReplyPayload represents a "net.grpc.reply" event
+type GRPCReplyPayload struct {
+	// Correlation
+	CallID string `json:"call_id"` // From the call event
+	Target string `json:"target"`  // Called target
+	Method string `json:"method"`  // Called method
+
+	// Reply data
+	Message []byte          `json:"message"`        // Raw protobuf reply message
+	JSON    json.RawMessage `json:"json,omitempty"` // Reply as protobuf JSON (with descriptors)
+
+	// Metadata
+	Headers    map[string]string `json:"headers,omitempty"`  // Response header metadata
+	Trailers   map[string]string `json:"trailers,omitempty"` // Response trailer metadata
+	Timestamp  time.Time         `json:"timestamp"`          // When the reply arrived
+	DurationNs int64             `json:"duration_ns"`        // Call time in nanoseconds
+}
+
+// GRPCErrorPayload represents a "net.grpc.error" event
+type GRPCErrorPayload struct {
+	// Correlation
+	CallID string `json:"call_id"` // From the call event
+	Target string `json:"target"`  // Called target
+	Method string `json:"method"`  // Called method
+
+	// Status
+	Code     uint32             `json:"code"`              // gRPC status code
+	CodeName string             `json:"code_name"`         // NotFound, Unavailable, etc.
+	Message  string             `json:"message"`           // Status message
+	Details  []GRPCStatusDetail `json:"details,omitempty"` // Status details (google.rpc.Status)
+
+	// Metadata
+	Headers    map[string]string `json:"headers,omitempty"`  // Response header metadata
+	Trailers   map[string]string `json:"trailers,omitempty"` // Response trailer metadata
+	Timestamp  time.Time         `json:"timestamp"`          // When the call failed
+	DurationNs int64             `json:"duration_ns"`        // Call time in nanoseconds
+}
+
+// GRPCStatusDetail is one detail message attached to a gRPC status
+type GRPCStatusDetail struct {
+	Type  string          `json:"type"`           // Type URL: type.googleapis.com/google.rpc.ErrorInfo
+	Value []byte          `json:"value"`          // Raw protobuf message
+	JSON  json.RawMessage `json:"json,omitempty"` // Message as protobuf JSON, when its type is known
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+)
+
+// AccessLogFormat selects how the ServerAdapter writes access log records
+type AccessLogFormat int
+
+const (
+	// AccessLogCommon writes each request as a Common Log Format line in the
+	// record message, with the remaining fields as attributes
+	AccessLogCommon AccessLogFormat = iota + 1
+
+	// AccessLogCombined is AccessLogCommon with the referer and user agent
+	AccessLogCombined
+
+	// AccessLogJSON writes each request as attributes only, including the
+	// request headers; use it with a slog.JSONHandler for JSON lines
+	AccessLogJSON
+)
+
+// redacted replaces the values of redacted headers in access logs
+const redacted = "REDACTED"
+
+// defaultRedactedHeaders are never written to access logs
+var defaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// accessRecorder captures the status, size and timing of a response for the
+// access log. It passes Flush and Hijack through for gateways and upgrades.
+type accessRecorder struct {
+	http.ResponseWriter
+	clk        clock.Clock
+	status     int
+	bytes      int64
+	firstWrite clock.MonoTime // When the status line was written (status is set)
+}
+
+func (r *accessRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.firstWrite = r.clk.Now()
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *accessRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *accessRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *accessRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	nc, brw, err := h.Hijack()
+	if err == nil && r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+		r.firstWrite = r.clk.Now()
+	}
+	return nc, brw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *accessRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// accessEntry is one request as seen by the access log
+type accessEntry struct {
+	requestID string
+	connID    string
+	principal string
+	route     string         // Pattern that answered the request, if known
+	accepted  clock.MonoTime // When the request arrived
+	received  time.Time      // Wall-clock arrival time, for Common Log Format
+	timing    *HTTPTiming    // Stage breakdown, for requests published to the bus
+}
+
+// logAccess writes the access log record for a finished request, subject to
+// sampling. Server errors (5xx) are always logged.
+func (a *ServerAdapter) logAccess(ctx context.Context, r *http.Request, rec *accessRecorder, entry accessEntry) {
+	end := a.clk.Now()
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK // Nothing written; net/http sends an empty 200
+	}
+	if status < http.StatusInternalServerError && a.opts.accessLogSampling < 1 &&
+		rand.Float64() >= a.opts.accessLogSampling {
+		return
+	}
+
+	logger := a.opts.logger
+	if !logger.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+
+	// Time waiting on the pipeline, then writing the response
+	var busWait, write time.Duration
+	switch {
+	case entry.timing != nil:
+		busWait = time.Duration(entry.timing.PipelineNs)
+		write = time.Duration(entry.timing.WriteNs)
+	case rec.status != 0:
+		write = a.clk.Since(rec.firstWrite)
+	}
+
+	attrs := []slog.Attr{
+		slog.String("request_id", entry.requestID),
+		slog.Duration("duration", clock.ToDuration(end-entry.accepted)),
+		slog.Duration("bus_wait", busWait),
+		slog.Duration("write", write),
+	}
+	if entry.route != "" {
+		attrs = append(attrs, slog.String("route", entry.route))
+	}
+
+	switch a.opts.accessLogFormat {
+	case AccessLogJSON:
+		attrs = append(attrs,
+			slog.String("connection_id", entry.connID),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("method", r.Method),
+			slog.String("uri", r.RequestURI),
+			slog.String("proto", r.Proto),
+			slog.Int("status", status),
+			slog.Int64("bytes", rec.bytes),
+			slog.String("principal", entry.principal),
+			slog.String("referer", a.headerValue(r, "Referer")),
+			slog.String("user_agent", a.headerValue(r, "User-Agent")),
+			slog.Any("headers", a.headerGroup(r)),
+		)
+		logger.LogAttrs(ctx, slog.LevelInfo, "http request", attrs...)
+	default:
+		logger.LogAttrs(ctx, slog.LevelInfo, a.commonLogLine(r, status, rec.bytes, entry.principal, entry.received), attrs...)
+	}
+}
+
+// commonLogLine formats a request in Common (or Combined) Log Format
+func (a *ServerAdapter) commonLogLine(r *http.Request, status int, bytes int64, principal string, start time.Time) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	size := "-"
+	if bytes > 0 {
+		size = strconv.FormatInt(bytes, 10)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s - %s [%s] \"%s %s %s\" %d %s",
+		host, orDash(principal), start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, r.RequestURI, r.Proto, status, size)
+	if a.opts.accessLogFormat == AccessLogCombined {
+		fmt.Fprintf(&b, " %q %q", orDash(a.headerValue(r, "Referer")), orDash(a.headerValue(r, "User-Agent")))
+	}
+	return b.String()
+}
+
+// headerValue returns a request header for the access log, redacted if configured
+func (a *ServerAdapter) headerValue(r *http.Request, name string) string {
+	value := r.Header.Get(name)
+	if value != "" && a.opts.redactedHeaders[http.CanonicalHeaderKey(name)] {
+		return redacted
+	}
+	return value
+}
+
+// headerGroup returns the request headers as a log group, redacted if configured
+func (a *ServerAdapter) headerGroup(r *http.Request) slog.Value {
+	attrs := make([]slog.Attr, 0, len(r.Header))
+	for _, key := range slices.Sorted(maps.Keys(r.Header)) {
+		value := strings.Join(r.Header[key], ", ")
+		if a.opts.redactedHeaders[key] {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(key, value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// orDash returns s, or "-" when it is empty (the Common Log Format placeholder)
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
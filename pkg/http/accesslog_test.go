@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// logBuffer collects log output from concurrent handlers
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// wait returns the first line, waiting for it to be written: the access log
+// record follows the response
+func (b *logBuffer) wait(t *testing.T) string {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if line := b.lines()[0]; line != "" {
+			return line
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for a log record")
+	return ""
+}
+
+// lines returns the complete lines written so far
+func (b *logBuffer) lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Split(strings.TrimSpace(b.buf.String()), "\n")
+}
+
+func TestServerAdapter_AccessLog(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	var logs logBuffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	adapter := NewServerAdapter(":18088",
+		WithLogger(logger),
+		WithAccessLog(AccessLogJSON),
+		WithRedactedHeaders("x-api-key"),
+		WithAuthenticator(AuthenticatorFunc(func(r *http.Request) (string, error) {
+			return "alice", nil
+		})),
+	)
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	emitterMgr := engine.NewEmitterManager(eng)
+	if err := emitterMgr.Register("http-client", NewClientEmitter(), event.Filter{
+		Types: []string{"net.http.response"},
+	}); err != nil {
+		t.Fatalf("Failed to register emitter: %v", err)
+	}
+	if err := emitterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start emitters: %v", err)
+	}
+	defer emitterMgr.Stop()
+
+	router := NewRouter(eng.ExternalBus())
+	router.Handle("GET /users/:id", func(ctx context.Context, req *Request) (*Response, error) {
+		return &Response{Body: []byte("user " + req.Params["id"])}, nil
+	})
+	if err := router.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start router: %v", err)
+	}
+	defer router.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	req, _ := http.NewRequest("GET", "http://localhost:18088/users/7?verbose=1", nil)
+	req.Header.Set("X-Api-Key", "secret")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("User-Agent", "access-log-test")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+
+	line := logs.wait(t)
+	var record map[string]any
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		t.Fatalf("Failed to decode log record %q: %v", line, err)
+	}
+
+	expected := map[string]any{
+		"msg":        "http request",
+		"method":     "GET",
+		"uri":        "/users/7?verbose=1",
+		"route":      "GET /users/:id",
+		"status":     float64(200),
+		"bytes":      float64(len("user 7")),
+		"principal":  "alice",
+		"user_agent": "access-log-test",
+	}
+	for key, want := range expected {
+		if record[key] != want {
+			t.Errorf("Expected %s=%v, got %v", key, want, record[key])
+		}
+	}
+	if record["request_id"] == "" || record["bus_wait"] == nil || record["write"] == nil {
+		t.Errorf("Expected request ID and timings, got %v", record)
+	}
+
+	headers, _ := record["headers"].(map[string]any)
+	if headers["X-Api-Key"] != redacted || headers["Authorization"] != redacted {
+		t.Errorf("Expected redacted headers, got %v", headers)
+	}
+	if strings.Contains(line, "secret") {
+		t.Errorf("Secret leaked into access log: %s", line)
+	}
+}
+
+func TestCommonLogLine(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://example.com/a?b=c", nil)
+	r.RequestURI = "/a?b=c"
+	r.RemoteAddr = "10.0.0.1:5000"
+	r.Header.Set("User-Agent", "curl/8.0")
+	start := time.Date(2025, 10, 10, 13, 55, 36, 0, time.UTC)
+
+	a := NewServerAdapter(":0", WithAccessLog(AccessLogCommon))
+	want := `10.0.0.1 - bob [10/Oct/2025:13:55:36 +0000] "GET /a?b=c HTTP/1.1" 404 -`
+	if got := a.commonLogLine(r, http.StatusNotFound, 0, "bob", start); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	a = NewServerAdapter(":0", WithAccessLog(AccessLogCombined))
+	want = `10.0.0.1 - - [10/Oct/2025:13:55:36 +0000] "GET /a?b=c HTTP/1.1" 200 512 "-" "curl/8.0"`
+	if got := a.commonLogLine(r, http.StatusOK, 512, "", start); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	var logs logBuffer
+	a := NewServerAdapter(":0",
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithAccessLog(AccessLogCommon),
+		WithAccessLogSampling(0),
+	)
+	r, _ := http.NewRequest("GET", "http://example.com/", nil)
+
+	a.logAccess(context.Background(), r, &accessRecorder{status: http.StatusOK}, accessEntry{received: time.Now()})
+	if logs.lines()[0] != "" {
+		t.Errorf("Expected sampled-out request to be skipped, got %q", logs.lines())
+	}
+
+	a.logAccess(context.Background(), r, &accessRecorder{status: http.StatusBadGateway}, accessEntry{received: time.Now()})
+	if lines := logs.lines(); len(lines) != 1 || !strings.Contains(lines[0], "502") {
+		t.Errorf("Expected server errors to bypass sampling, got %q", lines)
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/BYTE-6D65/netadapters/pkg/blobstore"
+)
+
+// openBody returns a reader for an inline body or a claim-checked blob
+func openBody(ctx context.Context, store blobstore.Store, body []byte, ref *blobstore.Ref) (io.ReadCloser, error) {
+	if ref == nil {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	if store == nil {
+		return nil, fmt.Errorf("body %s is claim-checked but no blob store is configured", ref.Key)
+	}
+	return store.Get(ctx, *ref)
+}
+
+// OpenBody returns the request body, resolving BodyRef from store when set
+func (p *HTTPRequestPayload) OpenBody(ctx context.Context, store blobstore.Store) (io.ReadCloser, error) {
+	return openBody(ctx, store, p.Body, p.BodyRef)
+}
+
+// OpenBody returns the response body, resolving BodyRef from store when set
+func (p *HTTPResponsePayload) OpenBody(ctx context.Context, store blobstore.Store) (io.ReadCloser, error) {
+	return openBody(ctx, store, p.Body, p.BodyRef)
+}
+
+// OpenBody returns the outbound body, resolving BodyRef from store when set
+func (p *HTTPOutboundPayload) OpenBody(ctx context.Context, store blobstore.Store) (io.ReadCloser, error) {
+	return openBody(ctx, store, p.Body, p.BodyRef)
+}
+
+// readBody reads a request body, offloading it to store once it exceeds threshold.
+// It returns either the inline body or a claim check, never both.
+func readBody(ctx context.Context, r io.Reader, store blobstore.Store, threshold int64) ([]byte, *blobstore.Ref, error) {
+	if store == nil || threshold <= 0 {
+		body, err := io.ReadAll(r)
+		return body, nil, err
+	}
+
+	head, err := io.ReadAll(io.LimitReader(r, threshold+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(head)) <= threshold {
+		return head, nil, nil
+	}
+
+	ref, err := store.Put(ctx, io.MultiReader(bytes.NewReader(head), r))
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &ref, nil
+}
@@ -3,19 +3,26 @@ package http
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
 	"github.com/BYTE-6D65/pipeline/pkg/event"
 )
 
 // ClientEmitter sends HTTP responses by writing to http.ResponseWriter
 type ClientEmitter struct {
-	id string
+	id    string
+	opts  options
+	stats metrics.Counters
 }
 
 // NewClientEmitter creates a new HTTP client emitter
-func NewClientEmitter() *ClientEmitter {
+func NewClientEmitter(opts ...Option) *ClientEmitter {
 	return &ClientEmitter{
-		id: "http-client-emitter",
+		id:   "http-client-emitter",
+		opts: newOptions(opts),
 	}
 }
 
@@ -30,11 +37,20 @@ func (e *ClientEmitter) Type() string {
 }
 
 // Emit sends an HTTP response by writing to the ResponseWriter
-func (e *ClientEmitter) Emit(ctx context.Context, evt *event.Event) error {
-	// Decode response payload
-	codec := event.JSONCodec{}
+func (e *ClientEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	// Decode response payload with the codec named in its metadata
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
 	var payload HTTPResponsePayload
-	if err := evt.DecodePayload(&payload, codec); err != nil {
+	if err := evt.DecodePayload(&payload, c); err != nil {
 		return fmt.Errorf("failed to decode payload: %w", err)
 	}
 
@@ -44,8 +60,29 @@ func (e *ClientEmitter) Emit(ctx context.Context, evt *event.Event) error {
 		return fmt.Errorf("no response writer found for request ID %s", payload.RequestID)
 	}
 
-	// Write response
-	return rw.WriteResponse(payload.StatusCode, payload.Headers, payload.Body)
+	rw.setRoute(payload.Route)
+
+	// Write response, streaming claim-checked bodies from the blob store
+	if payload.BodyRef == nil {
+		e.stats.Sent(len(payload.Body))
+		return rw.WriteResponse(payload.StatusCode, payload.Headers, payload.Body)
+	}
+
+	body, err := payload.OpenBody(ctx, e.opts.blobs)
+	if err != nil {
+		rw.WriteResponse(http.StatusBadGateway, nil, nil)
+		return fmt.Errorf("failed to resolve response body: %w", err)
+	}
+	defer body.Close()
+
+	e.stats.Sent(int(payload.BodyRef.Size))
+	return rw.WriteResponseFrom(payload.StatusCode, payload.Headers, body)
+}
+
+// Metrics returns the emitter's traffic counters (response body bytes and
+// failed writes)
+func (e *ClientEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "http", time.Now())
 }
 
 // Close closes the emitter (no-op for HTTP client emitter)
@@ -3,9 +3,12 @@ package http
 import (
 	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/correlate"
 	"github.com/BYTE-6D65/pipeline/pkg/event"
 )
 
@@ -85,15 +88,22 @@ func TestClientEmitter_Emit_WriteResponseError(t *testing.T) {
 		Timestamp:  time.Now(),
 	}
 
-	// Create a response writer and mark it as already written
+	// Register a response writer, as a running server does for a request
+	adapter := NewServerAdapter("")
+	adapter.responses.Store(correlate.NewTable[*responseWriter]())
+	servers.Store(adapter, struct{}{})
+	defer servers.Delete(adapter)
+
 	rw := &responseWriter{
-		w:         nil, // Will cause error, but written flag takes precedence
+		w:         httptest.NewRecorder(),
 		requestID: requestID,
-		written:   true, // Already written
-		done:      make(chan struct{}),
+		clk:       clock.System(),
+	}
+	pending, err := adapter.responses.Load().Register(requestID, rw, 0)
+	if err != nil {
+		t.Fatalf("Failed to register response writer: %v", err)
 	}
-	globalResponseWriters.Store(requestID, rw)
-	defer globalResponseWriters.Delete(requestID)
+	rw.pending = pending
 
 	codec := event.JSONCodec{}
 	evt, err := event.NewEvent("net.http.response", "test", payload, codec)
@@ -101,8 +111,21 @@ func TestClientEmitter_Emit_WriteResponseError(t *testing.T) {
 		t.Fatalf("Failed to create event: %v", err)
 	}
 
-	err = emitter.Emit(context.Background(), evt)
-	if err == nil {
+	if err := emitter.Emit(context.Background(), evt); err != nil {
+		t.Fatalf("Expected the first response to be written, got %v", err)
+	}
+
+	// The response is completed at most once
+	if err := rw.WriteResponse(http.StatusOK, nil, []byte("again")); err == nil {
 		t.Error("Expected error when writing response twice, got nil")
 	}
+	if err := emitter.Emit(context.Background(), evt); err == nil {
+		t.Error("Expected error when emitting a response twice, got nil")
+	}
+
+	// The adapter reports its table in its metrics
+	replies := adapter.Metrics().Replies
+	if replies == nil || replies.Registered != 1 || replies.Completed != 1 || replies.Unmatched != 1 {
+		t.Errorf("Unexpected reply stats: %+v", replies)
+	}
 }
@@ -0,0 +1,519 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// CloudEvents HTTP binding (https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/bindings/http-protocol-binding.md)
+const (
+	cloudEventsSpecVersion  = "1.0"
+	cloudEventsHeaderPrefix = "Ce-"
+	cloudEventsStructured   = "application/cloudevents+json"
+	cloudEventsBatch        = "application/cloudevents-batch+json"
+)
+
+// CloudEventsMode selects how a CloudEvent is carried in an HTTP message
+type CloudEventsMode int
+
+const (
+	// CloudEventsBinary carries attributes in ce-* headers and data in the body
+	CloudEventsBinary CloudEventsMode = iota
+	// CloudEventsStructured carries the whole event as application/cloudevents+json
+	CloudEventsStructured
+	// CloudEventsBatchMode carries several structured events as application/cloudevents-batch+json
+	CloudEventsBatchMode
+)
+
+// String returns the mode name used in event metadata
+func (m CloudEventsMode) String() string {
+	switch m {
+	case CloudEventsBinary:
+		return "binary"
+	case CloudEventsStructured:
+		return "structured"
+	case CloudEventsBatchMode:
+		return "batch"
+	default:
+		return fmt.Sprintf("CloudEventsMode(%d)", int(m))
+	}
+}
+
+// cloudEventsContextAttributes are the attributes that are not extensions
+var cloudEventsContextAttributes = map[string]bool{
+	"id": true, "source": true, "specversion": true, "type": true,
+	"datacontenttype": true, "dataschema": true, "subject": true, "time": true,
+	"data": true, "data_base64": true,
+}
+
+// detectCloudEvents reports whether r carries CloudEvents and in which mode
+func detectCloudEvents(r *http.Request) (CloudEventsMode, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == cloudEventsStructured:
+		return CloudEventsStructured, true
+	case mediaType == cloudEventsBatch:
+		return CloudEventsBatchMode, true
+	case r.Header.Get(cloudEventsHeaderPrefix+"Specversion") != "":
+		return CloudEventsBinary, true
+	}
+	return 0, false
+}
+
+// handleCloudEvents publishes each CloudEvent in the request as a pipeline event
+// and responds 202 Accepted. CloudEvents are fire-and-forget, so no response
+// event is awaited.
+func (a *ServerAdapter) handleCloudEvents(ctx context.Context, w http.ResponseWriter, r *http.Request, mode CloudEventsMode, requestID, connID, principal string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.stats.Error()
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var events []CloudEventPayload
+	switch mode {
+	case CloudEventsBinary:
+		var ce CloudEventPayload
+		ce, err = parseBinaryCloudEvent(r.Header, body)
+		events = append(events, ce)
+	case CloudEventsStructured:
+		var ce CloudEventPayload
+		ce, err = parseStructuredCloudEvent(body)
+		events = append(events, ce)
+	case CloudEventsBatchMode:
+		events, err = parseCloudEventsBatch(body)
+	}
+	if err != nil {
+		a.stats.Error()
+		http.Error(w, fmt.Sprintf("Invalid CloudEvent: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Build every event first, so an interceptor refusing one publishes none
+	evts := make([]*event.Event, 0, len(events))
+	for _, ce := range events {
+		ce.RequestID = requestID
+
+		evt, err := codec.NewEvent(ce.Type, ce.Source, ce, a.opts.codec)
+		if err != nil {
+			a.stats.Error()
+			http.Error(w, "Failed to create event", http.StatusInternalServerError)
+			return
+		}
+		evt.ID = ce.ID
+		if !ce.Time.IsZero() {
+			evt.Timestamp = ce.Time
+		}
+		evt.WithMetadata("adapter_id", a.id).
+			WithMetadata("request_id", requestID).
+			WithMetadata("ce_specversion", ce.SpecVersion).
+			WithMetadata("ce_mode", mode.String())
+		if connID != "" {
+			evt.WithMetadata("connection_id", connID)
+		}
+		if principal != "" {
+			evt.WithMetadata("principal", principal)
+		}
+
+		if !a.intercept(ctx, w, &ce, evt) {
+			return
+		}
+		evts = append(evts, evt)
+	}
+
+	for _, evt := range evts {
+		if err := a.bus.Publish(ctx, evt); err != nil {
+			a.stats.Error()
+			http.Error(w, "Failed to process request", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// parseBinaryCloudEvent reads attributes from ce-* headers and data from the body
+func parseBinaryCloudEvent(header http.Header, body []byte) (CloudEventPayload, error) {
+	ce := CloudEventPayload{
+		DataContentType: header.Get("Content-Type"),
+		Data:            body,
+	}
+
+	for key, values := range header {
+		if len(values) == 0 || !strings.HasPrefix(key, cloudEventsHeaderPrefix) {
+			continue
+		}
+		name := strings.ToLower(strings.TrimPrefix(key, cloudEventsHeaderPrefix))
+		value, err := url.PathUnescape(values[0])
+		if err != nil {
+			value = values[0]
+		}
+		if err := ce.setAttribute(name, value); err != nil {
+			return ce, err
+		}
+	}
+
+	return ce, ce.validate()
+}
+
+// parseStructuredCloudEvent decodes one application/cloudevents+json document
+func parseStructuredCloudEvent(body []byte) (CloudEventPayload, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return CloudEventPayload{}, fmt.Errorf("malformed JSON: %w", err)
+	}
+	return cloudEventFromDocument(doc)
+}
+
+// parseCloudEventsBatch decodes an application/cloudevents-batch+json array
+func parseCloudEventsBatch(body []byte) ([]CloudEventPayload, error) {
+	var docs []map[string]json.RawMessage
+	if err := json.Unmarshal(body, &docs); err != nil {
+		return nil, fmt.Errorf("malformed JSON batch: %w", err)
+	}
+
+	events := make([]CloudEventPayload, 0, len(docs))
+	for i, doc := range docs {
+		ce, err := cloudEventFromDocument(doc)
+		if err != nil {
+			return nil, fmt.Errorf("batch event %d: %w", i, err)
+		}
+		events = append(events, ce)
+	}
+	return events, nil
+}
+
+// cloudEventFromDocument converts a structured-mode JSON object into a payload
+func cloudEventFromDocument(doc map[string]json.RawMessage) (CloudEventPayload, error) {
+	var ce CloudEventPayload
+
+	for name, raw := range doc {
+		if name == "data" || name == "data_base64" {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			value = string(raw) // Non-string extension values keep their JSON form
+		}
+		if err := ce.setAttribute(name, value); err != nil {
+			return ce, err
+		}
+	}
+
+	if raw, ok := doc["data_base64"]; ok {
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return ce, fmt.Errorf("data_base64 must be a string")
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return ce, fmt.Errorf("invalid data_base64: %w", err)
+		}
+		ce.Data = data
+	} else if raw, ok := doc["data"]; ok {
+		// JSON data is kept as JSON; a string with a non-JSON content type is the data itself
+		var text string
+		if !isJSONContentType(ce.DataContentType) && json.Unmarshal(raw, &text) == nil {
+			ce.Data = []byte(text)
+		} else {
+			ce.Data = []byte(raw)
+			if ce.DataContentType == "" {
+				ce.DataContentType = "application/json"
+			}
+		}
+	}
+
+	return ce, ce.validate()
+}
+
+// setAttribute assigns a context attribute or extension by name
+func (ce *CloudEventPayload) setAttribute(name, value string) error {
+	switch name {
+	case "id":
+		ce.ID = value
+	case "source":
+		ce.Source = value
+	case "specversion":
+		ce.SpecVersion = value
+	case "type":
+		ce.Type = value
+	case "datacontenttype":
+		ce.DataContentType = value
+	case "dataschema":
+		ce.DataSchema = value
+	case "subject":
+		ce.Subject = value
+	case "time":
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return fmt.Errorf("invalid time %q: %w", value, err)
+		}
+		ce.Time = t
+	default:
+		if ce.Extensions == nil {
+			ce.Extensions = make(map[string]string)
+		}
+		ce.Extensions[name] = value
+	}
+	return nil
+}
+
+// validate checks the required context attributes
+func (ce *CloudEventPayload) validate() error {
+	switch {
+	case ce.SpecVersion != cloudEventsSpecVersion:
+		return fmt.Errorf("unsupported specversion %q", ce.SpecVersion)
+	case ce.ID == "":
+		return fmt.Errorf("missing id")
+	case ce.Source == "":
+		return fmt.Errorf("missing source")
+	case ce.Type == "":
+		return fmt.Errorf("missing type")
+	}
+	return nil
+}
+
+// isJSONContentType reports whether data with this content type is JSON
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// codecContentTypes maps event codecs to the content type of their encoded payloads
+var codecContentTypes = map[string]string{
+	"json":    "application/json",
+	"cbor":    "application/cbor",
+	"msgpack": "application/msgpack",
+	"binary":  "application/octet-stream",
+}
+
+// toCloudEvent converts a pipeline event into a CloudEvent.
+// Events that originated as CloudEvents keep their original attributes and data;
+// other events carry their encoded payload as data and metadata as extensions.
+func toCloudEvent(evt *event.Event) (CloudEventPayload, error) {
+	if evt.Metadata["ce_specversion"] != "" {
+		var ce CloudEventPayload
+		if err := codec.Decode(evt, &ce); err != nil {
+			return ce, fmt.Errorf("failed to decode CloudEvent payload: %w", err)
+		}
+		ce.RequestID = ""
+		return ce, nil
+	}
+
+	ce := CloudEventPayload{
+		ID:          evt.ID,
+		Source:      evt.Source,
+		SpecVersion: cloudEventsSpecVersion,
+		Type:        evt.Type,
+		Time:        evt.Timestamp,
+		Data:        evt.Data,
+	}
+
+	name := evt.Metadata[codec.MetadataKey]
+	if name == "" {
+		name = "json"
+	}
+	ce.DataContentType = codecContentTypes[name]
+	if ce.DataContentType == "" {
+		ce.DataContentType = "application/octet-stream"
+	}
+
+	for key, value := range evt.Metadata {
+		if ext := extensionName(key); ext != "" && !cloudEventsContextAttributes[ext] {
+			if ce.Extensions == nil {
+				ce.Extensions = make(map[string]string)
+			}
+			ce.Extensions[ext] = value
+		}
+	}
+
+	return ce, ce.validate()
+}
+
+// extensionName converts a metadata key into a valid CloudEvents attribute name
+// (lowercase letters and digits only)
+func extensionName(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// marshalStructured encodes a CloudEvent as an application/cloudevents+json document
+func marshalStructured(ce CloudEventPayload) ([]byte, error) {
+	doc := map[string]any{
+		"id":          ce.ID,
+		"source":      ce.Source,
+		"specversion": ce.SpecVersion,
+		"type":        ce.Type,
+	}
+	for name, value := range ce.Extensions {
+		doc[name] = value
+	}
+	if ce.DataContentType != "" {
+		doc["datacontenttype"] = ce.DataContentType
+	}
+	if ce.DataSchema != "" {
+		doc["dataschema"] = ce.DataSchema
+	}
+	if ce.Subject != "" {
+		doc["subject"] = ce.Subject
+	}
+	if !ce.Time.IsZero() {
+		doc["time"] = ce.Time.Format(time.RFC3339Nano)
+	}
+	if len(ce.Data) > 0 {
+		if isJSONContentType(ce.DataContentType) && json.Valid(ce.Data) {
+			doc["data"] = json.RawMessage(ce.Data)
+		} else {
+			doc["data_base64"] = base64.StdEncoding.EncodeToString(ce.Data)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// CloudEventsEmitter POSTs pipeline events to a URL as CloudEvents
+type CloudEventsEmitter struct {
+	id     string
+	target string
+	mode   CloudEventsMode
+	client *http.Client
+	stats  metrics.Counters
+}
+
+// NewCloudEventsEmitter creates an emitter that sends events to target in the
+// given mode (binary or structured; a client with a 30 second timeout is used
+// when client is nil)
+func NewCloudEventsEmitter(target string, mode CloudEventsMode, client *http.Client) *CloudEventsEmitter {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &CloudEventsEmitter{
+		id:     fmt.Sprintf("http-cloudevents-%s", target),
+		target: target,
+		mode:   mode,
+		client: client,
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *CloudEventsEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *CloudEventsEmitter) Type() string {
+	return "http-cloudevents"
+}
+
+// Emit serializes the event as a CloudEvent and POSTs it to the target
+func (e *CloudEventsEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	ce, err := toCloudEvent(evt)
+	if err != nil {
+		return err
+	}
+
+	req, err := e.newRequest(ctx, ce)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send CloudEvent %s: %w", ce.ID, err)
+	}
+	defer resp.Body.Close()
+	e.stats.Sent(int(req.ContentLength))
+	n, _ := io.Copy(io.Discard, resp.Body)
+	e.stats.Received(int(n))
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("CloudEvent %s rejected: %s", ce.ID, resp.Status)
+	}
+	return nil
+}
+
+// newRequest builds the HTTP request for a CloudEvent in the emitter's mode
+func (e *CloudEventsEmitter) newRequest(ctx context.Context, ce CloudEventPayload) (*http.Request, error) {
+	switch e.mode {
+	case CloudEventsBinary:
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.target, bytes.NewReader(ce.Data))
+		if err != nil {
+			return nil, err
+		}
+		setHeader := func(name, value string) {
+			if value != "" {
+				req.Header.Set(cloudEventsHeaderPrefix+name, url.PathEscape(value))
+			}
+		}
+		setHeader("Id", ce.ID)
+		setHeader("Source", ce.Source)
+		setHeader("Specversion", ce.SpecVersion)
+		setHeader("Type", ce.Type)
+		setHeader("Dataschema", ce.DataSchema)
+		setHeader("Subject", ce.Subject)
+		if !ce.Time.IsZero() {
+			setHeader("Time", ce.Time.Format(time.RFC3339Nano))
+		}
+		for name, value := range ce.Extensions {
+			setHeader(name, value)
+		}
+		if ce.DataContentType != "" {
+			req.Header.Set("Content-Type", ce.DataContentType)
+		}
+		return req, nil
+
+	case CloudEventsStructured:
+		body, err := marshalStructured(ce)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode CloudEvent: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", cloudEventsStructured+"; charset=utf-8")
+		return req, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported CloudEvents mode %s", e.mode)
+	}
+}
+
+// Metrics returns the emitter's traffic counters (body bytes and failed sends)
+func (e *CloudEventsEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "http", time.Now())
+}
+
+// Close releases idle connections
+func (e *CloudEventsEmitter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
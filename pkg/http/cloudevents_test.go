@@ -0,0 +1,247 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+func TestParseStructuredCloudEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantData string
+		wantErr  bool
+	}{
+		{
+			name:     "json data",
+			body:     `{"specversion":"1.0","id":"1","source":"/s","type":"t","data":{"a":1}}`,
+			wantData: `{"a":1}`,
+		},
+		{
+			name:     "base64 data",
+			body:     `{"specversion":"1.0","id":"1","source":"/s","type":"t","datacontenttype":"application/octet-stream","data_base64":"aGVsbG8="}`,
+			wantData: "hello",
+		},
+		{
+			name:     "text data",
+			body:     `{"specversion":"1.0","id":"1","source":"/s","type":"t","datacontenttype":"text/plain","data":"hello"}`,
+			wantData: "hello",
+		},
+		{name: "missing id", body: `{"specversion":"1.0","source":"/s","type":"t"}`, wantErr: true},
+		{name: "wrong specversion", body: `{"specversion":"0.3","id":"1","source":"/s","type":"t"}`, wantErr: true},
+		{name: "bad time", body: `{"specversion":"1.0","id":"1","source":"/s","type":"t","time":"yesterday"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ce, err := parseStructuredCloudEvent([]byte(tt.body))
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(ce.Data) != tt.wantData {
+				t.Errorf("Expected data %q, got %q", tt.wantData, ce.Data)
+			}
+		})
+	}
+}
+
+func TestHTTPServerAdapter_CloudEvents(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(NewServerAdapter(":18084", WithCloudEvents())); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"com.example.order.created"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	receive := func(t *testing.T) (*event.Event, CloudEventPayload) {
+		t.Helper()
+		select {
+		case evt := <-sub.Events():
+			var ce CloudEventPayload
+			if err := codec.Decode(evt, &ce); err != nil {
+				t.Fatalf("Failed to decode CloudEvent: %v", err)
+			}
+			return evt, ce
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for CloudEvent")
+			return nil, CloudEventPayload{}
+		}
+	}
+
+	t.Run("binary mode", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost:18084/events", strings.NewReader(`{"order":42}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Ce-Specversion", "1.0")
+		req.Header.Set("Ce-Id", "order-42")
+		req.Header.Set("Ce-Source", "/orders")
+		req.Header.Set("Ce-Type", "com.example.order.created")
+		req.Header.Set("Ce-Time", "2024-01-02T03:04:05Z")
+		req.Header.Set("Ce-Tenant", "acme%20corp")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d", resp.StatusCode)
+		}
+
+		evt, ce := receive(t)
+		if evt.ID != "order-42" || evt.Source != "/orders" {
+			t.Errorf("Expected event id/source from CloudEvent, got %s/%s", evt.ID, evt.Source)
+		}
+		if evt.Metadata["ce_mode"] != "binary" {
+			t.Errorf("Expected ce_mode binary, got %q", evt.Metadata["ce_mode"])
+		}
+		if string(ce.Data) != `{"order":42}` {
+			t.Errorf("Expected data to be the body, got %q", ce.Data)
+		}
+		if ce.Extensions["tenant"] != "acme corp" {
+			t.Errorf("Expected decoded tenant extension, got %q", ce.Extensions["tenant"])
+		}
+		if !ce.Time.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+			t.Errorf("Unexpected time %v", ce.Time)
+		}
+	})
+
+	t.Run("batch mode", func(t *testing.T) {
+		batch := `[
+			{"specversion":"1.0","id":"a","source":"/orders","type":"com.example.order.created"},
+			{"specversion":"1.0","id":"b","source":"/orders","type":"com.example.order.created"}
+		]`
+		resp, err := http.Post("http://localhost:18084/events", cloudEventsBatch, strings.NewReader(batch))
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d", resp.StatusCode)
+		}
+
+		first, _ := receive(t)
+		second, _ := receive(t)
+		if first.ID != "a" || second.ID != "b" {
+			t.Errorf("Expected events a and b in order, got %s and %s", first.ID, second.ID)
+		}
+		if first.Metadata["request_id"] != second.Metadata["request_id"] {
+			t.Error("Expected batch events to share a request ID")
+		}
+	})
+
+	t.Run("invalid event", func(t *testing.T) {
+		resp, err := http.Post("http://localhost:18084/events", cloudEventsStructured, strings.NewReader(`{"specversion":"1.0"}`))
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestCloudEventsEmitter(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header, body: body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	evt, err := event.NewEvent("com.example.order.shipped", "/warehouse", map[string]int{"order": 42}, event.JSONCodec{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	evt.WithMetadata("trace_id", "abc")
+
+	t.Run("binary", func(t *testing.T) {
+		emitter := NewCloudEventsEmitter(srv.URL, CloudEventsBinary, nil)
+		if err := emitter.Emit(context.Background(), evt); err != nil {
+			t.Fatalf("Emit failed: %v", err)
+		}
+
+		r := <-got
+		if r.header.Get("Ce-Id") != evt.ID || r.header.Get("Ce-Type") != evt.Type {
+			t.Errorf("Unexpected ce headers: %v", r.header)
+		}
+		if r.header.Get("Ce-Traceid") != "abc" {
+			t.Errorf("Expected metadata extension header, got %q", r.header.Get("Ce-Traceid"))
+		}
+		if r.header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected application/json, got %q", r.header.Get("Content-Type"))
+		}
+		if !bytes.Equal(r.body, evt.Data) {
+			t.Errorf("Expected body to be event data, got %q", r.body)
+		}
+	})
+
+	t.Run("structured", func(t *testing.T) {
+		emitter := NewCloudEventsEmitter(srv.URL, CloudEventsStructured, nil)
+		if err := emitter.Emit(context.Background(), evt); err != nil {
+			t.Fatalf("Emit failed: %v", err)
+		}
+
+		r := <-got
+		ce, err := parseStructuredCloudEvent(r.body)
+		if err != nil {
+			t.Fatalf("Emitter sent invalid structured event: %v", err)
+		}
+		if ce.ID != evt.ID || ce.Source != evt.Source {
+			t.Errorf("Expected id/source from event, got %s/%s", ce.ID, ce.Source)
+		}
+		var data map[string]int
+		if err := json.Unmarshal(ce.Data, &data); err != nil || data["order"] != 42 {
+			t.Errorf("Expected JSON data to be inlined, got %q", ce.Data)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer failing.Close()
+
+		emitter := NewCloudEventsEmitter(failing.URL, CloudEventsBinary, nil)
+		if err := emitter.Emit(context.Background(), evt); err == nil {
+			t.Error("Expected error for rejected event, got nil")
+		}
+	})
+}
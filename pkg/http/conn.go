@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/google/uuid"
+)
+
+// trackedConn counts the traffic and requests of an accepted connection
+// and adds them to the adapter's totals
+type trackedConn struct {
+	net.Conn
+	id        string
+	stats     *metrics.Counters
+	bytesIn   atomic.Uint64
+	bytesOut  atomic.Uint64
+	requests  atomic.Uint64
+	closeOnce sync.Once
+}
+
+func (c *trackedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.bytesIn.Add(uint64(n))
+	c.stats.Received(n)
+	return n, err
+}
+
+func (c *trackedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.bytesOut.Add(uint64(n))
+	c.stats.Sent(n)
+	return n, err
+}
+
+func (c *trackedConn) Close() error {
+	c.closeOnce.Do(c.stats.Closed)
+	return c.Conn.Close()
+}
+
+// trackingListener gives every accepted connection an ID and counters
+type trackingListener struct {
+	net.Listener
+	stats *metrics.Counters
+}
+
+func (l trackingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.stats.Opened()
+	return &trackedConn{Conn: c, id: uuid.New().String(), stats: l.stats}, nil
+}
+
+// connKey is the request context key for the request's trackedConn
+type connKey struct{}
+
+// connContext records the connection in the context of its requests
+func connContext(ctx context.Context, c net.Conn) context.Context {
+	if tc := unwrapConn(c); tc != nil {
+		return context.WithValue(ctx, connKey{}, tc)
+	}
+	return ctx
+}
+
+// requestConn returns the connection a request arrived on, if tracked
+func requestConn(r *http.Request) *trackedConn {
+	tc, _ := r.Context().Value(connKey{}).(*trackedConn)
+	return tc
+}
+
+// unwrapConn finds the trackedConn under a (possibly TLS) connection
+func unwrapConn(c net.Conn) *trackedConn {
+	if tlsConn, ok := c.(*tls.Conn); ok {
+		c = tlsConn.NetConn()
+	}
+	tc, _ := c.(*trackedConn)
+	return tc
+}
+
+// connectionEventTypes maps the connection states that are published to
+// their event types. Active is left out: the request events cover it.
+var connectionEventTypes = map[http.ConnState]string{
+	http.StateNew:      "net.connection.open",
+	http.StateIdle:     "net.connection.idle",
+	http.StateHijacked: "net.connection.hijacked",
+	http.StateClosed:   "net.connection.close",
+}
+
+// connState publishes connection lifecycle events (used as http.Server.ConnState)
+func (a *ServerAdapter) connState(ctx context.Context) func(net.Conn, http.ConnState) {
+	return func(c net.Conn, state http.ConnState) {
+		eventType, ok := connectionEventTypes[state]
+		tc := unwrapConn(c)
+		if !ok || tc == nil {
+			return
+		}
+		_, isTLS := c.(*tls.Conn)
+
+		payload := ConnectionPayload{
+			ConnectionID: tc.id,
+			State:        state.String(),
+			Protocol:     "http",
+			RemoteAddr:   tc.RemoteAddr().String(),
+			LocalAddr:    tc.LocalAddr().String(),
+			TLS:          isTLS,
+			BytesIn:      tc.bytesIn.Load(),
+			BytesOut:     tc.bytesOut.Load(),
+			Requests:     tc.requests.Load(),
+			Timestamp:    a.clk.Wall(),
+		}
+		evt, err := codec.NewEvent(eventType, a.id, payload, a.opts.codec)
+		if err != nil {
+			return
+		}
+		evt.WithMetadata("adapter_id", a.id).
+			WithMetadata("connection_id", tc.id)
+
+		a.bus.Publish(ctx, evt)
+	}
+}
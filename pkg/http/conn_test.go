@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+func TestHTTPServerAdapter_ConnectionEvents(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.http.request", "net.connection.open", "net.connection.idle", "net.connection.close"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(NewServerAdapter(":18087", WithConnectionEvents())); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	next := func(eventType string) *event.Event {
+		t.Helper()
+		for {
+			select {
+			case evt := <-sub.Events():
+				if evt.Type == eventType {
+					return evt
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Timed out waiting for %s", eventType)
+				return nil
+			}
+		}
+	}
+
+	// Two requests on one kept-alive connection
+	client := &http.Client{Transport: &http.Transport{}}
+	for i := 0; i < 2; i++ {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			resp, err := client.Get("http://localhost:18087/ping")
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+
+		if i == 0 {
+			var open ConnectionPayload
+			if err := codec.Decode(next("net.connection.open"), &open); err != nil || open.ConnectionID == "" || open.State != "new" {
+				t.Fatalf("Unexpected open event %+v (%v)", open, err)
+			}
+		}
+
+		evt := next("net.http.request")
+		var req HTTPRequestPayload
+		if err := codec.Decode(evt, &req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if req.ConnectionID == "" || evt.Metadata["connection_id"] != req.ConnectionID {
+			t.Errorf("Expected connection ID on request event, got %q / %v", req.ConnectionID, evt.Metadata)
+		}
+		rw, ok := GetResponseWriter(req.RequestID)
+		if !ok {
+			t.Fatal("No response writer for request")
+		}
+		rw.WriteResponse(http.StatusOK, nil, []byte("pong"))
+		<-done
+
+		var idle ConnectionPayload
+		if err := codec.Decode(next("net.connection.idle"), &idle); err != nil {
+			t.Fatalf("Failed to decode idle event: %v", err)
+		}
+		if idle.ConnectionID != req.ConnectionID || idle.Requests != uint64(i+1) || idle.BytesIn == 0 || idle.BytesOut == 0 {
+			t.Errorf("Unexpected idle event %+v", idle)
+		}
+	}
+
+	client.CloseIdleConnections()
+	var closed ConnectionPayload
+	if err := codec.Decode(next("net.connection.close"), &closed); err != nil || closed.State != "closed" || closed.Requests != 2 {
+		t.Errorf("Unexpected close event %+v (%v)", closed, err)
+	}
+}
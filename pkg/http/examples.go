@@ -3,8 +3,8 @@ package http
 import (
 	"fmt"
 	"net/http"
-	"time"
 
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
 	"github.com/BYTE-6D65/pipeline/pkg/event"
 )
 
@@ -12,17 +12,14 @@ import (
 // This is an example helper function for demonstration purposes.
 // It creates a text/plain response containing the request details.
 func CreateEchoResponse(requestEvt *event.Event) (*event.Event, error) {
-	// Decode request payload
-	codec := event.JSONCodec{}
+	// Decode request payload with the codec named in its metadata
 	var payload HTTPRequestPayload
-	if err := requestEvt.DecodePayload(&payload, codec); err != nil {
-		// Create error response
-		errorResponse := HTTPResponsePayload{
-			StatusCode: http.StatusInternalServerError,
-			Body:       []byte("Invalid request payload"),
-			Timestamp:  time.Now(),
-		}
-		return event.NewEvent("net.http.response", "http-echo", errorResponse, codec)
+	if err := codec.Decode(requestEvt, &payload); err != nil {
+		// Create error response (correlated via request_id metadata)
+		return Reply(requestEvt).
+			From("http-echo").
+			Status(http.StatusInternalServerError).
+			Text("Invalid request payload")
 	}
 
 	// Echo back request info
@@ -34,21 +31,5 @@ func CreateEchoResponse(requestEvt *event.Event) (*event.Event, error) {
 		string(payload.Body),
 	)
 
-	response := HTTPResponsePayload{
-		RequestID:  payload.RequestID,
-		StatusCode: http.StatusOK,
-		Headers: map[string]string{
-			"Content-Type": "text/plain",
-		},
-		Body:      []byte(echoBody),
-		Timestamp: time.Now(),
-	}
-
-	evt, err := event.NewEvent("net.http.response", "http-echo", response, codec)
-	if err != nil {
-		return nil, err
-	}
-
-	evt.WithMetadata("request_id", payload.RequestID)
-	return evt, nil
+	return Reply(requestEvt).From("http-echo").Text(echoBody)
 }
@@ -0,0 +1,22 @@
+package http
+
+import "net/http"
+
+// GatewayHandler serves requests in another protocol carried over plain
+// HTTP (e.g. grpc.ServerAdapter for gRPC-Web and transcoded REST calls).
+// Match is asked first, after authentication; requests it claims are handed
+// to ServeGateway with the same metadata an UpgradeHandler receives.
+type GatewayHandler interface {
+	Match(r *http.Request) bool
+	ServeGateway(w http.ResponseWriter, r *http.Request, metadata map[string]string)
+}
+
+// gatewayHandler returns the first gateway that claims r
+func (a *ServerAdapter) gatewayHandler(r *http.Request) (GatewayHandler, bool) {
+	for _, h := range a.opts.gateways {
+		if h.Match(r) {
+			return h, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+)
+
+// fakeGateway claims requests under /rpc/ and records the metadata it is handed
+type fakeGateway struct {
+	metadata chan map[string]string
+}
+
+func (f *fakeGateway) Match(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/rpc/")
+}
+
+func (f *fakeGateway) ServeGateway(w http.ResponseWriter, r *http.Request, metadata map[string]string) {
+	f.metadata <- metadata
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestHTTPServerAdapter_Gateway(t *testing.T) {
+	gateway := &fakeGateway{metadata: make(chan map[string]string, 1)}
+
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(NewServerAdapter(":18086", WithGateway(gateway))); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:18086/rpc/echo", strings.NewReader("{}"))
+	req.Header.Set("X-Tenant", "acme")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected gateway response, got %d", resp.StatusCode)
+	}
+
+	select {
+	case md := <-gateway.metadata:
+		if md["request_id"] == "" || md["http_adapter_id"] != "http-server-:18086" || md["header.X-Tenant"] != "acme" {
+			t.Errorf("Unexpected gateway metadata %v", md)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Gateway was not called")
+	}
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/intercept"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// intercept runs the interceptor chain on a draft event. It returns false,
+// having answered the request, when an interceptor replied or refused it.
+func (a *ServerAdapter) intercept(ctx context.Context, w http.ResponseWriter, payload any, evt *event.Event) bool {
+	if len(a.opts.interceptors) == 0 {
+		return true
+	}
+
+	reply, err := a.opts.interceptors.Run(ctx, &intercept.Call{
+		Protocol:  "http",
+		AdapterID: a.id,
+		Payload:   payload,
+		Event:     evt,
+	})
+	var rejection *intercept.Rejection
+	switch {
+	case errors.As(err, &rejection):
+		writeReply(w, rejection.Reply, http.StatusForbidden)
+	case err != nil:
+		a.stats.Error()
+		http.Error(w, "Failed to process request", http.StatusInternalServerError)
+	case reply != nil:
+		writeReply(w, *reply, http.StatusOK)
+	default:
+		return true
+	}
+	return false
+}
+
+// writeReply writes an interceptor reply, with status if its Code is not set
+func writeReply(w http.ResponseWriter, reply intercept.Reply, status int) {
+	for key, value := range reply.Headers {
+		w.Header().Set(key, value)
+	}
+	if reply.Code != 0 {
+		status = reply.Code
+	}
+	w.WriteHeader(status)
+	w.Write(reply.Body)
+}
+
+// ResponseHeaders returns an emitter interceptor that adds headers to
+// "net.http.response" events. Headers the response already sets are kept.
+// Other events pass through unchanged.
+func ResponseHeaders(headers map[string]string) intercept.EmitInterceptor {
+	return rewriteResponses(func(payload *HTTPResponsePayload) bool {
+		if payload.Headers == nil {
+			payload.Headers = make(map[string]string, len(headers))
+		}
+		for key, value := range headers {
+			if _, ok := payload.Headers[key]; !ok {
+				payload.Headers[key] = value
+			}
+		}
+		return true
+	})
+}
+
+// CompressResponses returns an emitter interceptor that gzips the Body of
+// "net.http.response" events of at least minSize bytes and sets
+// Content-Encoding. Responses are left alone when they already have a
+// Content-Encoding, carry their body in a BodyRef, would not shrink, or
+// answer a request that did not accept gzip. Other events pass through
+// unchanged.
+func CompressResponses(minSize int) intercept.EmitInterceptor {
+	return rewriteResponses(func(payload *HTTPResponsePayload) bool {
+		if payload.BodyRef != nil || len(payload.Body) == 0 || len(payload.Body) < minSize {
+			return false
+		}
+		if _, ok := headerKey(payload.Headers, "Content-Encoding"); ok {
+			return false
+		}
+		if rw, ok := GetResponseWriter(payload.RequestID); !ok || !acceptsGzip(rw.acceptEncoding) {
+			return false
+		}
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(payload.Body)
+		if err := zw.Close(); err != nil || buf.Len() >= len(payload.Body) {
+			return false
+		}
+
+		if payload.Headers == nil {
+			payload.Headers = make(map[string]string)
+		}
+		if key, ok := headerKey(payload.Headers, "Content-Length"); ok {
+			delete(payload.Headers, key)
+		}
+		payload.Headers["Content-Encoding"] = "gzip"
+		if _, ok := headerKey(payload.Headers, "Vary"); !ok {
+			payload.Headers["Vary"] = "Accept-Encoding"
+		}
+		payload.Body = buf.Bytes()
+		return true
+	})
+}
+
+// rewriteResponses returns an emitter interceptor that lets rewrite change
+// the payload of "net.http.response" events. The event is re-encoded only
+// when rewrite reports a change.
+func rewriteResponses(rewrite func(payload *HTTPResponsePayload) bool) intercept.EmitInterceptor {
+	return intercept.EmitInterceptorFunc(func(ctx context.Context, evt *event.Event, next intercept.EmitFunc) error {
+		if evt.Type != "net.http.response" {
+			return next(ctx, evt)
+		}
+
+		c, err := codec.ForEvent(evt, nil)
+		if err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		var payload HTTPResponsePayload
+		if err := evt.DecodePayload(&payload, c); err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		if !rewrite(&payload) {
+			return next(ctx, evt)
+		}
+		if evt.Data, err = c.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to encode payload: %w", err)
+		}
+		return next(ctx, evt)
+	})
+}
+
+// headerKey finds name in headers regardless of case and returns the key used
+func headerKey(headers map[string]string, name string) (string, bool) {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip, either
+// by name or through "*"
+func acceptsGzip(acceptEncoding string) bool {
+	allowed := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		allowed[coding] = true
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				allowed[coding] = false
+			}
+		}
+	}
+	if ok, named := allowed["gzip"]; named {
+		return ok
+	}
+	return allowed["*"]
+}
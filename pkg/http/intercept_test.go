@@ -0,0 +1,239 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/correlate"
+	"github.com/BYTE-6D65/netadapters/pkg/intercept"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+func TestServerAdapter_Interceptors(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	// Enrich every request, refuse /limited, answer /cached directly and fail /broken
+	tenant := intercept.Func(func(ctx context.Context, call *intercept.Call) (*intercept.Reply, error) {
+		req := call.Payload.(*HTTPRequestPayload)
+		call.Event.WithMetadata("tenant", req.Headers["X-Tenant"])
+		req.Headers["X-Tenant"] = "checked"
+		return nil, nil
+	})
+	gate := intercept.Func(func(ctx context.Context, call *intercept.Call) (*intercept.Reply, error) {
+		switch call.Payload.(*HTTPRequestPayload).Path {
+		case "/limited":
+			return nil, intercept.Reject(http.StatusTooManyRequests, "slow down")
+		case "/cached":
+			return &intercept.Reply{Headers: map[string]string{"X-Cache": "hit"}, Body: []byte("from cache")}, nil
+		case "/broken":
+			return nil, errors.New("policy store unavailable")
+		}
+		return nil, nil
+	})
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	adapter := NewServerAdapter(":18100", WithInterceptors(tenant, gate))
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	emitterMgr := engine.NewEmitterManager(eng)
+	if err := emitterMgr.Register("http-client", NewClientEmitter(), event.Filter{
+		Types: []string{"net.http.response"},
+	}); err != nil {
+		t.Fatalf("Failed to register emitter: %v", err)
+	}
+	if err := emitterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start emitters: %v", err)
+	}
+	defer emitterMgr.Stop()
+
+	observer, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.http.request"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer observer.Close()
+
+	router := NewRouter(eng.ExternalBus())
+	router.Handle("/ok", func(ctx context.Context, req *Request) (*Response, error) {
+		return &Response{Body: []byte(req.Headers["X-Tenant"])}, nil
+	})
+	if err := router.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start router: %v", err)
+	}
+	defer router.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+		header string // Expected X-Cache
+	}{
+		{"/ok", http.StatusOK, "checked", ""},
+		{"/limited", http.StatusTooManyRequests, "slow down", ""},
+		{"/cached", http.StatusOK, "from cache", "hit"},
+		{"/broken", http.StatusInternalServerError, "Failed to process request\n", ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:18100"+tt.path, nil)
+		req.Header.Set("X-Tenant", "acme")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tt.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.status || string(body) != tt.body || resp.Header.Get("X-Cache") != tt.header {
+			t.Errorf("%s: expected %d %q (X-Cache %q), got %d %q (X-Cache %q)", tt.path,
+				tt.status, tt.body, tt.header, resp.StatusCode, body, resp.Header.Get("X-Cache"))
+		}
+	}
+
+	// Only the request that passed every interceptor was published
+	select {
+	case evt := <-observer.Events():
+		if evt.Metadata["tenant"] != "acme" {
+			t.Errorf("Expected tenant metadata, got %v", evt.Metadata)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for request event")
+	}
+	select {
+	case evt := <-observer.Events():
+		t.Errorf("Expected intercepted requests not to be published, got %v", evt.Metadata)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if got := adapter.Metrics().ErrorCount; got != 1 {
+		t.Errorf("Expected only the failed interceptor to count as an error, got %d", got)
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	var delivered *event.Event
+	emitter := intercept.Wrap(NewClientEmitter(), intercept.With(
+		ResponseHeaders(map[string]string{"X-Frame-Options": "DENY", "Content-Type": "text/plain"}),
+		intercept.EmitInterceptorFunc(func(ctx context.Context, evt *event.Event, next intercept.EmitFunc) error {
+			delivered = evt
+			return nil // Stop before the ClientEmitter, which has no request to answer
+		}),
+	))
+
+	evt, err := Reply(&event.Event{Metadata: map[string]string{"request_id": "req-1"}}).JSON(map[string]string{"ok": "yes"})
+	if err != nil {
+		t.Fatalf("Failed to build response: %v", err)
+	}
+	if err := emitter.Emit(context.Background(), evt); err != nil {
+		t.Fatalf("Emit failed: %v", err)
+	}
+
+	var payload HTTPResponsePayload
+	if err := codec.Decode(delivered, &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.Headers["X-Frame-Options"] != "DENY" {
+		t.Errorf("Expected the injected header, got %v", payload.Headers)
+	}
+	if payload.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Expected the response's own Content-Type to be kept, got %q", payload.Headers["Content-Type"])
+	}
+}
+
+func TestCompressResponses(t *testing.T) {
+	// Requests waiting on a running server, as the emitter would find them
+	adapter := NewServerAdapter("")
+	adapter.responses.Store(correlate.NewTable[*responseWriter]())
+	servers.Store(adapter, struct{}{})
+	defer servers.Delete(adapter)
+	for id, accept := range map[string]string{"gzip": "br, gzip", "identity": "", "refused": "gzip;q=0, *"} {
+		if _, err := adapter.responses.Load().Register(id, &responseWriter{requestID: id, acceptEncoding: accept}, 0); err != nil {
+			t.Fatalf("Failed to register request: %v", err)
+		}
+	}
+
+	var delivered *event.Event
+	emitter := intercept.Wrap(NewClientEmitter(), intercept.With(
+		CompressResponses(64),
+		intercept.EmitInterceptorFunc(func(ctx context.Context, evt *event.Event, next intercept.EmitFunc) error {
+			delivered = evt
+			return nil // Stop before the ClientEmitter, which has no request to answer
+		}),
+	))
+
+	large := []byte(strings.Repeat("compressible ", 32))
+	tests := []struct {
+		name       string
+		requestID  string
+		body       []byte
+		headers    map[string]string
+		compressed bool
+	}{
+		{name: "large body", requestID: "gzip", body: large, headers: map[string]string{"Content-Length": "416"}, compressed: true},
+		{name: "below threshold", requestID: "gzip", body: []byte("short")},
+		{name: "already encoded", requestID: "gzip", body: large, headers: map[string]string{"content-encoding": "br"}},
+		{name: "gzip not accepted", requestID: "identity", body: large},
+		{name: "gzip refused", requestID: "refused", body: large},
+		{name: "unknown request", requestID: "missing", body: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt, err := codec.NewEvent("net.http.response", "test", HTTPResponsePayload{
+				RequestID:  tt.requestID,
+				StatusCode: http.StatusOK,
+				Headers:    tt.headers,
+				Body:       tt.body,
+			}, codec.JSON{})
+			if err != nil {
+				t.Fatalf("Failed to create event: %v", err)
+			}
+			if err := emitter.Emit(context.Background(), evt); err != nil {
+				t.Fatalf("Emit failed: %v", err)
+			}
+
+			var payload HTTPResponsePayload
+			if err := codec.Decode(delivered, &payload); err != nil {
+				t.Fatalf("Failed to decode payload: %v", err)
+			}
+			if !tt.compressed {
+				if !bytes.Equal(payload.Body, tt.body) || payload.Headers["Content-Encoding"] != "" {
+					t.Errorf("Expected the response unchanged, got %v", payload.Headers)
+				}
+				return
+			}
+
+			if payload.Headers["Content-Encoding"] != "gzip" || payload.Headers["Vary"] != "Accept-Encoding" {
+				t.Errorf("Expected gzip headers, got %v", payload.Headers)
+			}
+			if _, ok := payload.Headers["Content-Length"]; ok {
+				t.Error("Expected the stale Content-Length to be removed")
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(payload.Body))
+			if err != nil {
+				t.Fatalf("Expected a gzip body: %v", err)
+			}
+			if body, _ := io.ReadAll(zr); !bytes.Equal(body, tt.body) {
+				t.Errorf("Expected the body to round-trip, got %q", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/BYTE-6D65/netadapters/pkg/blobstore"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/intercept"
+)
+
+// Option configures a ServerAdapter or an emitter in this package
+type Option func(*options)
+
+// options holds the settings shared by adapters and emitters
+type options struct {
+	codec codec.Codec
+
+	// Claim check
+	blobs          blobstore.Store
+	claimThreshold int64
+
+	// CloudEvents HTTP binding
+	cloudEvents bool
+
+	// Connection lifecycle events
+	connectionEvents bool
+
+	// Stage breakdown in a Server-Timing response header
+	serverTiming   bool
+	responseEvents bool
+
+	// Authentication, interception, protocol upgrades and gateways
+	auth         Authenticator
+	interceptors intercept.Chain
+	upgrades     []upgradeRoute
+	gateways     []GatewayHandler
+
+	// Logging
+	logger            *slog.Logger
+	accessLogFormat   AccessLogFormat // 0 disables the access log
+	accessLogSampling float64
+	redactedHeaders   map[string]bool // Canonical header names
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		codec:             codec.JSON{},
+		logger:            slog.Default(),
+		accessLogSampling: 1,
+		redactedHeaders:   make(map[string]bool),
+	}
+	for _, name := range defaultRedactedHeaders {
+		o.redactedHeaders[name] = true
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCodec sets the payload codec.
+// The ServerAdapter encodes request events with it; emitters use it to decode
+// events that carry no "codec" metadata.
+func WithCodec(c codec.Codec) Option {
+	return func(o *options) {
+		if c != nil {
+			o.codec = c
+		}
+	}
+}
+
+// WithBlobStore sets the store emitters use to resolve claim-checked bodies
+func WithBlobStore(store blobstore.Store) Option {
+	return func(o *options) {
+		o.blobs = store
+	}
+}
+
+// WithClaimCheck makes the ServerAdapter offload request bodies larger than
+// threshold bytes to store, publishing a BodyRef instead of the body.
+// It also sets the store used by emitters, like WithBlobStore.
+func WithClaimCheck(store blobstore.Store, threshold int64) Option {
+	return func(o *options) {
+		o.blobs = store
+		o.claimThreshold = threshold
+	}
+}
+
+// WithCloudEvents makes the ServerAdapter accept CloudEvents in binary,
+// structured and batch mode. Each CloudEvent is published as an event with the
+// CloudEvent's type, source and id, and the request is answered with
+// 202 Accepted. Other requests are handled as usual.
+func WithCloudEvents() Option {
+	return func(o *options) {
+		o.cloudEvents = true
+	}
+}
+
+// WithConnectionEvents makes the ServerAdapter publish connection lifecycle
+// events: "net.connection.open", "net.connection.idle" (after each request on
+// a kept-alive connection), "net.connection.hijacked" (handed over, e.g. for a
+// WebSocket) and "net.connection.close", each with a ConnectionPayload.
+// Request events carry the connection ID either way.
+func WithConnectionEvents() Option {
+	return func(o *options) {
+		o.connectionEvents = true
+	}
+}
+
+// WithServerTiming makes the ServerAdapter add a Server-Timing header to
+// responses from the pipeline, with the time spent reading the request
+// ("read"), encoding the event ("encode"), waiting for the response
+// ("pipeline", of which "publish" in bus Publish), in milliseconds.
+// The breakdown is recorded in the adapter's latency metrics either way.
+func WithServerTiming() Option {
+	return func(o *options) {
+		o.serverTiming = true
+	}
+}
+
+// WithResponseEvents makes the ServerAdapter publish a
+// "net.http.response.sent" event once each response from the pipeline is
+// written, or the request timed out. Its HTTPResponsePayload has the status,
+// headers and route but no body; DurationNs (accept to response written) and
+// the stage breakdown in Timing are measured on the adapter's clock.
+func WithResponseEvents() Option {
+	return func(o *options) {
+		o.responseEvents = true
+	}
+}
+
+// WithAuthenticator makes the ServerAdapter authenticate every request before
+// publishing it. Rejected requests get 401 Unauthorized; the principal of
+// accepted ones is recorded in event metadata as "principal".
+func WithAuthenticator(auth Authenticator) Option {
+	return func(o *options) {
+		o.auth = auth
+	}
+}
+
+// WithInterceptors adds interceptors that run, in order, on every request
+// event (and CloudEvent) before it is published. An interceptor's Reply is
+// written as the response (status 200 unless its Code is set); a Rejection
+// is written the same way with 403 Forbidden by default. Any other error
+// gets 500 Internal Server Error. The payload is an *HTTPRequestPayload, or
+// a *CloudEventPayload.
+func WithInterceptors(interceptors ...intercept.Interceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithUpgrade hands upgrade requests (e.g. WebSocket handshakes) on paths
+// matching pattern to handler, so they share the adapter's port. Patterns use
+// the Router syntax ("/ws", "/rooms/:room"). Other requests to the same path
+// are published as usual.
+func WithUpgrade(pattern string, handler UpgradeHandler) Option {
+	return func(o *options) {
+		o.upgrades = append(o.upgrades, upgradeRoute{pattern: pattern, handler: handler})
+	}
+}
+
+// WithGateway hands requests claimed by handler's Match to handler instead of
+// publishing them. Gateways are asked in the order they were added, after
+// upgrade routes.
+func WithGateway(handler GatewayHandler) Option {
+	return func(o *options) {
+		if handler != nil {
+			o.gateways = append(o.gateways, handler)
+		}
+	}
+}
+
+// WithLogger sets the logger the ServerAdapter writes server errors and
+// access log records to (defaults to slog.Default())
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithAccessLog makes the ServerAdapter log every request at Info level in
+// format, with its request ID, route, status, bytes, principal and duration,
+// split into the time spent waiting on the pipeline ("bus_wait") and writing
+// the response ("write")
+func WithAccessLog(format AccessLogFormat) Option {
+	return func(o *options) {
+		o.accessLogFormat = format
+	}
+}
+
+// WithAccessLogSampling logs only a fraction (0 to 1) of requests.
+// Requests answered with a 5xx status are always logged.
+func WithAccessLogSampling(rate float64) Option {
+	return func(o *options) {
+		o.accessLogSampling = min(max(rate, 0), 1)
+	}
+}
+
+// WithRedactedHeaders replaces the values of the named request headers in
+// access logs. Authorization, Proxy-Authorization and Cookie are always
+// redacted.
+func WithRedactedHeaders(names ...string) Option {
+	return func(o *options) {
+		for _, name := range names {
+			o.redactedHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// OutboundEmitter sends HTTP requests described by "net.http.outbound" events.
+// Claim-checked bodies are streamed from the blob store set with WithBlobStore.
+type OutboundEmitter struct {
+	id     string
+	client *http.Client
+	opts   options
+	stats  metrics.Counters
+}
+
+// NewOutboundEmitter creates an emitter that sends requests with client
+// (a client with a 30 second timeout is used when client is nil)
+func NewOutboundEmitter(client *http.Client, opts ...Option) *OutboundEmitter {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &OutboundEmitter{
+		id:     "http-outbound-emitter",
+		client: client,
+		opts:   newOptions(opts),
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *OutboundEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *OutboundEmitter) Type() string {
+	return "http-outbound"
+}
+
+// Emit sends the HTTP request described by the event
+func (e *OutboundEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var payload HTTPOutboundPayload
+	if err := evt.DecodePayload(&payload, c); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if payload.URL == "" {
+		return fmt.Errorf("outbound request %s has no URL", payload.RequestID)
+	}
+
+	body, err := payload.OpenBody(ctx, e.opts.blobs)
+	if err != nil {
+		return fmt.Errorf("failed to resolve request body: %w", err)
+	}
+	defer body.Close()
+
+	method := payload.Method
+	if method == "" {
+		method = http.MethodGet
+		if len(payload.Body) > 0 || payload.BodyRef != nil {
+			method = http.MethodPost
+		}
+	}
+
+	size := int64(len(payload.Body))
+	if payload.BodyRef != nil {
+		size = payload.BodyRef.Size
+	}
+	var reqBody io.Reader = body
+	if size == 0 {
+		reqBody = http.NoBody
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, payload.URL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	for key, value := range payload.Headers {
+		req.Header.Set(key, value)
+	}
+	if payload.RequestID != "" && req.Header.Get("X-Request-ID") == "" {
+		req.Header.Set("X-Request-ID", payload.RequestID)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s failed: %w", payload.RequestID, err)
+	}
+	defer resp.Body.Close()
+	e.stats.Sent(int(size))
+	n, _ := io.Copy(io.Discard, resp.Body)
+	e.stats.Received(int(n))
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("request %s failed: %s", payload.RequestID, resp.Status)
+	}
+	return nil
+}
+
+// Metrics returns the emitter's traffic counters (body bytes and failed requests)
+func (e *OutboundEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "http", time.Now())
+}
+
+// Close releases idle connections
+func (e *OutboundEmitter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
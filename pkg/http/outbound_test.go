@@ -0,0 +1,200 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/blobstore"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+func TestOutboundEmitter_Metadata(t *testing.T) {
+	emitter := NewOutboundEmitter(nil)
+
+	if emitter.ID() != "http-outbound-emitter" {
+		t.Errorf("Expected ID 'http-outbound-emitter', got %s", emitter.ID())
+	}
+	if emitter.Type() != "http-outbound" {
+		t.Errorf("Expected Type 'http-outbound', got %s", emitter.Type())
+	}
+}
+
+func TestOutboundEmitter_ResolvesBodyRef(t *testing.T) {
+	store := blobstore.NewMemoryStore()
+	defer store.Close()
+
+	data := bytes.Repeat([]byte("x"), 4096)
+	ref, err := store.Put(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to put blob: %v", err)
+	}
+
+	received := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST, got %s", r.Method)
+		}
+		if r.Header.Get("X-Request-ID") != "req-out" {
+			t.Errorf("Expected X-Request-ID header, got %q", r.Header.Get("X-Request-ID"))
+		}
+		received <- body
+	}))
+	defer srv.Close()
+
+	evt, err := codec.NewEvent("net.http.outbound", "test", HTTPOutboundPayload{
+		RequestID: "req-out",
+		URL:       srv.URL + "/upload",
+		BodyRef:   &ref,
+	}, codec.CBOR{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	emitter := NewOutboundEmitter(nil, WithBlobStore(store))
+	if err := emitter.Emit(context.Background(), evt); err != nil {
+		t.Fatalf("Emit failed: %v", err)
+	}
+
+	if got := <-received; !bytes.Equal(got, data) {
+		t.Errorf("Expected resolved body of %d bytes, got %d", len(data), len(got))
+	}
+}
+
+func TestOutboundEmitter_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ref := blobstore.Ref{Key: "missing", Size: 10}
+	tests := []struct {
+		name    string
+		payload HTTPOutboundPayload
+	}{
+		{name: "no URL", payload: HTTPOutboundPayload{RequestID: "r1"}},
+		{name: "no blob store", payload: HTTPOutboundPayload{RequestID: "r2", URL: srv.URL, BodyRef: &ref}},
+		{name: "error status", payload: HTTPOutboundPayload{RequestID: "r3", URL: srv.URL}},
+	}
+
+	emitter := NewOutboundEmitter(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt, err := event.NewEvent("net.http.outbound", "test", tt.payload, event.JSONCodec{})
+			if err != nil {
+				t.Fatalf("Failed to create event: %v", err)
+			}
+			if err := emitter.Emit(context.Background(), evt); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestHTTPServerAdapter_ClaimCheck(t *testing.T) {
+	store := blobstore.NewMemoryStore()
+	defer store.Close()
+
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(NewServerAdapter(":18083", WithClaimCheck(store, 1024))); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	emitterMgr := engine.NewEmitterManager(eng)
+	if err := emitterMgr.Register("http-client", NewClientEmitter(WithBlobStore(store)), event.Filter{
+		Types: []string{"net.http.response"},
+	}); err != nil {
+		t.Fatalf("Failed to register emitter: %v", err)
+	}
+	if err := emitterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start emitters: %v", err)
+	}
+	defer emitterMgr.Stop()
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.http.request"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	// Echo the claim check back: the emitter must resolve it when writing
+	go func() {
+		for evt := range sub.Events() {
+			var payload HTTPRequestPayload
+			if err := codec.Decode(evt, &payload); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+				continue
+			}
+
+			mode := "inline"
+			if payload.BodyRef != nil {
+				mode = "ref"
+			}
+			resp := HTTPResponsePayload{
+				RequestID:  payload.RequestID,
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"X-Claim-Check": mode},
+				Body:       payload.Body,
+				BodyRef:    payload.BodyRef,
+				Timestamp:  time.Now(),
+			}
+			respEvt, err := codec.NewEvent("net.http.response", "test", resp, codec.JSON{})
+			if err != nil {
+				t.Errorf("Failed to create response: %v", err)
+				continue
+			}
+			eng.ExternalBus().Publish(context.Background(), respEvt)
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	t.Run("large body is offloaded", func(t *testing.T) {
+		data := bytes.Repeat([]byte("0123456789"), 1000)
+		resp, err := http.Post("http://localhost:18083/upload", "application/octet-stream", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Failed to send POST request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		got, _ := io.ReadAll(resp.Body)
+		if resp.Header.Get("X-Claim-Check") != "ref" {
+			t.Errorf("Expected body to be offloaded, got %q", resp.Header.Get("X-Claim-Check"))
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("Expected %d byte echo, got %d bytes", len(data), len(got))
+		}
+	})
+
+	t.Run("small body stays inline", func(t *testing.T) {
+		resp, err := http.Post("http://localhost:18083/upload", "text/plain", bytes.NewReader([]byte("small")))
+		if err != nil {
+			t.Fatalf("Failed to send POST request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		got, _ := io.ReadAll(resp.Body)
+		if resp.Header.Get("X-Claim-Check") != "inline" {
+			t.Errorf("Expected body to stay inline, got %q", resp.Header.Get("X-Claim-Check"))
+		}
+		if string(got) != "small" {
+			t.Errorf("Expected inline echo, got %q", got)
+		}
+	})
+}
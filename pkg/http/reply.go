@@ -0,0 +1,255 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// defaultReplySource is the event source used when none is set with From
+const defaultReplySource = "http-reply"
+
+// requestHead is the subset of HTTPRequestPayload needed to build a reply.
+// Decoding into it skips allocating the (possibly large) request body.
+type requestHead struct {
+	RequestID string    `json:"request_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ResponseBuilder builds a "net.http.response" event bound to a request event.
+// Correlation, content headers, DurationNs and the timestamp are filled in
+// automatically; a terminal method (JSON, Text, Bytes, Redirect, Problem or
+// NoContent) produces the event. The response is encoded with the request's
+// codec unless Codec is called.
+type ResponseBuilder struct {
+	requestID string
+	adapterID string
+	received  time.Time
+	clk       clock.Clock
+	source    string
+	codec     codec.Codec
+	status    int
+	route     string
+	headers   map[string]string
+	err       error
+}
+
+// Reply starts a response to the given request event.
+// If the request payload cannot be decoded, the request ID is taken from the
+// event's "request_id" metadata so the response can still be delivered.
+func Reply(requestEvt *event.Event) *ResponseBuilder {
+	b := &ResponseBuilder{
+		source:  defaultReplySource,
+		clk:     clock.System(),
+		codec:   codec.JSON{},
+		headers: make(map[string]string),
+	}
+
+	if requestEvt == nil {
+		b.err = fmt.Errorf("reply: request event is nil")
+		return b
+	}
+
+	b.adapterID = requestEvt.Metadata["adapter_id"]
+	b.clk = serverClock(b.adapterID)
+
+	c, err := codec.ForEvent(requestEvt, nil)
+	if err != nil {
+		c = codec.JSON{}
+	}
+	b.codec = c
+
+	var head requestHead
+	if err == nil && requestEvt.DecodePayload(&head, c) == nil {
+		b.requestID = head.RequestID
+		b.received = head.Timestamp
+	}
+	if b.requestID == "" {
+		b.requestID = requestEvt.Metadata["request_id"]
+	}
+	if b.requestID == "" {
+		b.err = fmt.Errorf("reply: request event %s has no request ID", requestEvt.ID)
+	}
+
+	return b
+}
+
+// From sets the source of the response event
+func (b *ResponseBuilder) From(source string) *ResponseBuilder {
+	b.source = source
+	return b
+}
+
+// Codec sets the codec used to encode the response event
+func (b *ResponseBuilder) Codec(c codec.Codec) *ResponseBuilder {
+	if c != nil {
+		b.codec = c
+	}
+	return b
+}
+
+// Status sets the HTTP status code (defaults depend on the terminal method)
+func (b *ResponseBuilder) Status(code int) *ResponseBuilder {
+	b.status = code
+	return b
+}
+
+// Route records the pattern that handled the request, for access logs
+func (b *ResponseBuilder) Route(pattern string) *ResponseBuilder {
+	b.route = pattern
+	return b
+}
+
+// Clock sets the clock that timestamps the response. It defaults to the clock
+// of the running adapter that received the request, so DurationNs is measured
+// on the same clock as the request timestamp, or the system clock if there is none.
+func (b *ResponseBuilder) Clock(clk clock.Clock) *ResponseBuilder {
+	b.clk = clk
+	return b
+}
+
+// Header sets a response header
+func (b *ResponseBuilder) Header(key, value string) *ResponseBuilder {
+	b.headers[key] = value
+	return b
+}
+
+// JSON encodes v as the response body with an application/json content type
+func (b *ResponseBuilder) JSON(v any) (*event.Event, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("reply: failed to encode JSON body: %w", err)
+	}
+	return b.Bytes("application/json", body)
+}
+
+// Text sets a plain-text response body
+func (b *ResponseBuilder) Text(s string) (*event.Event, error) {
+	return b.Bytes("text/plain; charset=utf-8", []byte(s))
+}
+
+// Bytes sets a raw response body with the given content type
+func (b *ResponseBuilder) Bytes(contentType string, body []byte) (*event.Event, error) {
+	if contentType != "" {
+		b.setDefaultHeader("Content-Type", contentType)
+	}
+	return b.build(http.StatusOK, body)
+}
+
+// Redirect responds with a Location header.
+// The status defaults to 302 Found unless a 3xx status was set.
+func (b *ResponseBuilder) Redirect(url string) (*event.Event, error) {
+	if b.status < 300 || b.status > 399 {
+		b.status = http.StatusFound
+	}
+	b.headers["Location"] = url
+	return b.build(http.StatusFound, nil)
+}
+
+// Problem responds with an RFC 7807 problem document.
+// The status is taken from the builder, then from p.Status, then defaults to 500.
+func (b *ResponseBuilder) Problem(p Problem) (*event.Event, error) {
+	if b.status == 0 {
+		b.status = p.Status
+	}
+	if b.status == 0 {
+		b.status = http.StatusInternalServerError
+	}
+	p.Status = b.status
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("reply: failed to encode problem: %w", err)
+	}
+	return b.Bytes("application/problem+json", body)
+}
+
+// NoContent responds with 204 No Content and an empty body
+func (b *ResponseBuilder) NoContent() (*event.Event, error) {
+	return b.build(http.StatusNoContent, nil)
+}
+
+// setDefaultHeader sets a header unless the caller already set it
+func (b *ResponseBuilder) setDefaultHeader(key, value string) {
+	if _, ok := b.headers[key]; !ok {
+		b.headers[key] = value
+	}
+}
+
+// build creates the response event
+func (b *ResponseBuilder) build(defaultStatus int, body []byte) (*event.Event, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	status := b.status
+	if status == 0 {
+		status = defaultStatus
+	}
+
+	now := b.clk.Wall()
+	response := HTTPResponsePayload{
+		RequestID:  b.requestID,
+		StatusCode: status,
+		Headers:    b.headers,
+		Body:       body,
+		Route:      b.route,
+		Timestamp:  now,
+	}
+	if !b.received.IsZero() {
+		response.DurationNs = now.Sub(b.received).Nanoseconds()
+	}
+
+	evt, err := codec.NewEvent("net.http.response", b.source, response, b.codec)
+	if err != nil {
+		return nil, err
+	}
+
+	evt.WithMetadata("request_id", b.requestID)
+	if b.adapterID != "" {
+		evt.WithMetadata("adapter_id", b.adapterID)
+	}
+	return evt, nil
+}
+
+// Problem is an RFC 7807 problem details document
+type Problem struct {
+	Type     string `json:"type,omitempty"`     // URI identifying the problem type
+	Title    string `json:"title,omitempty"`    // Short human-readable summary
+	Status   int    `json:"status,omitempty"`   // HTTP status code
+	Detail   string `json:"detail,omitempty"`   // Explanation specific to this occurrence
+	Instance string `json:"instance,omitempty"` // URI identifying this occurrence
+
+	// Extensions are additional members merged into the document
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON merges Extensions into the problem document
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	base, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return base, err
+	}
+
+	doc := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		doc[k] = v
+	}
+	var members map[string]any
+	if err := json.Unmarshal(base, &members); err != nil {
+		return nil, err
+	}
+	for k, v := range members {
+		doc[k] = v
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+func newTestRequestEvent(t *testing.T, requestID string) *event.Event {
+	t.Helper()
+
+	payload := HTTPRequestPayload{
+		RequestID: requestID,
+		Method:    "POST",
+		Path:      "/users",
+		Timestamp: time.Now().Add(-5 * time.Millisecond),
+	}
+	evt, err := event.NewEvent("net.http.request", "http-server-:8080", payload, event.JSONCodec{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	evt.WithMetadata("adapter_id", "http-server-:8080").
+		WithMetadata("request_id", requestID)
+	return evt
+}
+
+func decodeResponse(t *testing.T, evt *event.Event) HTTPResponsePayload {
+	t.Helper()
+
+	var payload HTTPResponsePayload
+	if err := evt.DecodePayload(&payload, event.JSONCodec{}); err != nil {
+		t.Fatalf("Failed to decode response payload: %v", err)
+	}
+	return payload
+}
+
+func TestReply_JSON(t *testing.T) {
+	reqEvt := newTestRequestEvent(t, "req-json")
+
+	evt, err := Reply(reqEvt).Status(http.StatusCreated).JSON(map[string]string{"id": "42"})
+	if err != nil {
+		t.Fatalf("Failed to build reply: %v", err)
+	}
+
+	if evt.Type != "net.http.response" {
+		t.Errorf("Expected type net.http.response, got %s", evt.Type)
+	}
+	if evt.Metadata["request_id"] != "req-json" {
+		t.Errorf("Expected request_id metadata req-json, got %s", evt.Metadata["request_id"])
+	}
+	if evt.Metadata["adapter_id"] != "http-server-:8080" {
+		t.Errorf("Expected adapter_id metadata to be copied, got %s", evt.Metadata["adapter_id"])
+	}
+
+	payload := decodeResponse(t, evt)
+	if payload.RequestID != "req-json" {
+		t.Errorf("Expected request ID req-json, got %s", payload.RequestID)
+	}
+	if payload.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", payload.StatusCode)
+	}
+	if payload.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Expected application/json, got %s", payload.Headers["Content-Type"])
+	}
+	if string(payload.Body) != `{"id":"42"}` {
+		t.Errorf("Unexpected body: %s", string(payload.Body))
+	}
+	if payload.DurationNs <= 0 {
+		t.Errorf("Expected DurationNs to be set, got %d", payload.DurationNs)
+	}
+	if payload.Timestamp.IsZero() {
+		t.Error("Expected Timestamp to be set")
+	}
+}
+
+func TestReply_TextDefaults(t *testing.T) {
+	evt, err := Reply(newTestRequestEvent(t, "req-text")).
+		From("users-service").
+		Header("Content-Type", "text/csv").
+		Text("a,b")
+	if err != nil {
+		t.Fatalf("Failed to build reply: %v", err)
+	}
+
+	if evt.Source != "users-service" {
+		t.Errorf("Expected source users-service, got %s", evt.Source)
+	}
+
+	payload := decodeResponse(t, evt)
+	if payload.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", payload.StatusCode)
+	}
+	if payload.Headers["Content-Type"] != "text/csv" {
+		t.Errorf("Expected explicit Content-Type to win, got %s", payload.Headers["Content-Type"])
+	}
+}
+
+func TestReply_Redirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		expected int
+	}{
+		{name: "default", status: 0, expected: http.StatusFound},
+		{name: "explicit 3xx", status: http.StatusMovedPermanently, expected: http.StatusMovedPermanently},
+		{name: "non-3xx ignored", status: http.StatusOK, expected: http.StatusFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt, err := Reply(newTestRequestEvent(t, "req-redirect")).Status(tt.status).Redirect("/login")
+			if err != nil {
+				t.Fatalf("Failed to build reply: %v", err)
+			}
+
+			payload := decodeResponse(t, evt)
+			if payload.StatusCode != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, payload.StatusCode)
+			}
+			if payload.Headers["Location"] != "/login" {
+				t.Errorf("Expected Location /login, got %s", payload.Headers["Location"])
+			}
+		})
+	}
+}
+
+func TestReply_Problem(t *testing.T) {
+	evt, err := Reply(newTestRequestEvent(t, "req-problem")).Problem(Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Status:     http.StatusForbidden,
+		Detail:     "Your balance is 30",
+		Extensions: map[string]any{"balance": 30},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build reply: %v", err)
+	}
+
+	payload := decodeResponse(t, evt)
+	if payload.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", payload.StatusCode)
+	}
+	if payload.Headers["Content-Type"] != "application/problem+json" {
+		t.Errorf("Expected application/problem+json, got %s", payload.Headers["Content-Type"])
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(payload.Body, &doc); err != nil {
+		t.Fatalf("Failed to decode problem document: %v", err)
+	}
+	if doc["title"] != "Forbidden" {
+		t.Errorf("Expected default title Forbidden, got %v", doc["title"])
+	}
+	if doc["balance"] != float64(30) {
+		t.Errorf("Expected extension member balance=30, got %v", doc["balance"])
+	}
+	if doc["status"] != float64(http.StatusForbidden) {
+		t.Errorf("Expected status member 403, got %v", doc["status"])
+	}
+}
+
+func TestReply_NoContent(t *testing.T) {
+	evt, err := Reply(newTestRequestEvent(t, "req-empty")).NoContent()
+	if err != nil {
+		t.Fatalf("Failed to build reply: %v", err)
+	}
+
+	payload := decodeResponse(t, evt)
+	if payload.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", payload.StatusCode)
+	}
+	if len(payload.Body) != 0 {
+		t.Errorf("Expected empty body, got %q", payload.Body)
+	}
+}
+
+func TestReply_UsesAdapterClock(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	adapter := NewServerAdapter(":18103")
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), clk); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	payload := HTTPRequestPayload{RequestID: "req-clock", Method: "GET", Path: "/", Timestamp: start}
+	reqEvt, err := event.NewEvent("net.http.request", adapter.ID(), payload, event.JSONCodec{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	reqEvt.WithMetadata("adapter_id", adapter.ID())
+
+	clk.Advance(30 * time.Millisecond)
+
+	evt, err := Reply(reqEvt).Text("ok")
+	if err != nil {
+		t.Fatalf("Failed to build reply: %v", err)
+	}
+
+	resp := decodeResponse(t, evt)
+	if resp.DurationNs != int64(30*time.Millisecond) {
+		t.Errorf("Expected DurationNs of 30ms on the adapter's clock, got %d", resp.DurationNs)
+	}
+	if !resp.Timestamp.Equal(start.Add(30 * time.Millisecond)) {
+		t.Errorf("Expected Timestamp from the adapter's clock, got %v", resp.Timestamp)
+	}
+}
+
+func TestReply_UndecodablePayloadUsesMetadata(t *testing.T) {
+	evt := &event.Event{
+		ID:       "evt-1",
+		Type:     "net.http.request",
+		Data:     []byte("not json"),
+		Metadata: map[string]string{"request_id": "req-meta"},
+	}
+
+	respEvt, err := Reply(evt).Status(http.StatusInternalServerError).Text("Invalid request payload")
+	if err != nil {
+		t.Fatalf("Failed to build reply: %v", err)
+	}
+
+	payload := decodeResponse(t, respEvt)
+	if payload.RequestID != "req-meta" {
+		t.Errorf("Expected request ID from metadata, got %q", payload.RequestID)
+	}
+}
+
+func TestReply_NoRequestID(t *testing.T) {
+	evt := &event.Event{ID: "evt-2", Type: "net.http.request", Data: []byte("{}")}
+
+	if _, err := Reply(evt).Text("unreachable"); err == nil {
+		t.Error("Expected error for request event without request ID, got nil")
+	}
+	if _, err := Reply(nil).NoContent(); err == nil {
+		t.Error("Expected error for nil request event, got nil")
+	}
+}
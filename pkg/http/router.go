@@ -0,0 +1,440 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// Request is the decoded HTTP request passed to a HandlerFunc
+type Request struct {
+	HTTPRequestPayload
+
+	Params map[string]string // Path parameters captured by the route pattern
+	Event  *event.Event      // Underlying "net.http.request" event
+}
+
+// Response is returned by a HandlerFunc and published as a "net.http.response" event
+type Response struct {
+	StatusCode int               // Defaults to 200 (204 when Body is empty)
+	Headers    map[string]string // Response headers
+	Body       []byte            // Response body
+}
+
+// HandlerFunc handles a request synchronously and returns its response.
+// A nil response with a nil error is sent as 204 No Content.
+type HandlerFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// StatusError is a handler error that maps to a specific HTTP status
+type StatusError struct {
+	Status  int
+	Message string
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+// Errorf returns a StatusError with a formatted message
+func Errorf(status int, format string, args ...any) error {
+	return &StatusError{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// route is a registered method + path pattern
+type route struct {
+	method  string // Empty matches any method
+	pattern string
+	handler HandlerFunc
+}
+
+// String returns the route as registered, e.g. "GET /users/:id"
+func (rt route) String() string {
+	if rt.method == "" {
+		return rt.pattern
+	}
+	return rt.method + " " + rt.pattern
+}
+
+// Router dispatches "net.http.request" events to synchronous handlers.
+// Request events stay on the bus, so other subscribers still observe them;
+// requests that match no route are ignored unless WithNotFound is set.
+type Router struct {
+	bus      event.Bus
+	clk      clock.Clock
+	logger   *slog.Logger
+	source   string
+	workers  int
+	timeout  time.Duration
+	notFound HandlerFunc
+	metrics  metrics.Counters
+
+	mu      sync.RWMutex
+	routes  []route
+	sub     event.Subscription
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	done    chan struct{} // Closed once the workers have exited
+	running bool
+}
+
+// RouterOption configures a Router
+type RouterOption func(*Router)
+
+// WithWorkers sets the number of handler goroutines (defaults to runtime.NumCPU())
+func WithWorkers(n int) RouterOption {
+	return func(r *Router) {
+		if n > 0 {
+			r.workers = n
+		}
+	}
+}
+
+// WithNotFound sets the handler for requests that match no route
+func WithNotFound(h HandlerFunc) RouterOption {
+	return func(r *Router) {
+		r.notFound = h
+	}
+}
+
+// WithRouterSource sets the source of published response events
+func WithRouterSource(source string) RouterOption {
+	return func(r *Router) {
+		r.source = source
+	}
+}
+
+// WithRouterClock sets the clock that timestamps response events
+func WithRouterClock(clk clock.Clock) RouterOption {
+	return func(r *Router) {
+		r.clk = clk
+	}
+}
+
+// WithRouterLogger sets the logger the router writes failed responses to
+// (defaults to slog.Default())
+func WithRouterLogger(logger *slog.Logger) RouterOption {
+	return func(r *Router) {
+		if logger != nil {
+			r.logger = logger
+		}
+	}
+}
+
+// WithHandlerTimeout sets the deadline of the context passed to each handler
+// (defaults to 30 seconds, the ServerAdapter's response timeout). A handler
+// that fails with context.DeadlineExceeded is answered with 504 Gateway
+// Timeout.
+func WithHandlerTimeout(d time.Duration) RouterOption {
+	return func(r *Router) {
+		if d > 0 {
+			r.timeout = d
+		}
+	}
+}
+
+// NewRouter creates a router that consumes requests from and publishes responses to bus
+func NewRouter(bus event.Bus, opts ...RouterOption) *Router {
+	r := &Router{
+		bus:     bus,
+		clk:     clock.System(),
+		logger:  slog.Default(),
+		source:  "http-router",
+		workers: runtime.NumCPU(),
+		timeout: responseTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Handle registers a handler for a pattern such as "GET /users/:id".
+// Patterns without a method match every method.
+func (r *Router) Handle(pattern string, h HandlerFunc) error {
+	if h == nil {
+		return fmt.Errorf("nil handler for pattern %q", pattern)
+	}
+
+	rt := route{pattern: strings.TrimSpace(pattern), handler: h}
+	if method, path, ok := strings.Cut(rt.pattern, " "); ok {
+		rt.method = strings.ToUpper(method)
+		rt.pattern = strings.TrimSpace(path)
+	}
+	if !strings.HasPrefix(rt.pattern, "/") {
+		return fmt.Errorf("invalid pattern %q: path must start with /", pattern)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.routes {
+		if existing.method == rt.method && existing.pattern == rt.pattern {
+			return fmt.Errorf("pattern %q already registered", pattern)
+		}
+	}
+	r.routes = append(r.routes, rt)
+	return nil
+}
+
+// Start subscribes to request events and starts the worker pool
+func (r *Router) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return fmt.Errorf("router already running")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	sub, err := r.bus.Subscribe(ctx, event.Filter{
+		Types: []string{"net.http.request"},
+	})
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	r.sub = sub
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	r.running = true
+
+	for i := 0; i < r.workers; i++ {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			for evt := range sub.Events() {
+				r.dispatch(ctx, evt)
+			}
+		}()
+	}
+	go func(done chan struct{}) {
+		r.wg.Wait()
+		close(done)
+	}(r.done)
+
+	return nil
+}
+
+// Stop closes the subscription and waits for in-flight handlers to finish
+func (r *Router) Stop() error {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return nil
+	}
+	r.running = false
+	sub, cancel := r.sub, r.cancel
+	r.mu.Unlock()
+
+	err := sub.Close()
+	cancel()
+	r.wg.Wait()
+	return err
+}
+
+// ID returns the source of the router's response events
+func (r *Router) ID() string {
+	return r.source
+}
+
+// Metrics returns the router's counters. ErrorCount counts handlers that
+// failed without a StatusError and responses that could not be built or
+// published; Latency["handler"] is the time spent in handlers.
+func (r *Router) Metrics() metrics.NetworkMetrics {
+	return r.metrics.Snapshot(r.source, "http-router", "http", r.clk.Wall())
+}
+
+// match finds the route and path parameters for a request
+func (r *Router) match(method, path string) (*route, map[string]string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i, rt := range r.routes {
+		if rt.method != "" && rt.method != method {
+			continue
+		}
+		if params, ok := matchPath(rt.pattern, path); ok {
+			return &r.routes[i], params
+		}
+	}
+	return nil, nil
+}
+
+// dispatch runs the matching handler and publishes its response
+func (r *Router) dispatch(ctx context.Context, evt *event.Event) {
+	var payload HTTPRequestPayload
+	if err := codec.Decode(evt, &payload); err != nil {
+		r.publish(ctx, evt, "", nil, fmt.Errorf("failed to decode request: %w", err))
+		return
+	}
+
+	handler, pattern := r.notFound, ""
+	rt, params := r.match(payload.Method, payload.Path)
+	if rt != nil {
+		handler, pattern = rt.handler, rt.String()
+	}
+	if handler == nil {
+		return
+	}
+
+	req := &Request{
+		HTTPRequestPayload: payload,
+		Params:             params,
+		Event:              evt,
+	}
+	handlerCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	start := r.clk.Now()
+	resp, err := r.call(handlerCtx, handler, req)
+	r.metrics.Observe("handler", r.clk.Since(start))
+	cancel()
+
+	r.publish(ctx, evt, pattern, resp, err)
+}
+
+// call invokes a handler, converting panics into errors
+func (r *Router) call(ctx context.Context, h HandlerFunc, req *Request) (resp *Response, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			resp, err = nil, fmt.Errorf("handler panic: %v", p)
+		}
+	}()
+	return h(ctx, req)
+}
+
+// publish turns a handler result into a response event and publishes it.
+// pattern is the matched route, recorded for access logs. A response that
+// cannot be built is replaced with a 500, so the client is not left waiting.
+func (r *Router) publish(ctx context.Context, reqEvt *event.Event, pattern string, resp *Response, err error) {
+	reply := Reply(reqEvt).From(r.source).Clock(r.clk).Route(pattern)
+
+	var respEvt *event.Event
+	switch {
+	case err != nil:
+		var statusErr *StatusError
+		switch {
+		case errors.As(err, &statusErr):
+			respEvt, err = reply.Problem(Problem{Status: statusErr.Status, Detail: statusErr.Message})
+		case errors.Is(err, context.DeadlineExceeded):
+			r.metrics.Error()
+			respEvt, err = reply.Problem(Problem{Status: http.StatusGatewayTimeout})
+		default:
+			r.metrics.Error()
+			r.logger.Error("HTTP handler failed", "route", pattern, "request_id", reqEvt.Metadata["request_id"], "error", err)
+			respEvt, err = reply.Problem(Problem{Status: http.StatusInternalServerError})
+		}
+	case resp == nil:
+		respEvt, err = reply.NoContent()
+	default:
+		for key, value := range resp.Headers {
+			reply.Header(key, value)
+		}
+		status := resp.StatusCode
+		if status == 0 && len(resp.Body) == 0 {
+			status = http.StatusNoContent
+		}
+		respEvt, err = reply.Status(status).Bytes("", resp.Body)
+	}
+	if err != nil {
+		r.metrics.Error()
+		r.logger.Error("Failed to build HTTP response", "route", pattern, "request_id", reqEvt.Metadata["request_id"], "error", err)
+		respEvt, err = Reply(reqEvt).From(r.source).Clock(r.clk).Route(pattern).
+			Problem(Problem{Status: http.StatusInternalServerError})
+		if err != nil {
+			return
+		}
+	}
+
+	if err := r.bus.Publish(ctx, respEvt); err != nil {
+		r.metrics.Error()
+		r.logger.Error("Failed to publish HTTP response", "route", pattern, "request_id", reqEvt.Metadata["request_id"], "error", err)
+	}
+}
+
+// defaultRouters holds the router used by Handle for each engine
+var (
+	defaultRoutersMu sync.Mutex
+	defaultRouters   = make(map[*engine.Engine]*Router)
+)
+
+// Handle registers a handler on the engine's default router, starting the
+// router on first use. The router runs until StopHandlers is called or the
+// engine's external bus closes.
+func Handle(eng *engine.Engine, pattern string, h HandlerFunc) error {
+	defaultRoutersMu.Lock()
+	defer defaultRoutersMu.Unlock()
+
+	if r, ok := defaultRouters[eng]; ok {
+		return r.Handle(pattern, h)
+	}
+
+	r := NewRouter(eng.ExternalBus())
+	if err := r.Handle(pattern, h); err != nil {
+		return err
+	}
+	if err := r.Start(context.Background()); err != nil {
+		return err
+	}
+	defaultRouters[eng] = r
+
+	// Forget the router once its subscription ends with the bus
+	done := r.done
+	go func() {
+		<-done
+		defaultRoutersMu.Lock()
+		defer defaultRoutersMu.Unlock()
+		if defaultRouters[eng] == r {
+			delete(defaultRouters, eng)
+		}
+	}()
+	return nil
+}
+
+// StopHandlers stops the engine's default router and unregisters its
+// handlers; a later Handle starts a new router
+func StopHandlers(eng *engine.Engine) error {
+	defaultRoutersMu.Lock()
+	r, ok := defaultRouters[eng]
+	delete(defaultRouters, eng)
+	defaultRoutersMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return r.Stop()
+}
+
+// matchPath matches a path against a pattern such as "/users/:id"
+func matchPath(pattern, path string) (map[string]string, bool) {
+	params := make(map[string]string)
+
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(patternParts) != len(pathParts) {
+		return nil, false
+	}
+
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, ":") {
+			params[strings.TrimPrefix(part, ":")] = pathParts[i]
+		} else if part != pathParts[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
@@ -0,0 +1,263 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+func TestHandle_EndToEnd(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(NewServerAdapter(":18081")); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	emitterMgr := engine.NewEmitterManager(eng)
+	if err := emitterMgr.Register("http-client", NewClientEmitter(), event.Filter{
+		Types: []string{"net.http.response"},
+	}); err != nil {
+		t.Fatalf("Failed to register emitter: %v", err)
+	}
+	if err := emitterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start emitters: %v", err)
+	}
+	defer emitterMgr.Stop()
+
+	// An independent subscriber must still observe request events
+	observer, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.http.request"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer observer.Close()
+
+	if err := Handle(eng, "GET /users/:id", func(ctx context.Context, req *Request) (*Response, error) {
+		if req.Params["id"] == "missing" {
+			return nil, Errorf(http.StatusNotFound, "user %s not found", req.Params["id"])
+		}
+		body, _ := json.Marshal(map[string]string{"id": req.Params["id"]})
+		return &Response{
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    body,
+		}, nil
+	}); err != nil {
+		t.Fatalf("Failed to register handler: %v", err)
+	}
+	if err := Handle(eng, "DELETE /users/:id", func(ctx context.Context, req *Request) (*Response, error) {
+		return nil, nil
+	}); err != nil {
+		t.Fatalf("Failed to register handler: %v", err)
+	}
+	if err := Handle(eng, "/panic", func(ctx context.Context, req *Request) (*Response, error) {
+		panic("boom")
+	}); err != nil {
+		t.Fatalf("Failed to register handler: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	t.Run("handler response", func(t *testing.T) {
+		resp, err := http.Get("http://localhost:18081/users/42")
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if string(body) != `{"id":"42"}` {
+			t.Errorf("Unexpected body: %s", string(body))
+		}
+
+		select {
+		case evt := <-observer.Events():
+			if evt.Type != "net.http.request" {
+				t.Errorf("Expected observer to see net.http.request, got %s", evt.Type)
+			}
+		case <-time.After(time.Second):
+			t.Error("Expected observer to receive the request event")
+		}
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		resp, err := http.Get("http://localhost:18081/users/missing")
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Content-Type") != "application/problem+json" {
+			t.Errorf("Expected problem document, got %s", resp.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(string(body), "user missing not found") {
+			t.Errorf("Expected error detail in body, got %s", string(body))
+		}
+	})
+
+	t.Run("nil response", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodDelete, "http://localhost:18081/users/42", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		resp, err := http.Get("http://localhost:18081/panic")
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestRouter_Handle_InvalidPatterns(t *testing.T) {
+	r := NewRouter(nil)
+	noop := func(ctx context.Context, req *Request) (*Response, error) { return nil, nil }
+
+	if err := r.Handle("users", noop); err == nil {
+		t.Error("Expected error for pattern without leading slash, got nil")
+	}
+	if err := r.Handle("GET /users", nil); err == nil {
+		t.Error("Expected error for nil handler, got nil")
+	}
+	if err := r.Handle("GET /users", noop); err != nil {
+		t.Fatalf("Failed to register handler: %v", err)
+	}
+	if err := r.Handle("get /users", noop); err == nil {
+		t.Error("Expected error for duplicate pattern, got nil")
+	}
+}
+
+func TestRouter_Match(t *testing.T) {
+	r := NewRouter(nil)
+	noop := func(ctx context.Context, req *Request) (*Response, error) { return nil, nil }
+	r.Handle("GET /users/:id", noop)
+	r.Handle("/health", noop)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		matched bool
+		params  map[string]string
+	}{
+		{name: "method and params", method: "GET", path: "/users/7", matched: true, params: map[string]string{"id": "7"}},
+		{name: "wrong method", method: "POST", path: "/users/7", matched: false},
+		{name: "any method", method: "POST", path: "/health", matched: true, params: map[string]string{}},
+		{name: "no route", method: "GET", path: "/orders", matched: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, params := r.match(tt.method, tt.path)
+			if (h != nil) != tt.matched {
+				t.Fatalf("Expected matched=%v, got %v", tt.matched, h != nil)
+			}
+			for key, expected := range tt.params {
+				if params[key] != expected {
+					t.Errorf("Expected param %s=%s, got %s", key, expected, params[key])
+				}
+			}
+		})
+	}
+}
+
+func TestRouter_HandlerTimeout(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+	bus := eng.ExternalBus()
+
+	responses, err := bus.Subscribe(context.Background(), event.Filter{Types: []string{"net.http.response"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer responses.Close()
+
+	r := NewRouter(bus, WithHandlerTimeout(20*time.Millisecond))
+	r.Handle("/slow", func(ctx context.Context, req *Request) (*Response, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start router: %v", err)
+	}
+	defer r.Stop()
+
+	reqEvt, _ := codec.NewEvent("net.http.request", "test", HTTPRequestPayload{RequestID: "req-1", Method: "GET", Path: "/slow"}, codec.JSON{})
+	reqEvt.WithMetadata("request_id", "req-1")
+	bus.Publish(context.Background(), reqEvt)
+
+	select {
+	case evt := <-responses.Events():
+		var payload HTTPResponsePayload
+		if err := codec.Decode(evt, &payload); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if payload.StatusCode != http.StatusGatewayTimeout {
+			t.Errorf("Expected status 504, got %d", payload.StatusCode)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a response once the handler deadline passed")
+	}
+	if got := r.Metrics().ErrorCount; got != 1 {
+		t.Errorf("Expected the timed out handler to count as an error, got %d", got)
+	}
+}
+
+func TestStopHandlers(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	noop := func(ctx context.Context, req *Request) (*Response, error) { return nil, nil }
+	if err := Handle(eng, "/health", noop); err != nil {
+		t.Fatalf("Failed to register handler: %v", err)
+	}
+	if err := StopHandlers(eng); err != nil {
+		t.Fatalf("Failed to stop handlers: %v", err)
+	}
+
+	defaultRoutersMu.Lock()
+	_, ok := defaultRouters[eng]
+	defaultRoutersMu.Unlock()
+	if ok {
+		t.Error("Expected the default router to be removed")
+	}
+
+	// The pattern is free again on a new router
+	if err := Handle(eng, "/health", noop); err != nil {
+		t.Errorf("Expected to register on a new router, got %v", err)
+	}
+	StopHandlers(eng)
+}
@@ -1,18 +1,30 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/correlate"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
 	"github.com/BYTE-6D65/pipeline/pkg/event"
 	"github.com/google/uuid"
 )
 
+// responseTimeout is how long a request waits for its response event
+const responseTimeout = 30 * time.Second
+
 // ServerAdapter listens for HTTP requests and publishes them as events
 type ServerAdapter struct {
 	id     string
@@ -20,16 +32,23 @@ type ServerAdapter struct {
 	server *http.Server
 	bus    event.Bus
 	clk    clock.Clock
+	opts   options
+	stats  metrics.Counters
+
+	// Requests waiting for a response, by request ID; replaced by each Start
+	responses atomic.Pointer[correlate.Table[*responseWriter]]
 
 	mu      sync.Mutex
 	running bool
 }
 
 // NewServerAdapter creates a new HTTP server adapter
-func NewServerAdapter(addr string) *ServerAdapter {
+func NewServerAdapter(addr string, opts ...Option) *ServerAdapter {
 	return &ServerAdapter{
 		id:   fmt.Sprintf("http-server-%s", addr),
 		addr: addr,
+		clk:  clock.System(),
+		opts: newOptions(opts),
 	}
 }
 
@@ -43,8 +62,9 @@ func (a *ServerAdapter) Type() string {
 	return "http-server"
 }
 
-// Start begins listening for HTTP requests
-func (a *ServerAdapter) Start(ctx context.Context, bus event.Bus, clk clock.Clock) error {
+// Start begins listening for HTTP requests. Timestamps and the response
+// timeout are taken from clk (see clock.From).
+func (a *ServerAdapter) Start(ctx context.Context, bus event.Bus, clk pipeclock.Clock) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -53,23 +73,38 @@ func (a *ServerAdapter) Start(ctx context.Context, bus event.Bus, clk clock.Cloc
 	}
 
 	a.bus = bus
-	a.clk = clk
+	if clk != nil {
+		a.clk = clock.From(clk)
+	}
 
 	// Create HTTP handler that publishes events
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		a.handleRequest(ctx, w, r)
 	})
 
+	ln, err := net.Listen("tcp", a.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.addr, err)
+	}
+
+	a.responses.Store(correlate.NewTable[*responseWriter](correlate.WithClock(a.clk)))
+	servers.Store(a, struct{}{})
+
 	a.server = &http.Server{
-		Addr:    a.addr,
-		Handler: handler,
+		Addr:        a.addr,
+		Handler:     handler,
+		ConnContext: connContext,
+		ErrorLog:    slog.NewLogLogger(a.opts.logger.Handler(), slog.LevelError),
+	}
+	if a.opts.connectionEvents {
+		a.server.ConnState = a.connState(ctx)
 	}
 
 	// Start server in goroutine
+	server := a.server
 	go func() {
-		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			// Log error - in production would use proper logging
-			fmt.Printf("HTTP server error: %v\n", err)
+		if err := server.Serve(trackingListener{Listener: ln, stats: &a.stats}); err != nil && err != http.ErrServerClosed {
+			a.opts.logger.Error("HTTP server failed", "adapter_id", a.id, "error", err)
 		}
 	}()
 
@@ -77,7 +112,8 @@ func (a *ServerAdapter) Start(ctx context.Context, bus event.Bus, clk clock.Cloc
 	return nil
 }
 
-// Stop shuts down the HTTP server
+// Stop shuts down the HTTP server. Requests still waiting for a response are
+// answered with 503 Service Unavailable.
 func (a *ServerAdapter) Stop() error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -89,20 +125,98 @@ func (a *ServerAdapter) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// Release waiting handlers so Shutdown need not wait out their timeout,
+	// then any that registered while the listener was closing
+	responses := a.responses.Load()
+	responses.CancelAll(correlate.ErrCanceled)
 	err := a.server.Shutdown(ctx)
+	responses.CancelAll(correlate.ErrCanceled)
+	servers.Delete(a)
 	a.running = false
 	return err
 }
 
+// Metrics returns the adapter's traffic counters. Bytes are counted on the
+// socket, including headers; errors are requests that failed or timed out.
+// Replies counts the requests waiting for a response since the last Start.
+func (a *ServerAdapter) Metrics() metrics.NetworkMetrics {
+	snapshot := a.stats.Snapshot(a.id, a.Type(), "http", a.clk.Wall())
+	if responses := a.responses.Load(); responses != nil {
+		replies := responses.Stats()
+		snapshot.Replies = &replies
+	}
+	return snapshot
+}
+
 // handleRequest processes an HTTP request and publishes it as an event
 func (a *ServerAdapter) handleRequest(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-	// Read request body
-	body, err := io.ReadAll(r.Body)
+	// Generate request ID
+	accepted := a.clk.Now()
+	received := a.clk.Wall()
+	requestID := uuid.New().String()
+	connID := ""
+	if tc := requestConn(r); tc != nil {
+		tc.requests.Add(1)
+		connID = tc.id
+	}
+
+	// Record the response for the access log once the request is done
+	var principal, route string
+	var timing *HTTPTiming
+	if a.opts.accessLogFormat != 0 {
+		rec := &accessRecorder{ResponseWriter: w, clk: a.clk}
+		w = rec
+		defer func() {
+			a.logAccess(r.Context(), r, rec, accessEntry{
+				requestID: requestID,
+				connID:    connID,
+				principal: principal,
+				route:     route,
+				accepted:  accepted,
+				received:  received,
+				timing:    timing,
+			})
+		}()
+	}
+
+	// Authenticate before anything is published
+	if a.opts.auth != nil {
+		var err error
+		if principal, err = a.opts.auth.Authenticate(r); err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	// Hand protocol upgrades on configured routes to their handler
+	if h, pattern, ok := a.upgradeHandler(r); ok {
+		route = pattern
+		h.ServeUpgrade(w, r, a.upgradeMetadata(r, requestID, connID, principal))
+		return
+	}
+
+	// Hand requests in other protocols (gRPC-Web, transcoded REST) to their gateway
+	if h, ok := a.gatewayHandler(r); ok {
+		h.ServeGateway(w, r, a.upgradeMetadata(r, requestID, connID, principal))
+		return
+	}
+
+	if a.opts.cloudEvents {
+		if mode, ok := detectCloudEvents(r); ok {
+			a.handleCloudEvents(ctx, w, r, mode, requestID, connID, principal)
+			return
+		}
+	}
+
+	// Read request body (large bodies go to the blob store when claim check is enabled)
+	body, bodyRef, err := readBody(r.Context(), r.Body, a.opts.blobs, a.opts.claimThreshold)
 	if err != nil {
+		a.stats.Error()
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
+	bodyRead := a.clk.Now()
 
 	// Parse query parameters
 	query := make(map[string]string)
@@ -120,30 +234,29 @@ func (a *ServerAdapter) handleRequest(ctx context.Context, w http.ResponseWriter
 		}
 	}
 
-	// Generate request ID
-	requestID := uuid.New().String()
-
 	// Get local address
 	localAddr := a.addr
 
 	// Create payload
 	payload := HTTPRequestPayload{
-		RequestID:  requestID,
-		Method:     r.Method,
-		Path:       r.URL.Path,
-		Query:      query,
-		Headers:    headers,
-		Body:       body,
-		RemoteAddr: r.RemoteAddr,
-		LocalAddr:  localAddr,
-		Timestamp:  time.Now(),
-		TLS:        r.TLS != nil,
-	}
-
-	// Create event with JSON codec
-	codec := event.JSONCodec{}
-	evt, err := event.NewEvent("net.http.request", a.id, payload, codec)
+		RequestID:    requestID,
+		ConnectionID: connID,
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		Query:        query,
+		Headers:      headers,
+		Body:         body,
+		BodyRef:      bodyRef,
+		RemoteAddr:   r.RemoteAddr,
+		LocalAddr:    localAddr,
+		Timestamp:    received,
+		TLS:          r.TLS != nil,
+	}
+
+	// Create event with the configured codec (recorded in metadata)
+	evt, err := codec.NewEvent("net.http.request", a.id, payload, a.opts.codec)
 	if err != nil {
+		a.stats.Error()
 		http.Error(w, "Failed to create event", http.StatusInternalServerError)
 		return
 	}
@@ -151,85 +264,195 @@ func (a *ServerAdapter) handleRequest(ctx context.Context, w http.ResponseWriter
 	// Add metadata
 	evt.WithMetadata("adapter_id", a.id).
 		WithMetadata("request_id", requestID)
+	if connID != "" {
+		evt.WithMetadata("connection_id", connID)
+	}
+	if principal != "" {
+		evt.WithMetadata("principal", principal)
+	}
+
+	// Let interceptors enrich, answer or refuse the request before it is published
+	if !a.intercept(ctx, w, &payload, evt) {
+		return
+	}
 
-	// Store response writer in global registry
+	// Register the response writer for the emitter to find by request ID
 	rw := &responseWriter{
-		w:         w,
-		requestID: requestID,
-		written:   false,
-		done:      make(chan struct{}),
+		w:              w,
+		requestID:      requestID,
+		acceptEncoding: r.Header.Get("Accept-Encoding"),
+		clk:            a.clk,
+		serverTiming:   a.opts.serverTiming,
+		times:          stageTimes{accepted: accepted, bodyRead: bodyRead, encoded: a.clk.Now()},
+	}
+	pending, err := a.responses.Load().Register(requestID, rw, 0)
+	if err != nil {
+		a.stats.Error()
+		http.Error(w, "Failed to process request", http.StatusInternalServerError)
+		return
 	}
-	globalResponseWriters.Store(requestID, rw)
+	rw.pending = pending
 
 	// Publish event
 	if err := a.bus.Publish(ctx, evt); err != nil {
-		globalResponseWriters.Delete(requestID)
+		pending.Cancel(err)
+		a.stats.Error()
 		http.Error(w, "Failed to process request", http.StatusInternalServerError)
 		return
 	}
+	rw.markPublished()
+
+	// Record the stage breakdown once the request is done
+	defer func() {
+		times := rw.stageTimes()
+		t := times.timing(a.clk.Now())
+		a.observe(times, t)
+		timing = &t
+		if a.opts.responseEvents {
+			a.publishSent(ctx, rw, connID, t)
+		}
+	}()
 
 	// Wait for response with timeout
+	timeout := a.clk.NewTimer(responseTimeout)
+	defer timeout.Stop()
+
 	select {
-	case <-rw.done:
-		// Response was written
-		globalResponseWriters.Delete(requestID)
-	case <-time.After(30 * time.Second):
-		// Timeout - write default response
-		globalResponseWriters.Delete(requestID)
-		if !rw.written {
+	case <-pending.Done():
+		if errors.Is(pending.Err(), correlate.ErrCanceled) {
+			// Stop canceled the request before a response arrived
+			a.stats.Error()
+			http.Error(w, "Server shutting down", http.StatusServiceUnavailable)
+			return
+		}
+	case <-timeout.C():
+		if pending.Cancel(correlate.ErrTimeout) {
+			// Timeout - write default response
+			a.stats.Error()
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte("Request processed"))
+			return
 		}
+		<-pending.Done() // The emitter claimed the response first
 	}
+	route = rw.route
 }
 
 // responseWriter wraps http.ResponseWriter with tracking
 type responseWriter struct {
-	w         http.ResponseWriter
-	requestID string
-	written   bool
-	done      chan struct{}
-	mu        sync.Mutex
+	w              http.ResponseWriter
+	requestID      string
+	acceptEncoding string // The request's Accept-Encoding, for CompressResponses
+	route          string // Pattern that produced the response, for the access log
+	status         int    // Status written by the emitter, zero until then
+	headers        map[string]string
+	clk            clock.Clock
+	serverTiming   bool // Send the stage breakdown in a Server-Timing header
+	times          stageTimes
+	pending        *correlate.Pending[*responseWriter] // Claimed once, by the writer of the response
+	mu             sync.Mutex
+}
+
+// markPublished records that the request event was published
+func (rw *responseWriter) markPublished() {
+	now := rw.clk.Now()
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.times.published = now
+	rw.times.hasPublished = true
+}
+
+// stageTimes returns the stage clock readings taken so far
+func (rw *responseWriter) stageTimes() stageTimes {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	return rw.times
+}
+
+// written returns the route, status and headers of the response written by
+// the emitter (status is zero if none was)
+func (rw *responseWriter) written() (string, int, map[string]string) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	return rw.route, rw.status, rw.headers
+}
+
+// setRoute records the pattern that produced the response (called by emitter
+// before writing)
+func (rw *responseWriter) setRoute(route string) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.route = route
 }
 
 // WriteResponse writes the HTTP response (called by emitter)
 func (rw *responseWriter) WriteResponse(statusCode int, headers map[string]string, body []byte) error {
+	return rw.WriteResponseFrom(statusCode, headers, bytes.NewReader(body))
+}
+
+// WriteResponseFrom writes the HTTP response, streaming the body from r
+func (rw *responseWriter) WriteResponseFrom(statusCode int, headers map[string]string, body io.Reader) (err error) {
+	if !rw.pending.Claim() {
+		return fmt.Errorf("response for request %s already written or timed out", rw.requestID)
+	}
+	defer func() { rw.pending.Finish(err) }() // Release the handler once written
+
 	rw.mu.Lock()
 	defer rw.mu.Unlock()
 
-	if rw.written {
-		return fmt.Errorf("response already written")
-	}
+	rw.times.received = rw.clk.Now()
+	rw.status, rw.headers = statusCode, headers
 
 	// Set headers
 	for key, value := range headers {
 		rw.w.Header().Set(key, value)
 	}
+	if rw.serverTiming {
+		rw.w.Header().Add("Server-Timing", rw.times.serverTiming())
+	}
 
 	// Write status code
 	rw.w.WriteHeader(statusCode)
 
 	// Write body
-	if len(body) > 0 {
-		_, err := rw.w.Write(body)
-		if err != nil {
-			return err
-		}
-	}
-
-	rw.written = true
-	close(rw.done) // Signal that response is written
-	return nil
+	_, err = io.Copy(rw.w, body)
+	rw.times.written = rw.clk.Now()
+	rw.times.hasResponse = true
+	return err
 }
 
-// Global response writer registry
-var globalResponseWriters sync.Map
+// servers holds the running adapters, whose response tables
+// GetResponseWriter searches
+var servers sync.Map // *ServerAdapter -> struct{}
+
+// serverClock returns the clock of the running adapter with the given ID, or
+// the system clock if none is running
+func serverClock(adapterID string) clock.Clock {
+	clk := clock.System()
+	servers.Range(func(key, _ any) bool {
+		if a := key.(*ServerAdapter); a.id == adapterID {
+			clk = a.clk
+			return false
+		}
+		return true
+	})
+	return clk
+}
 
-// GetResponseWriter retrieves a response writer by request ID
+// GetResponseWriter retrieves a response writer by request ID from the
+// running adapter that received the request
 func GetResponseWriter(requestID string) (*responseWriter, bool) {
-	val, ok := globalResponseWriters.Load(requestID)
-	if !ok {
-		return nil, false
-	}
-	return val.(*responseWriter), true
+	var rw *responseWriter
+	servers.Range(func(key, _ any) bool {
+		if p, ok := key.(*ServerAdapter).responses.Load().Peek(requestID); ok {
+			rw = p.Handle()
+			return false
+		}
+		return true
+	})
+	return rw, rw != nil
 }
@@ -8,7 +8,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
 	"github.com/BYTE-6D65/pipeline/pkg/engine"
 	"github.com/BYTE-6D65/pipeline/pkg/event"
 )
@@ -244,7 +245,7 @@ func TestServerAdapter_StartTwice(t *testing.T) {
 	defer adapterMgr.Stop()
 
 	// Try to start again - should fail since it's already running
-	err := adapter.Start(context.Background(), eng.ExternalBus(), clock.NewSystemClock())
+	err := adapter.Start(context.Background(), eng.ExternalBus(), clock.System())
 	if err == nil {
 		t.Error("Expected error when starting adapter twice, got nil")
 	}
@@ -282,3 +283,195 @@ func (m *MockClock) Now() clock.MonoTime {
 func (m *MockClock) Since(t clock.MonoTime) time.Duration {
 	return clock.ToDuration(m.now - t)
 }
+
+func TestHTTPServerAdapter_WithCodec(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(NewServerAdapter(":18082", WithCodec(codec.Binary{}))); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	emitterMgr := engine.NewEmitterManager(eng)
+	if err := emitterMgr.Register("http-client", NewClientEmitter(), event.Filter{
+		Types: []string{"net.http.response"},
+	}); err != nil {
+		t.Fatalf("Failed to register emitter: %v", err)
+	}
+	if err := emitterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start emitters: %v", err)
+	}
+	defer emitterMgr.Stop()
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.http.request"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	codecs := make(chan string, 1)
+	go func() {
+		for evt := range sub.Events() {
+			codecs <- evt.Metadata[codec.MetadataKey]
+			response, err := CreateEchoResponse(evt)
+			if err != nil {
+				t.Errorf("Failed to create echo response: %v", err)
+				continue
+			}
+			eng.ExternalBus().Publish(context.Background(), response)
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := http.Post("http://localhost:18082/binary", "application/octet-stream", bytes.NewReader([]byte{0, 1, 2}))
+	if err != nil {
+		t.Fatalf("Failed to send POST request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if !bytes.Contains(respBody, []byte("POST /binary")) {
+		t.Errorf("Expected echo response, got: %s", string(respBody))
+	}
+	if name := <-codecs; name != "binary" {
+		t.Errorf("Expected request event encoded with binary codec, got %q", name)
+	}
+}
+
+func TestServerAdapter_ResponseTimeout(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.http.request"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	// Nothing answers, so the request waits out the response timeout on the fake clock
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	adapter := NewServerAdapter(":18099")
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), clk); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	type result struct {
+		body []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://localhost:18099/slow")
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		done <- result{body: body, err: err}
+	}()
+
+	select {
+	case evt := <-sub.Events():
+		var payload HTTPRequestPayload
+		if err := evt.DecodePayload(&payload, event.JSONCodec{}); err != nil {
+			t.Fatalf("Failed to decode payload: %v", err)
+		}
+		if !payload.Timestamp.Equal(start) {
+			t.Errorf("Expected timestamp %v from the clock, got %v", start, payload.Timestamp)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for request event")
+	}
+
+	clk.BlockUntil(1)
+	clk.Advance(responseTimeout)
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("Request failed: %v", res.err)
+		}
+		if string(res.body) != "Request processed" {
+			t.Errorf("Expected the timeout response, got %q", res.body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Request did not time out after advancing the clock")
+	}
+	m := adapter.Metrics()
+	if m.ErrorCount != 1 {
+		t.Errorf("Expected the timeout to count as an error, got %d", m.ErrorCount)
+	}
+	if m.Replies == nil || m.Replies.TimedOut != 1 || m.Replies.Pending != 0 {
+		t.Errorf("Expected one timed out reply in the adapter's table, got %+v", m.Replies)
+	}
+}
+
+func TestServerAdapter_StopCancelsWaitingRequests(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.http.request"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	adapter := NewServerAdapter(":18102")
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), clock.System()); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://localhost:18102/pending")
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+
+	// Nothing answers; Stop must not wait for the response timeout
+	select {
+	case <-sub.Events():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for request event")
+	}
+
+	start := time.Now()
+	if err := adapter.Stop(); err != nil {
+		t.Errorf("Expected a clean shutdown, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Stop to return promptly, took %v", elapsed)
+	}
+
+	select {
+	case code := <-status:
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("Expected 503 for the canceled request, got %d", code)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Request was not answered after Stop")
+	}
+	if replies := adapter.Metrics().Replies; replies == nil || replies.Canceled != 1 {
+		t.Errorf("Expected one canceled reply, got %+v", replies)
+	}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+)
+
+// HTTPTiming breaks the latency of a request published to the bus down by
+// stage. Stages run back to back, so they add up to TotalNs.
+type HTTPTiming struct {
+	ReadNs     int64 `json:"read_ns"`     // Accept to request body read
+	EncodeNs   int64 `json:"encode_ns"`   // Building the request event
+	PipelineNs int64 `json:"pipeline_ns"` // Publishing to the response event arriving (bus and handlers)
+	PublishNs  int64 `json:"publish_ns"`  // Part of PipelineNs spent in bus Publish
+	WriteNs    int64 `json:"write_ns"`    // Writing the response to the socket
+	TotalNs    int64 `json:"total_ns"`    // Accept to response written
+}
+
+// stageTimes are the clock readings taken as a request passes through the
+// ServerAdapter. published is set once bus Publish returns, which may be
+// after the response arrived; received and written are set by the emitter.
+type stageTimes struct {
+	accepted  clock.MonoTime
+	bodyRead  clock.MonoTime
+	encoded   clock.MonoTime
+	published clock.MonoTime
+	received  clock.MonoTime
+	written   clock.MonoTime
+
+	hasPublished bool
+	hasResponse  bool // received and written are set
+}
+
+// timing computes the breakdown, ending at now when the response never arrived
+func (s stageTimes) timing(now clock.MonoTime) HTTPTiming {
+	t := HTTPTiming{
+		ReadNs:   int64(s.bodyRead - s.accepted),
+		EncodeNs: int64(s.encoded - s.bodyRead),
+	}
+	if s.hasPublished {
+		t.PublishNs = int64(s.published - s.encoded)
+	}
+	if s.hasResponse {
+		t.PipelineNs = int64(s.received - s.encoded)
+		t.WriteNs = int64(s.written - s.received)
+		t.TotalNs = int64(s.written - s.accepted)
+	} else {
+		t.PipelineNs = int64(now - s.encoded)
+		t.TotalNs = int64(now - s.accepted)
+	}
+	return t
+}
+
+// serverTiming formats the stages known when the response starts as a
+// Server-Timing header value (durations in milliseconds)
+func (s stageTimes) serverTiming() string {
+	type stage struct {
+		name string
+		dur  clock.MonoTime
+	}
+	stages := []stage{
+		{"read", s.bodyRead - s.accepted},
+		{"encode", s.encoded - s.bodyRead},
+		{"pipeline", s.received - s.encoded},
+	}
+	if s.hasPublished {
+		stages = append(stages, stage{"publish", s.published - s.encoded})
+	}
+
+	parts := make([]string, len(stages))
+	for i, stage := range stages {
+		ms := float64(clock.ToDuration(stage.dur)) / float64(time.Millisecond)
+		parts[i] = fmt.Sprintf("%s;dur=%.3f", stage.name, ms)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// observe adds the stages a request went through to the adapter's latency metrics
+func (a *ServerAdapter) observe(s stageTimes, t HTTPTiming) {
+	a.stats.Observe("read", time.Duration(t.ReadNs))
+	a.stats.Observe("encode", time.Duration(t.EncodeNs))
+	if s.hasPublished {
+		a.stats.Observe("publish", time.Duration(t.PublishNs))
+	}
+	if s.hasResponse {
+		a.stats.Observe("pipeline", time.Duration(t.PipelineNs))
+		a.stats.Observe("write", time.Duration(t.WriteNs))
+		a.stats.Observe("total", time.Duration(t.TotalNs))
+	}
+}
+
+// publishSent publishes a "net.http.response.sent" event with the timing of a
+// request that was published to the bus
+func (a *ServerAdapter) publishSent(ctx context.Context, rw *responseWriter, connID string, t HTTPTiming) {
+	route, status, headers := rw.written()
+	if status == 0 {
+		status = http.StatusOK // Timed out with the default response
+	}
+
+	payload := HTTPResponsePayload{
+		RequestID:  rw.requestID,
+		StatusCode: status,
+		Headers:    headers,
+		Route:      route,
+		Timestamp:  a.clk.Wall(),
+		DurationNs: t.TotalNs,
+		Timing:     &t,
+	}
+	evt, err := codec.NewEvent("net.http.response.sent", a.id, payload, a.opts.codec)
+	if err != nil {
+		return
+	}
+	evt.WithMetadata("adapter_id", a.id).
+		WithMetadata("request_id", rw.requestID)
+	if connID != "" {
+		evt.WithMetadata("connection_id", connID)
+	}
+
+	a.bus.Publish(ctx, evt)
+}
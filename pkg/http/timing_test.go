@@ -0,0 +1,191 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+func TestStageTimes(t *testing.T) {
+	ms := int64(time.Millisecond)
+	s := stageTimes{
+		accepted:     0,
+		bodyRead:     2_000_000,
+		encoded:      3_000_000,
+		published:    4_000_000,
+		received:     10_000_000,
+		written:      15_000_000,
+		hasPublished: true,
+		hasResponse:  true,
+	}
+
+	want := HTTPTiming{ReadNs: 2 * ms, EncodeNs: ms, PipelineNs: 7 * ms, PublishNs: ms, WriteNs: 5 * ms, TotalNs: 15 * ms}
+	if got := s.timing(99_000_000); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if got, want := s.serverTiming(), "read;dur=2.000, encode;dur=1.000, pipeline;dur=7.000, publish;dur=1.000"; got != want {
+		t.Errorf("Expected Server-Timing %q, got %q", want, got)
+	}
+
+	// Without a response the pipeline stage runs until now
+	s.hasResponse = false
+	if got := s.timing(20_000_000); got.PipelineNs != 17*ms || got.WriteNs != 0 || got.TotalNs != 20*ms {
+		t.Errorf("Unexpected timing without a response: %+v", got)
+	}
+}
+
+func TestServerAdapter_ServerTiming(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	adapter := NewServerAdapter(":18089", WithServerTiming())
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	emitterMgr := engine.NewEmitterManager(eng)
+	if err := emitterMgr.Register("http-client", NewClientEmitter(), event.Filter{
+		Types: []string{"net.http.response"},
+	}); err != nil {
+		t.Fatalf("Failed to register emitter: %v", err)
+	}
+	if err := emitterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start emitters: %v", err)
+	}
+	defer emitterMgr.Stop()
+
+	router := NewRouter(eng.ExternalBus())
+	router.Handle("/slow", func(ctx context.Context, req *Request) (*Response, error) {
+		time.Sleep(20 * time.Millisecond)
+		return &Response{Body: []byte("done")}, nil
+	})
+	if err := router.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start router: %v", err)
+	}
+	defer router.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := http.Get("http://localhost:18089/slow")
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+
+	header := resp.Header.Get("Server-Timing")
+	for _, stage := range []string{"read;dur=", "encode;dur=", "pipeline;dur="} {
+		if !strings.Contains(header, stage) {
+			t.Errorf("Expected %q in Server-Timing, got %q", stage, header)
+		}
+	}
+
+	// The breakdown reaches the metrics once the handler returns
+	deadline := time.Now().Add(2 * time.Second)
+	for adapter.Metrics().Latency["total"].Count == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	latency := adapter.Metrics().Latency
+	if latency["pipeline"].Count != 1 || latency["pipeline"].MaxNs < int64(20*time.Millisecond) {
+		t.Errorf("Expected one pipeline observation of at least 20ms, got %+v", latency["pipeline"])
+	}
+	if latency["write"].Count != 1 || latency["read"].Count != 1 {
+		t.Errorf("Expected read and write observations, got %+v", latency)
+	}
+}
+
+func TestServerAdapter_ResponseEvents(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.http.request", "net.http.response.sent"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	clk := clock.NewFake(time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC))
+	adapter := NewServerAdapter(":18101", WithResponseEvents())
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), clk); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Get("http://localhost:18101/orders")
+		if err != nil {
+			t.Errorf("Request failed: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+
+	next := func(eventType string, payload any) *event.Event {
+		t.Helper()
+		for {
+			select {
+			case evt := <-sub.Events():
+				if evt.Type != eventType {
+					continue
+				}
+				if err := codec.Decode(evt, payload); err != nil {
+					t.Fatalf("Failed to decode %s: %v", eventType, err)
+				}
+				return evt
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Timed out waiting for %s", eventType)
+				return nil
+			}
+		}
+	}
+
+	// The pipeline takes 20ms of adapter time to answer
+	var req HTTPRequestPayload
+	next("net.http.request", &req)
+	clk.Advance(20 * time.Millisecond)
+	rw, ok := GetResponseWriter(req.RequestID)
+	if !ok {
+		t.Fatal("Expected a pending response writer")
+	}
+	if err := rw.WriteResponse(http.StatusCreated, map[string]string{"Location": "/orders/1"}, []byte("{}")); err != nil {
+		t.Fatalf("Failed to write response: %v", err)
+	}
+	<-done
+
+	var sent HTTPResponsePayload
+	evt := next("net.http.response.sent", &sent)
+	if sent.RequestID != req.RequestID || evt.Metadata["request_id"] != req.RequestID {
+		t.Errorf("Expected request ID %s, got %+v", req.RequestID, sent)
+	}
+	if sent.StatusCode != http.StatusCreated || sent.Headers["Location"] != "/orders/1" || len(sent.Body) != 0 {
+		t.Errorf("Unexpected response payload: %+v", sent)
+	}
+	// Publish may return before or after the clock moved, so PublishNs is not compared
+	want := HTTPTiming{PipelineNs: int64(20 * time.Millisecond), TotalNs: int64(20 * time.Millisecond)}
+	if sent.Timing != nil {
+		want.PublishNs = sent.Timing.PublishNs
+	}
+	if sent.Timing == nil || *sent.Timing != want {
+		t.Errorf("Expected timing %+v from the adapter clock, got %+v", want, sent.Timing)
+	}
+	if sent.DurationNs != int64(20*time.Millisecond) {
+		t.Errorf("Expected DurationNs of 20ms, got %d", sent.DurationNs)
+	}
+}
@@ -1,11 +1,16 @@
 package http
 
-import "time"
+import (
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/blobstore"
+)
 
 // HTTPRequestPayload represents an HTTP request event
 type HTTPRequestPayload struct {
 	// Identity
-	RequestID string `json:"request_id"` // UUID for correlation
+	RequestID    string `json:"request_id"`    // UUID for correlation
+	ConnectionID string `json:"connection_id"` // Connection the request arrived on
 
 	// Request data
 	Method  string            `json:"method"`  // GET, POST, etc.
@@ -14,6 +19,9 @@ type HTTPRequestPayload struct {
 	Headers map[string]string `json:"headers"` // Content-Type, etc.
 	Body    []byte            `json:"body"`    // Request body
 
+	// Claim check for bodies offloaded to a blob store (Body is empty when set)
+	BodyRef *blobstore.Ref `json:"body_ref,omitempty"`
+
 	// Network data
 	RemoteAddr string `json:"remote_addr"` // Client IP:port
 	LocalAddr  string `json:"local_addr"`  // Server IP:port
@@ -23,6 +31,32 @@ type HTTPRequestPayload struct {
 	TLS       bool      `json:"tls"`       // HTTPS?
 }
 
+// ConnectionPayload represents a "net.connection.open", "net.connection.idle",
+// "net.connection.hijacked" or "net.connection.close" event. Counters are
+// totals for the connection so far; a hijacked connection (e.g. a WebSocket)
+// publishes no further events.
+type ConnectionPayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for connection
+
+	// State
+	State    string `json:"state"`    // new, idle, hijacked or closed
+	Protocol string `json:"protocol"` // http
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Client IP:port
+	LocalAddr  string `json:"local_addr"`  // Server IP:port
+	TLS        bool   `json:"tls"`         // HTTPS?
+
+	// Traffic
+	BytesIn  uint64 `json:"bytes_in"`  // Bytes read, including headers
+	BytesOut uint64 `json:"bytes_out"` // Bytes written, including headers
+	Requests uint64 `json:"requests"`  // Requests served
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When the state changed
+}
+
 // HTTPResponsePayload represents an HTTP response event
 type HTTPResponsePayload struct {
 	// Correlation
@@ -33,7 +67,51 @@ type HTTPResponsePayload struct {
 	Headers    map[string]string `json:"headers"`     // Content-Type, etc.
 	Body       []byte            `json:"body"`        // Response body
 
+	// Claim check for bodies offloaded to a blob store (Body is empty when set)
+	BodyRef *blobstore.Ref `json:"body_ref,omitempty"`
+
 	// Metadata
-	Timestamp   time.Time `json:"timestamp"`    // When sent
-	DurationNs  int64     `json:"duration_ns"`  // Processing time in nanoseconds
+	Route      string    `json:"route,omitempty"` // Pattern that handled the request, for access logs
+	Timestamp  time.Time `json:"timestamp"`       // When sent
+	DurationNs int64     `json:"duration_ns"`     // Processing time in nanoseconds
+
+	// Stage breakdown, set on "net.http.response.sent" events
+	Timing *HTTPTiming `json:"timing,omitempty"`
+}
+
+// HTTPOutboundPayload represents an HTTP request to send with the OutboundEmitter
+type HTTPOutboundPayload struct {
+	// Identity
+	RequestID string `json:"request_id"` // UUID for correlation
+
+	// Request data
+	Method  string            `json:"method"`  // Defaults to POST with a body, GET without
+	URL     string            `json:"url"`     // Absolute target URL
+	Headers map[string]string `json:"headers"` // Content-Type, etc.
+	Body    []byte            `json:"body"`    // Request body
+
+	// Claim check for bodies offloaded to a blob store (Body is empty when set)
+	BodyRef *blobstore.Ref `json:"body_ref,omitempty"`
+}
+
+// CloudEventPayload represents a CloudEvent received or sent over HTTP
+type CloudEventPayload struct {
+	// Required context attributes
+	ID          string `json:"id"`          // Unique within Source
+	Source      string `json:"source"`      // URI-reference of the producer
+	SpecVersion string `json:"specversion"` // Always "1.0"
+	Type        string `json:"type"`        // e.g. com.example.order.created
+
+	// Optional context attributes
+	DataContentType string    `json:"datacontenttype,omitempty"` // Content type of Data
+	DataSchema      string    `json:"dataschema,omitempty"`      // URI of the data schema
+	Subject         string    `json:"subject,omitempty"`         // Subject within Source
+	Time            time.Time `json:"time"`                      // When the occurrence happened
+
+	// Event data and extension attributes
+	Data       []byte            `json:"data,omitempty"`       // Raw event data
+	Extensions map[string]string `json:"extensions,omitempty"` // Extension attributes
+
+	// Correlation (shared by all events of one HTTP request)
+	RequestID string `json:"request_id,omitempty"`
 }
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Authenticator identifies the caller of a request.
+// It returns the principal (user, client or key ID) or an error to reject the
+// request with 401 Unauthorized.
+type Authenticator interface {
+	Authenticate(r *http.Request) (principal string, err error)
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface
+type AuthenticatorFunc func(r *http.Request) (string, error)
+
+// Authenticate calls f(r)
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (string, error) {
+	return f(r)
+}
+
+// UpgradeHandler takes over requests that ask for a protocol upgrade
+// (e.g. websocket.ServerAdapter). metadata carries the request's
+// "request_id", "http_adapter_id", "http_connection_id" and "principal"
+// (when authenticated), and its headers as "header.<Canonical-Name>".
+type UpgradeHandler interface {
+	ServeUpgrade(w http.ResponseWriter, r *http.Request, metadata map[string]string)
+}
+
+// upgradeRoute hands upgrade requests matching pattern to handler
+type upgradeRoute struct {
+	pattern string
+	handler UpgradeHandler
+}
+
+// isUpgrade reports whether r asks for a protocol upgrade
+func isUpgrade(r *http.Request) bool {
+	if r.Header.Get("Upgrade") == "" {
+		return false
+	}
+	for _, value := range r.Header.Values("Connection") {
+		for _, token := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgradeHandler returns the handler and pattern for an upgrade request, if a route matches
+func (a *ServerAdapter) upgradeHandler(r *http.Request) (UpgradeHandler, string, bool) {
+	if len(a.opts.upgrades) == 0 || !isUpgrade(r) {
+		return nil, "", false
+	}
+	for _, route := range a.opts.upgrades {
+		if _, ok := matchPath(route.pattern, r.URL.Path); ok {
+			return route.handler, route.pattern, true
+		}
+	}
+	return nil, "", false
+}
+
+// upgradeMetadata builds the metadata handed to an UpgradeHandler
+func (a *ServerAdapter) upgradeMetadata(r *http.Request, requestID, connID, principal string) map[string]string {
+	metadata := map[string]string{
+		"request_id":      requestID,
+		"http_adapter_id": a.id,
+	}
+	if connID != "" {
+		metadata["http_connection_id"] = connID
+	}
+	if principal != "" {
+		metadata["principal"] = principal
+	}
+	for key, values := range r.Header {
+		if len(values) > 0 {
+			metadata["header."+key] = values[0] // Take first value
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// fakeUpgrader records the metadata it is handed
+type fakeUpgrader struct {
+	metadata chan map[string]string
+}
+
+func (f *fakeUpgrader) ServeUpgrade(w http.ResponseWriter, r *http.Request, metadata map[string]string) {
+	f.metadata <- metadata
+	w.WriteHeader(http.StatusSwitchingProtocols)
+}
+
+func TestHTTPServerAdapter_UpgradeAndAuth(t *testing.T) {
+	upgrader := &fakeUpgrader{metadata: make(chan map[string]string, 1)}
+	auth := AuthenticatorFunc(func(r *http.Request) (string, error) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			return "", errors.New("bad token")
+		}
+		return "alice", nil
+	})
+
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(NewServerAdapter(":18085",
+		WithAuthenticator(auth),
+		WithUpgrade("/rooms/:room", upgrader),
+	)); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.http.request"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	do := func(header http.Header) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:18085/rooms/lobby", nil)
+		req.Header = header
+		client := &http.Client{Timeout: 200 * time.Millisecond}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil // Unanswered plain requests time out
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		resp := do(http.Header{})
+		if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %v", resp)
+		}
+	})
+
+	t.Run("upgrade is handed over", func(t *testing.T) {
+		resp := do(http.Header{
+			"Authorization": {"Bearer secret"},
+			"Connection":    {"keep-alive, Upgrade"},
+			"Upgrade":       {"websocket"},
+		})
+		if resp == nil || resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("Expected 101 from upgrade handler, got %v", resp)
+		}
+
+		metadata := <-upgrader.metadata
+		if metadata["principal"] != "alice" || metadata["request_id"] == "" {
+			t.Errorf("Expected principal and request ID, got %v", metadata)
+		}
+		if metadata["header.Authorization"] != "Bearer secret" {
+			t.Errorf("Expected handshake headers, got %v", metadata)
+		}
+	})
+
+	t.Run("plain request is published", func(t *testing.T) {
+		go do(http.Header{"Authorization": {"Bearer secret"}})
+
+		select {
+		case evt := <-sub.Events():
+			if evt.Metadata["principal"] != "alice" {
+				t.Errorf("Expected principal metadata, got %v", evt.Metadata)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for request event")
+		}
+		if len(upgrader.metadata) != 0 {
+			t.Error("Expected plain request not to be handed to the upgrade handler")
+		}
+	})
+}
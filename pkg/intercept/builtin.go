@@ -0,0 +1,94 @@
+package intercept
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// SignatureKey is the event metadata key holding the Sign signature
+const SignatureKey = "signature"
+
+// signaturePrefix names the algorithm in signature values
+const signaturePrefix = "sha256="
+
+// SetMetadata sets an event metadata key before delivery, overwriting any value
+func SetMetadata(key, value string) EmitInterceptor {
+	return EmitInterceptorFunc(func(ctx context.Context, evt *event.Event, next EmitFunc) error {
+		evt.WithMetadata(key, value)
+		return next(ctx, evt)
+	})
+}
+
+// Sign adds an HMAC-SHA256 signature of the event data, keyed with key, in
+// the "signature" metadata as "sha256=<hex>". Receivers check it with
+// VerifySignature. Place it after interceptors that change the data.
+func Sign(key []byte) EmitInterceptor {
+	return EmitInterceptorFunc(func(ctx context.Context, evt *event.Event, next EmitFunc) error {
+		evt.WithMetadata(SignatureKey, signaturePrefix+hex.EncodeToString(signature(key, evt.Data)))
+		return next(ctx, evt)
+	})
+}
+
+// VerifySignature reports whether the event carries a valid Sign signature for key
+func VerifySignature(evt *event.Event, key []byte) bool {
+	value, ok := strings.CutPrefix(evt.Metadata[SignatureKey], signaturePrefix)
+	if !ok {
+		return false
+	}
+	sig, err := hex.DecodeString(value)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(sig, signature(key, evt.Data))
+}
+
+// signature computes the HMAC-SHA256 of data
+func signature(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// Policy vetoes events that check refuses, with the error text as the reason.
+// Use it for per-tenant rules, quotas and similar.
+func Policy(check func(ctx context.Context, evt *event.Event) error) EmitInterceptor {
+	return EmitInterceptorFunc(func(ctx context.Context, evt *event.Event, next EmitFunc) error {
+		if err := check(ctx, evt); err != nil {
+			var veto *Veto
+			if errors.As(err, &veto) {
+				return veto
+			}
+			return &Veto{Reason: err.Error()}
+		}
+		return next(ctx, evt)
+	})
+}
+
+// Outcome is the result of delivering one event
+type Outcome struct {
+	Event   *event.Event
+	Err     error         // nil if delivered; a *Veto if a later interceptor vetoed it
+	Latency time.Duration // Time spent in the rest of the chain and the emitter
+}
+
+// Observe calls fn with the outcome of each delivery, timed with clk (the
+// system clock if nil). Place it first to time the whole chain.
+func Observe(clk clock.Clock, fn func(Outcome)) EmitInterceptor {
+	if clk == nil {
+		clk = clock.System()
+	}
+	return EmitInterceptorFunc(func(ctx context.Context, evt *event.Event, next EmitFunc) error {
+		start := clk.Now()
+		err := next(ctx, evt)
+		fn(Outcome{Event: evt, Err: err, Latency: clk.Since(start)})
+		return err
+	})
+}
@@ -0,0 +1,146 @@
+package intercept
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// EmitFunc delivers an outbound event
+type EmitFunc func(ctx context.Context, evt *event.Event) error
+
+// EmitInterceptor wraps the delivery of an outbound event. It may change the
+// event before calling next, veto delivery by returning a Veto instead of
+// calling next, and observe the outcome of next.
+//
+// The event is a copy with its own metadata. Replace Data rather than
+// modifying it in place, since the bytes are shared with other emitters.
+type EmitInterceptor interface {
+	InterceptEmit(ctx context.Context, evt *event.Event, next EmitFunc) error
+}
+
+// EmitInterceptorFunc adapts a function to an EmitInterceptor
+type EmitInterceptorFunc func(ctx context.Context, evt *event.Event, next EmitFunc) error
+
+// InterceptEmit calls f
+func (f EmitInterceptorFunc) InterceptEmit(ctx context.Context, evt *event.Event, next EmitFunc) error {
+	return f(ctx, evt, next)
+}
+
+// Veto is an error that stops an event from being delivered
+type Veto struct {
+	Reason string
+}
+
+// Vetof returns a Veto with a formatted reason
+func Vetof(format string, args ...any) *Veto {
+	return &Veto{Reason: fmt.Sprintf(format, args...)}
+}
+
+func (v *Veto) Error() string {
+	return "delivery vetoed: " + v.Reason
+}
+
+// VetoPayload represents a "net.emit.vetoed" event
+type VetoPayload struct {
+	EmitterID string    `json:"emitter_id"`
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Emitter runs outbound events through an interceptor chain before handing
+// them to the emitter it wraps. It can be registered in place of the wrapped
+// emitter and keeps its ID, type and metrics.
+type Emitter struct {
+	next engine.Emitter
+	opts options
+	emit EmitFunc // The chain, ending in next.Emit
+}
+
+// Wrap returns e decorated with the interceptors given in opts
+func Wrap(e engine.Emitter, opts ...Option) *Emitter {
+	w := &Emitter{next: e, opts: newOptions(opts)}
+
+	// Build the chain from the inside out, so the first interceptor runs first
+	w.emit = e.Emit
+	for i := len(w.opts.interceptors) - 1; i >= 0; i-- {
+		in, next := w.opts.interceptors[i], w.emit
+		w.emit = func(ctx context.Context, evt *event.Event) error {
+			return in.InterceptEmit(ctx, evt, next)
+		}
+	}
+	return w
+}
+
+// ID returns the wrapped emitter's ID
+func (w *Emitter) ID() string {
+	return w.next.ID()
+}
+
+// Type returns the wrapped emitter's type
+func (w *Emitter) Type() string {
+	return w.next.Type()
+}
+
+// Emit delivers a copy of evt through the chain. A vetoed event is reported
+// as a "net.emit.vetoed" event and the Veto is returned.
+func (w *Emitter) Emit(ctx context.Context, evt *event.Event) error {
+	draft := *evt
+	draft.Metadata = maps.Clone(evt.Metadata)
+
+	err := w.emit(ctx, &draft)
+	var veto *Veto
+	if errors.As(err, &veto) {
+		w.publishVeto(ctx, evt, veto)
+	}
+	return err
+}
+
+// Metrics returns the wrapped emitter's metrics, if it reports any
+func (w *Emitter) Metrics() metrics.NetworkMetrics {
+	if src, ok := w.next.(metrics.Source); ok {
+		return src.Metrics()
+	}
+	return metrics.NetworkMetrics{AdapterID: w.ID(), Type: w.Type(), Timestamp: w.opts.clk.Wall()}
+}
+
+// Unwrap returns the wrapped emitter
+func (w *Emitter) Unwrap() engine.Emitter {
+	return w.next
+}
+
+// Close closes the wrapped emitter
+func (w *Emitter) Close() error {
+	return w.next.Close()
+}
+
+// publishVeto publishes a "net.emit.vetoed" event for evt
+func (w *Emitter) publishVeto(ctx context.Context, evt *event.Event, veto *Veto) {
+	if w.opts.bus == nil {
+		return
+	}
+	payload := VetoPayload{
+		EmitterID: w.ID(),
+		EventID:   evt.ID,
+		EventType: evt.Type,
+		Reason:    veto.Reason,
+		Timestamp: w.opts.clk.Wall(),
+	}
+	vetoEvt, err := codec.NewEvent("net.emit.vetoed", w.ID(), payload, w.opts.codec)
+	if err != nil {
+		return
+	}
+	vetoEvt.WithMetadata("emitter_id", w.ID()).
+		WithMetadata("event_id", evt.ID)
+
+	w.opts.bus.Publish(ctx, vetoEvt)
+}
@@ -0,0 +1,157 @@
+package intercept
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// recorder is an emitter that keeps what it is given
+type recorder struct {
+	events []*event.Event
+	err    error
+	clk    *clock.Fake // Advanced by each Emit, if set
+	stats  metrics.Counters
+}
+
+func (r *recorder) ID() string   { return "recorder" }
+func (r *recorder) Type() string { return "test" }
+func (r *recorder) Close() error { return nil }
+
+func (r *recorder) Emit(ctx context.Context, evt *event.Event) error {
+	if r.clk != nil {
+		r.clk.Advance(5 * time.Millisecond)
+	}
+	r.events = append(r.events, evt)
+	return r.err
+}
+
+func (r *recorder) Metrics() metrics.NetworkMetrics {
+	return r.stats.Snapshot(r.ID(), r.Type(), "test", time.Now())
+}
+
+func TestWrap(t *testing.T) {
+	var order []string
+	trace := func(name string) EmitInterceptor {
+		return EmitInterceptorFunc(func(ctx context.Context, evt *event.Event, next EmitFunc) error {
+			order = append(order, name)
+			return next(ctx, evt)
+		})
+	}
+
+	inner := &recorder{}
+	inner.stats.Sent(42)
+	key := []byte("secret")
+	e := Wrap(inner, With(trace("first"), trace("second")), With(SetMetadata("tenant", "acme"), Sign(key)))
+
+	var _ engine.Emitter = e
+	if e.ID() != inner.ID() || e.Type() != inner.Type() || e.Metrics().BytesSent != 42 {
+		t.Errorf("Expected the wrapped emitter's identity and metrics")
+	}
+
+	evt, err := codec.NewEvent("test.message", "test", message{Text: "hi"}, codec.JSON{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if err := e.Emit(context.Background(), evt); err != nil {
+		t.Fatalf("Emit failed: %v", err)
+	}
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("Expected interceptors in order, got %v", order)
+	}
+	if len(inner.events) != 1 {
+		t.Fatalf("Expected one delivered event, got %d", len(inner.events))
+	}
+	delivered := inner.events[0]
+	if delivered.Metadata["tenant"] != "acme" || !VerifySignature(delivered, key) {
+		t.Errorf("Expected tenant and a valid signature, got %v", delivered.Metadata)
+	}
+	if VerifySignature(delivered, []byte("other")) {
+		t.Error("Expected the signature to fail with another key")
+	}
+
+	// Interceptors work on a copy; other subscribers see the event unchanged
+	if _, ok := evt.Metadata["tenant"]; ok {
+		t.Errorf("Expected the original event to be unchanged, got %v", evt.Metadata)
+	}
+}
+
+func TestWrap_Veto(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.emit.vetoed"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	var outcome Outcome
+	inner := &recorder{}
+	e := Wrap(inner, WithBus(eng.ExternalBus()), With(
+		Observe(nil, func(o Outcome) { outcome = o }),
+		Policy(func(ctx context.Context, evt *event.Event) error {
+			if evt.Metadata["tenant"] == "blocked" {
+				return errors.New("tenant suspended")
+			}
+			return nil
+		}),
+	))
+
+	evt, _ := codec.NewEvent("test.message", "test", message{Text: "hi"}, codec.JSON{})
+	evt.WithMetadata("tenant", "blocked")
+	err = e.Emit(context.Background(), evt)
+
+	var veto *Veto
+	if !errors.As(err, &veto) || veto.Reason != "tenant suspended" {
+		t.Fatalf("Expected a veto, got %v", err)
+	}
+	if len(inner.events) != 0 {
+		t.Error("Expected the vetoed event not to be delivered")
+	}
+	if !errors.Is(outcome.Err, err) {
+		t.Errorf("Expected the observer to see the veto, got %v", outcome.Err)
+	}
+
+	select {
+	case vetoEvt := <-sub.Events():
+		var payload VetoPayload
+		if err := codec.Decode(vetoEvt, &payload); err != nil {
+			t.Fatalf("Failed to decode veto: %v", err)
+		}
+		if payload.EventID != evt.ID || payload.EmitterID != "recorder" || payload.Reason != "tenant suspended" {
+			t.Errorf("Unexpected veto payload: %+v", payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for net.emit.vetoed")
+	}
+}
+
+func TestObserve(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	failure := errors.New("connection refused")
+	inner := &recorder{clk: clk, err: failure}
+
+	var outcomes []Outcome
+	e := Wrap(inner, With(Observe(clk, func(o Outcome) { outcomes = append(outcomes, o) })))
+
+	evt, _ := codec.NewEvent("test.message", "test", message{Text: "hi"}, codec.JSON{})
+	if err := e.Emit(context.Background(), evt); !errors.Is(err, failure) {
+		t.Fatalf("Expected the emitter error, got %v", err)
+	}
+
+	if len(outcomes) != 1 {
+		t.Fatalf("Expected one outcome, got %d", len(outcomes))
+	}
+	if outcomes[0].Latency != 5*time.Millisecond || !errors.Is(outcomes[0].Err, failure) || outcomes[0].Event.ID != evt.ID {
+		t.Errorf("Unexpected outcome: %+v", outcomes[0])
+	}
+}
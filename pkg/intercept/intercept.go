@@ -0,0 +1,99 @@
+// Package intercept defines interceptors, which run inside an adapter between
+// receiving a message and publishing it as an event.
+//
+// An interceptor sees the decoded payload and the draft event. It can enrich
+// or change them, answer the message directly, or reject it with a reply.
+// Interceptors are protocol-neutral. Each adapter maps a Reply onto its
+// protocol, e.g. an HTTP status, headers and body.
+//
+// On the outbound side, Wrap decorates any emitter with EmitInterceptors.
+// These can change an event before delivery, veto it, and observe the
+// outcome.
+package intercept
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// Call is a message an adapter is about to publish
+type Call struct {
+	Protocol  string       // Adapter protocol, e.g. http
+	AdapterID string       // Adapter that received the message
+	Payload   any          // Decoded payload, a pointer such as *http.HTTPRequestPayload
+	Event     *event.Event // Draft event, published once the chain passes it
+}
+
+// Reply is an answer sent by the adapter instead of publishing the event
+type Reply struct {
+	Code    int               // Protocol status, e.g. an HTTP status code; 0 for the adapter default
+	Headers map[string]string // Protocol headers, where the protocol has them
+	Body    []byte
+}
+
+// Interceptor inspects a call before it is published. It returns a non-nil
+// Reply to answer the call directly, a Rejection (or any other error) to
+// refuse it, or neither to pass it on.
+type Interceptor interface {
+	Intercept(ctx context.Context, call *Call) (*Reply, error)
+}
+
+// Func adapts a function to an Interceptor
+type Func func(ctx context.Context, call *Call) (*Reply, error)
+
+// Intercept calls f
+func (f Func) Intercept(ctx context.Context, call *Call) (*Reply, error) {
+	return f(ctx, call)
+}
+
+// Rejection is an error that refuses a call with a reply, such as a 401 or
+// 429 for HTTP. Adapters treat other errors as interceptor failures.
+type Rejection struct {
+	Reply  Reply
+	Reason string
+}
+
+// Reject returns a Rejection with the given protocol status and reason
+func Reject(code int, reason string) *Rejection {
+	return &Rejection{Reply: Reply{Code: code, Body: []byte(reason)}, Reason: reason}
+}
+
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("rejected (%d): %s", r.Reply.Code, r.Reason)
+}
+
+// Chain is an ordered list of interceptors
+type Chain []Interceptor
+
+// Run passes call through each interceptor in order, stopping at the first
+// that replies or fails. When the call passes, the payload is encoded into the
+// event again (with the codec named in its metadata), so that changes made by
+// interceptors are published.
+func (c Chain) Run(ctx context.Context, call *Call) (*Reply, error) {
+	for _, in := range c {
+		reply, err := in.Intercept(ctx, call)
+		if err != nil {
+			return nil, err
+		}
+		if reply != nil {
+			return reply, nil
+		}
+	}
+
+	if len(c) == 0 || call.Payload == nil {
+		return nil, nil
+	}
+	cdc, err := codec.ForEvent(call.Event, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode intercepted payload: %w", err)
+	}
+	data, err := cdc.Marshal(call.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode intercepted payload: %w", err)
+	}
+	call.Event.Data = data
+	return nil, nil
+}
@@ -0,0 +1,90 @@
+package intercept
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+)
+
+type message struct {
+	Text string `json:"text"`
+}
+
+func newCall(t *testing.T, text string) *Call {
+	t.Helper()
+	payload := &message{Text: text}
+	evt, err := codec.NewEvent("test.message", "test", payload, codec.CBOR{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	return &Call{Protocol: "test", AdapterID: "test", Payload: payload, Event: evt}
+}
+
+func TestChain_Run(t *testing.T) {
+	var order []string
+	chain := Chain{
+		Func(func(ctx context.Context, call *Call) (*Reply, error) {
+			order = append(order, "enrich")
+			call.Event.WithMetadata("tenant", "acme")
+			return nil, nil
+		}),
+		Func(func(ctx context.Context, call *Call) (*Reply, error) {
+			order = append(order, "rewrite")
+			call.Payload.(*message).Text = "rewritten"
+			return nil, nil
+		}),
+	}
+
+	call := newCall(t, "original")
+	reply, err := chain.Run(context.Background(), call)
+	if reply != nil || err != nil {
+		t.Fatalf("Expected the call to pass, got %v, %v", reply, err)
+	}
+	if len(order) != 2 || order[0] != "enrich" || order[1] != "rewrite" {
+		t.Errorf("Expected interceptors in order, got %v", order)
+	}
+	if call.Event.Metadata["tenant"] != "acme" {
+		t.Errorf("Expected enriched metadata, got %v", call.Event.Metadata)
+	}
+
+	// The payload is encoded again with the event's codec
+	var got message
+	if err := codec.Decode(call.Event, &got); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if got.Text != "rewritten" {
+		t.Errorf("Expected the rewritten payload, got %q", got.Text)
+	}
+}
+
+func TestChain_Stops(t *testing.T) {
+	var reached bool
+	last := Func(func(ctx context.Context, call *Call) (*Reply, error) {
+		reached = true
+		return nil, nil
+	})
+
+	cached := Func(func(ctx context.Context, call *Call) (*Reply, error) {
+		return &Reply{Body: []byte("cached")}, nil
+	})
+	reply, err := Chain{cached, last}.Run(context.Background(), newCall(t, "a"))
+	if err != nil || reply == nil || string(reply.Body) != "cached" {
+		t.Errorf("Expected the direct reply, got %v, %v", reply, err)
+	}
+
+	limited := Func(func(ctx context.Context, call *Call) (*Reply, error) {
+		return nil, Reject(http.StatusTooManyRequests, "slow down")
+	})
+	_, err = Chain{limited, last}.Run(context.Background(), newCall(t, "b"))
+	var rejection *Rejection
+	if !errors.As(err, &rejection) || rejection.Reply.Code != http.StatusTooManyRequests || string(rejection.Reply.Body) != "slow down" {
+		t.Errorf("Expected a 429 rejection, got %v", err)
+	}
+
+	if reached {
+		t.Error("Expected the chain to stop at the first reply or rejection")
+	}
+}
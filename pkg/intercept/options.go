@@ -0,0 +1,63 @@
+package intercept
+
+import (
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// Option configures an Emitter
+type Option func(*options)
+
+// options holds the Emitter settings
+type options struct {
+	interceptors []EmitInterceptor
+	bus          event.Bus
+	codec        codec.Codec
+	clk          clock.Clock
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		codec: codec.JSON{},
+		clk:   clock.System(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// With adds interceptors; they run in the order added, the first outermost
+func With(interceptors ...EmitInterceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithBus sets the bus "net.emit.vetoed" events are published to. Without a
+// bus, vetoes are only returned from Emit.
+func WithBus(bus event.Bus) Option {
+	return func(o *options) {
+		o.bus = bus
+	}
+}
+
+// WithClock sets the clock veto events are timestamped with
+func WithClock(clk clock.Clock) Option {
+	return func(o *options) {
+		if clk != nil {
+			o.clk = clk
+		}
+	}
+}
+
+// WithCodec sets the codec veto events are encoded with
+func WithCodec(c codec.Codec) Option {
+	return func(o *options) {
+		if c != nil {
+			o.codec = c
+		}
+	}
+}
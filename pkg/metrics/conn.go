@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net"
+	"sync"
+)
+
+// Conn is a net.Conn counted in Counters: it is an active connection from
+// NewConn until the first Close, and the bytes read and written on it are
+// added to the totals
+type Conn struct {
+	net.Conn
+	stats     *Counters
+	closeOnce sync.Once
+}
+
+// NewConn wraps nc so it is counted in stats
+func NewConn(nc net.Conn, stats *Counters) *Conn {
+	stats.Opened()
+	return &Conn{Conn: nc, stats: stats}
+}
+
+// Read reads from the connection, counting the bytes received
+func (c *Conn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.stats.Received(n)
+	return n, err
+}
+
+// Write writes to the connection, counting the bytes sent
+func (c *Conn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.stats.Sent(n)
+	return n, err
+}
+
+// Close closes the connection; only the first call counts it as closed
+func (c *Conn) Close() error {
+	c.closeOnce.Do(c.stats.Closed)
+	return c.Conn.Close()
+}
+
+// Listener is a net.Listener whose accepted connections are counted in
+// Counters (see Conn)
+type Listener struct {
+	net.Listener
+	stats *Counters
+}
+
+// NewListener wraps ln so the connections it accepts are counted in stats
+func NewListener(ln net.Listener, stats *Counters) *Listener {
+	return &Listener{Listener: ln, stats: stats}
+}
+
+// Accept waits for the next connection and wraps it in a Conn
+func (l *Listener) Accept() (net.Conn, error) {
+	nc, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(nc, l.stats), nil
+}
@@ -0,0 +1,146 @@
+// Package metrics counts traffic in network adapters and emitters and
+// publishes periodic "net.metrics" snapshots.
+//
+// Every adapter and emitter in netadapters keeps Counters and reports them
+// through a Metrics method (the Source interface). A Reporter, registered
+// like any other adapter, publishes a snapshot of each source on an interval.
+package metrics
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NetworkMetrics represents a "net.metrics" event. Counters are totals since
+// the component was created; ConnectionCount is the current number of open
+// connections.
+type NetworkMetrics struct {
+	// Identity
+	AdapterID string `json:"adapter_id"` // Adapter or emitter ID
+	Type      string `json:"type"`       // Adapter or emitter type, e.g. http-server
+	Protocol  string `json:"protocol"`   // http, websocket, tcp, udp, mqtt or grpc
+
+	// Counters
+	ConnectionCount int64  `json:"connection_count"` // Active connections
+	BytesReceived   uint64 `json:"bytes_received"`   // Total bytes in
+	BytesSent       uint64 `json:"bytes_sent"`       // Total bytes out
+	ErrorCount      uint64 `json:"error_count"`      // Errors encountered
+
+	// Latency by stage (e.g. "read", "pipeline", "write"), for components that time their work
+	Latency map[string]LatencyStats `json:"latency,omitempty"`
+
+	// Requests held open for a reply, for components that correlate replies
+	Replies *ReplyStats `json:"replies,omitempty"`
+
+	// Metadata
+	IntervalNs int64     `json:"interval_ns"` // Time since the previous snapshot, 0 for the first
+	Timestamp  time.Time `json:"timestamp"`   // When taken
+}
+
+// LatencyStats summarizes the durations observed for one stage
+type LatencyStats struct {
+	Count   uint64 `json:"count"`
+	TotalNs int64  `json:"total_ns"` // Sum of durations; TotalNs / Count is the mean
+	MaxNs   int64  `json:"max_ns"`
+}
+
+// ReplyStats counts the requests a component has held open for a reply
+// since it started (see package correlate)
+type ReplyStats struct {
+	Pending    int          `json:"pending"`    // Registered and not yet completed
+	Registered uint64       `json:"registered"` // Entries registered
+	Completed  uint64       `json:"completed"`  // Claimed and finished without error
+	Failed     uint64       `json:"failed"`     // Claimed and finished with an error
+	TimedOut   uint64       `json:"timed_out"`  // Deadline passed before a claim
+	Canceled   uint64       `json:"canceled"`   // Canceled before a claim
+	Unmatched  uint64       `json:"unmatched"`  // Replies for IDs that were not pending (late or unknown)
+	Latency    LatencyStats `json:"latency"`    // Registration to claim, for claimed entries
+}
+
+// Source is an adapter or emitter that reports its counters
+type Source interface {
+	ID() string
+	Metrics() NetworkMetrics
+}
+
+// Counters holds a component's traffic counters. The zero value is ready to
+// use and all methods are safe for concurrent use.
+type Counters struct {
+	connections atomic.Int64
+	bytesIn     atomic.Uint64
+	bytesOut    atomic.Uint64
+	errors      atomic.Uint64
+
+	latencyMu sync.Mutex
+	latency   map[string]LatencyStats
+}
+
+// Opened records a new connection
+func (c *Counters) Opened() {
+	c.connections.Add(1)
+}
+
+// Closed records a closed connection
+func (c *Counters) Closed() {
+	c.connections.Add(-1)
+}
+
+// Received adds n bytes in
+func (c *Counters) Received(n int) {
+	if n > 0 {
+		c.bytesIn.Add(uint64(n))
+	}
+}
+
+// Sent adds n bytes out
+func (c *Counters) Sent(n int) {
+	if n > 0 {
+		c.bytesOut.Add(uint64(n))
+	}
+}
+
+// Error records a failure
+func (c *Counters) Error() {
+	c.errors.Add(1)
+}
+
+// Observe records a duration for stage
+func (c *Counters) Observe(stage string, d time.Duration) {
+	c.latencyMu.Lock()
+	defer c.latencyMu.Unlock()
+
+	if c.latency == nil {
+		c.latency = make(map[string]LatencyStats)
+	}
+	stats := c.latency[stage]
+	stats.Count++
+	stats.TotalNs += d.Nanoseconds()
+	stats.MaxNs = max(stats.MaxNs, d.Nanoseconds())
+	c.latency[stage] = stats
+}
+
+// Snapshot returns the current counters for the component id, taken at the
+// given time (the component's clock reading)
+func (c *Counters) Snapshot(id, typ, protocol string, at time.Time) NetworkMetrics {
+	return NetworkMetrics{
+		AdapterID:       id,
+		Type:            typ,
+		Protocol:        protocol,
+		ConnectionCount: c.connections.Load(),
+		BytesReceived:   c.bytesIn.Load(),
+		BytesSent:       c.bytesOut.Load(),
+		ErrorCount:      c.errors.Load(),
+		Latency:         c.latencySnapshot(),
+		Timestamp:       at,
+	}
+}
+
+// latencySnapshot copies the latency stats, or returns nil if none were observed
+func (c *Counters) latencySnapshot() map[string]LatencyStats {
+	c.latencyMu.Lock()
+	defer c.latencyMu.Unlock()
+
+	return maps.Clone(c.latency)
+}
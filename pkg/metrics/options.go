@@ -0,0 +1,31 @@
+package metrics
+
+import "github.com/BYTE-6D65/netadapters/pkg/codec"
+
+// Option configures a Reporter
+type Option func(*options)
+
+// options holds the Reporter settings
+type options struct {
+	codec codec.Codec
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		codec: codec.JSON{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCodec sets the codec snapshots are encoded with
+func WithCodec(c codec.Codec) Option {
+	return func(o *options) {
+		if c != nil {
+			o.codec = c
+		}
+	}
+}
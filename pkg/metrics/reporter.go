@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// Reporter is an adapter that publishes a "net.metrics" event for each of
+// its sources every interval. Register it with the adapter manager alongside
+// the adapters it reports on; emitters are added the same way.
+type Reporter struct {
+	id       string
+	interval time.Duration
+	opts     options
+
+	mu      sync.Mutex
+	sources []Source
+	running bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewReporter creates a reporter publishing every interval
+func NewReporter(interval time.Duration, opts ...Option) *Reporter {
+	return &Reporter{
+		id:       "metrics-reporter",
+		interval: interval,
+		opts:     newOptions(opts),
+	}
+}
+
+// ID returns the adapter's unique identifier
+func (r *Reporter) ID() string {
+	return r.id
+}
+
+// Type returns the adapter type
+func (r *Reporter) Type() string {
+	return "metrics-reporter"
+}
+
+// Add registers sources to report on; it may be called while running
+func (r *Reporter) Add(sources ...Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sources = append(r.sources, sources...)
+}
+
+// Start begins publishing snapshots, ticking and timestamping with clk (see clock.From)
+func (r *Reporter) Start(ctx context.Context, bus event.Bus, clk pipeclock.Clock) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return fmt.Errorf("adapter already running")
+	}
+	if r.interval <= 0 {
+		return fmt.Errorf("metrics interval must be positive, got %v", r.interval)
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.run(ctx, bus, clock.From(clk), r.done)
+
+	r.running = true
+	return nil
+}
+
+// run publishes a snapshot of every source on each tick
+func (r *Reporter) run(ctx context.Context, bus event.Bus, clk clock.Clock, done chan struct{}) {
+	defer close(done)
+
+	ticker := clk.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	last := clk.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			interval := clk.Since(last)
+			last = clk.Now()
+
+			r.mu.Lock()
+			sources := append([]Source(nil), r.sources...)
+			r.mu.Unlock()
+
+			for _, src := range sources {
+				snapshot := src.Metrics()
+				snapshot.IntervalNs = interval.Nanoseconds()
+				snapshot.Timestamp = clk.Wall()
+				r.publish(ctx, bus, snapshot)
+			}
+		}
+	}
+}
+
+// publish encodes a snapshot with the reporter codec and publishes it
+func (r *Reporter) publish(ctx context.Context, bus event.Bus, snapshot NetworkMetrics) {
+	evt, err := codec.NewEvent("net.metrics", r.id, snapshot, r.opts.codec)
+	if err != nil {
+		return
+	}
+	evt.WithMetadata("reporter_id", r.id).
+		WithMetadata("adapter_id", snapshot.AdapterID).
+		WithMetadata("protocol", snapshot.Protocol)
+
+	bus.Publish(ctx, evt)
+}
+
+// Stop stops publishing
+func (r *Reporter) Stop() error {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return nil
+	}
+	r.running = false
+	r.cancel()
+	done := r.done
+	r.mu.Unlock()
+
+	<-done
+	return nil
+}
@@ -0,0 +1,178 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// source is a Source backed by plain Counters
+type source struct {
+	id    string
+	stats Counters
+}
+
+func (s *source) ID() string {
+	return s.id
+}
+
+func (s *source) Metrics() NetworkMetrics {
+	return s.stats.Snapshot(s.id, "test-source", "tcp", time.Now())
+}
+
+func TestCounters(t *testing.T) {
+	var c Counters
+	c.Opened()
+	c.Opened()
+	c.Closed()
+	c.Received(100)
+	c.Received(-1) // Failed reads report n <= 0
+	c.Sent(40)
+	c.Error()
+
+	m := c.Snapshot("a", "test", "udp", time.Now())
+	if m.AdapterID != "a" || m.Type != "test" || m.Protocol != "udp" {
+		t.Errorf("Unexpected identity: %+v", m)
+	}
+	if m.ConnectionCount != 1 || m.BytesReceived != 100 || m.BytesSent != 40 || m.ErrorCount != 1 {
+		t.Errorf("Unexpected counters: %+v", m)
+	}
+	if m.Latency != nil {
+		t.Errorf("Expected no latency before any observation, got %v", m.Latency)
+	}
+
+	c.Observe("write", 3*time.Millisecond)
+	c.Observe("write", time.Millisecond)
+	want := LatencyStats{Count: 2, TotalNs: int64(4 * time.Millisecond), MaxNs: int64(3 * time.Millisecond)}
+	if got := c.Snapshot("a", "test", "udp", time.Now()).Latency["write"]; got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestConn(t *testing.T) {
+	var c Counters
+	client, server := net.Pipe()
+	conn := NewConn(server, &c)
+
+	go client.Write([]byte("hello"))
+	buf := make([]byte, 16)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	go client.Read(buf)
+	if _, err := conn.Write(buf[:n]); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+
+	if m := c.Snapshot("", "", "", time.Now()); m.ConnectionCount != 1 || m.BytesReceived != 5 || m.BytesSent != 5 {
+		t.Errorf("Unexpected counters while open: %+v", m)
+	}
+	conn.Close()
+	conn.Close()
+	if m := c.Snapshot("", "", "", time.Now()); m.ConnectionCount != 0 {
+		t.Errorf("Expected no open connections after Close, got %d", m.ConnectionCount)
+	}
+}
+
+func TestReporter(t *testing.T) {
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.metrics"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+
+	src := &source{id: "tcp-listener-test"}
+	src.stats.Opened()
+	src.stats.Received(512)
+
+	reporter := NewReporter(20 * time.Millisecond)
+	reporter.Add(src)
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(reporter); err != nil {
+		t.Fatalf("Failed to register reporter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	t.Cleanup(func() { adapterMgr.Stop() })
+
+	select {
+	case evt := <-sub.Events():
+		var m NetworkMetrics
+		if err := codec.Decode(evt, &m); err != nil {
+			t.Fatalf("Failed to decode metrics: %v", err)
+		}
+		if m.AdapterID != src.id || m.Protocol != "tcp" {
+			t.Errorf("Unexpected identity: %+v", m)
+		}
+		if m.ConnectionCount != 1 || m.BytesReceived != 512 {
+			t.Errorf("Unexpected counters: %+v", m)
+		}
+		if m.IntervalNs <= 0 {
+			t.Errorf("Expected a positive interval, got %d", m.IntervalNs)
+		}
+		if evt.Metadata["adapter_id"] != src.id || evt.Metadata["reporter_id"] != reporter.ID() {
+			t.Errorf("Unexpected metadata: %v", evt.Metadata)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for net.metrics")
+	}
+}
+
+func TestReporter_FakeClock(t *testing.T) {
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.metrics"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	reporter := NewReporter(time.Minute)
+	reporter.Add(&source{id: "udp-listener-test"})
+	if err := reporter.Start(context.Background(), eng.ExternalBus(), clk); err != nil {
+		t.Fatalf("Failed to start reporter: %v", err)
+	}
+	t.Cleanup(func() { reporter.Stop() })
+
+	// A minute passes without waiting for it
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+
+	select {
+	case evt := <-sub.Events():
+		var m NetworkMetrics
+		if err := codec.Decode(evt, &m); err != nil {
+			t.Fatalf("Failed to decode metrics: %v", err)
+		}
+		if m.IntervalNs != time.Minute.Nanoseconds() {
+			t.Errorf("Expected a one minute interval, got %v", time.Duration(m.IntervalNs))
+		}
+		if !m.Timestamp.Equal(start.Add(time.Minute)) {
+			t.Errorf("Expected timestamp %v, got %v", start.Add(time.Minute), m.Timestamp)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for net.metrics")
+	}
+}
+
+func TestReporter_RejectsInterval(t *testing.T) {
+	reporter := NewReporter(0)
+	if err := reporter.Start(context.Background(), nil, nil); err == nil {
+		t.Fatal("Expected an error for a zero interval")
+	}
+}
@@ -0,0 +1,98 @@
+package mqtt
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ErrBadCredentials rejects a connection with "bad username or password"
+var ErrBadCredentials = errors.New("mqtt bad username or password")
+
+// ConnectInfo describes a client asking to connect to a BrokerAdapter
+type ConnectInfo struct {
+	ClientID        string
+	Username        string
+	Password        []byte
+	RemoteAddr      string
+	ProtocolVersion byte
+}
+
+// Authenticator decides whether a client may connect to a BrokerAdapter.
+// A non-nil error rejects the connection.
+type Authenticator interface {
+	Authenticate(info ConnectInfo) error
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface
+type AuthenticatorFunc func(info ConnectInfo) error
+
+// Authenticate calls f(info)
+func (f AuthenticatorFunc) Authenticate(info ConnectInfo) error {
+	return f(info)
+}
+
+// Access is the set of operations an ACL rule allows
+type Access int
+
+const (
+	// AccessNone denies publishing and subscribing
+	AccessNone Access = 0
+	// AccessSubscribe allows subscribing to topics under the prefix
+	AccessSubscribe Access = 1 << 0
+	// AccessPublish allows publishing to topics under the prefix
+	AccessPublish Access = 1 << 1
+	// AccessAll allows publishing and subscribing
+	AccessAll = AccessSubscribe | AccessPublish
+)
+
+// aclRule grants access to topics under a prefix
+type aclRule struct {
+	prefix    string
+	access    Access
+	users     []string // Authenticated usernames; empty means everyone
+	clientIDs []string // Client IDs; empty means everyone
+}
+
+// allowed reports whether the client may perform need on topic, a topic
+// name or (for subscriptions) a topic filter. Filters are matched by their
+// literal text, so "devices/#" is not covered by a "devices/1/" rule.
+func allowed(rules []aclRule, clientID, username, topic string, need Access) bool {
+	if len(rules) == 0 {
+		return true
+	}
+
+	best, bestLen := AccessNone, -1
+	for _, rule := range rules {
+		if len(rule.users) > 0 && !slices.Contains(rule.users, username) {
+			continue
+		}
+		if len(rule.clientIDs) > 0 && !slices.Contains(rule.clientIDs, clientID) {
+			continue
+		}
+		prefix, ok := expandPrefix(rule.prefix, clientID, username)
+		if !ok || !strings.HasPrefix(topic, prefix) || len(prefix) < bestLen {
+			continue
+		}
+		best, bestLen = rule.access, len(prefix)
+	}
+	return best&need == need
+}
+
+// expandPrefix substitutes "%c" and "%u". Identities that contain topic
+// separators, wildcards or "%" cannot be substituted, so the rule does not apply.
+func expandPrefix(prefix, clientID, username string) (string, bool) {
+	if !strings.Contains(prefix, "%") {
+		return prefix, true
+	}
+	for placeholder, value := range map[string]string{"%c": clientID, "%u": username} {
+		if !strings.Contains(prefix, placeholder) {
+			continue
+		}
+		if value == "" || strings.ContainsAny(value, "/+#%") {
+			return "", false
+		}
+		prefix = strings.ReplaceAll(prefix, placeholder, value)
+	}
+	return prefix, true
+}
@@ -0,0 +1,42 @@
+package mqtt
+
+import "testing"
+
+func TestAllowed(t *testing.T) {
+	rules := []aclRule{
+		{prefix: "devices/%c/", access: AccessAll},
+		{prefix: "commands/", access: AccessSubscribe},
+		{prefix: "commands/", access: AccessAll, users: []string{"operator"}},
+		{prefix: "devices/", access: AccessSubscribe, users: []string{"operator"}},
+		{prefix: "gateway/", access: AccessPublish, clientIDs: []string{"gw-1"}},
+	}
+
+	tests := []struct {
+		clientID, username, topic string
+		need                      Access
+		want                      bool
+	}{
+		{"dev-1", "", "devices/dev-1/temp", AccessPublish, true},
+		{"dev-1", "", "devices/dev-2/temp", AccessPublish, false},
+		{"dev-1", "", "commands/dev-1", AccessSubscribe, true},
+		{"dev-1", "", "commands/dev-1", AccessPublish, false},
+		{"console", "operator", "commands/dev-1", AccessPublish, true},
+		{"console", "operator", "devices/dev-1/temp", AccessSubscribe, true},
+		{"dev-1", "", "other/topic", AccessSubscribe, false},
+		{"a/b", "", "devices/a/b/temp", AccessPublish, false},    // IDs with separators never expand
+		{"operator", "", "commands/dev-1", AccessPublish, false}, // A client ID is not a username
+		{"operator", "guest", "commands/dev-1", AccessPublish, false},
+		{"gw-1", "", "gateway/status", AccessPublish, true},
+		{"gw-2", "gw-1", "gateway/status", AccessPublish, false}, // Nor a username a client ID
+	}
+
+	for _, tt := range tests {
+		if got := allowed(rules, tt.clientID, tt.username, tt.topic, tt.need); got != tt.want {
+			t.Errorf("allowed(%q, %q, %q, %d) = %v, want %v", tt.clientID, tt.username, tt.topic, tt.need, got, tt.want)
+		}
+	}
+
+	if !allowed(nil, "dev-1", "", "anything", AccessAll) {
+		t.Error("Expected everything to be allowed without rules")
+	}
+}
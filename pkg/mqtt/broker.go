@@ -0,0 +1,823 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/google/uuid"
+)
+
+// BrokerAdapter runs an MQTT broker inside the process. Devices connect
+// with MQTT 3.1.1 or 5.0; every message they publish is routed to the
+// subscribed devices and published as a "net.mqtt.message" event, and
+// client connects and disconnects are published as "net.mqtt.connect" and
+// "net.mqtt.disconnect" events. Pipeline messages reach devices through
+// Deliver or a BrokerEmitter.
+//
+// Sessions, QoS 1/2 queues and retained messages live in memory only.
+// Shared subscriptions and topic aliases are not supported.
+type BrokerAdapter struct {
+	id   string
+	addr string
+	ln   net.Listener
+	bus  event.Bus
+	clk  clock.Clock
+	ctx  context.Context
+	opts options
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	stats  metrics.Counters
+
+	// state guards sessions, retained, conns and each session's conn and subs
+	state    sync.Mutex
+	sessions map[string]*clientSession
+	retained map[string]*publishPacket
+	conns    map[net.Conn]struct{}
+	dropped  atomic.Uint64
+
+	mu      sync.Mutex
+	running bool
+}
+
+// clientSession is the broker's state for one client identifier
+type clientSession struct {
+	id     string
+	conn   *clientConn // nil while offline
+	subs   map[string]subscription
+	expiry time.Duration // How long the session outlives its connection; negative never expires
+	expire func()        // Cancels the pending expiry, nil when none
+
+	mu       sync.Mutex // Guards out and received
+	out      *outbox
+	received map[uint16]bool // Inbound QoS 2 IDs awaiting PUBREL
+}
+
+// clientConn is one connected client
+type clientConn struct {
+	nc         net.Conn
+	version    byte
+	clientID   string
+	username   string
+	remoteAddr string
+	will       *willMessage
+	takenOver  bool // Set under the broker's state lock
+
+	writeTimeout time.Duration
+	writeMu      sync.Mutex
+}
+
+// NewBrokerAdapter creates a broker listening on addr (e.g. ":1883")
+func NewBrokerAdapter(addr string, opts ...Option) *BrokerAdapter {
+	return &BrokerAdapter{
+		id:       fmt.Sprintf("mqtt-broker-%s", addr),
+		addr:     addr,
+		clk:      clock.System(),
+		opts:     newOptions(opts),
+		sessions: make(map[string]*clientSession),
+		retained: make(map[string]*publishPacket),
+	}
+}
+
+// ID returns the adapter's unique identifier
+func (a *BrokerAdapter) ID() string {
+	return a.id
+}
+
+// Type returns the adapter type
+func (a *BrokerAdapter) Type() string {
+	return "mqtt-broker"
+}
+
+// Start begins accepting client connections. Timestamps and session expiry
+// are taken from clk (see clock.From).
+func (a *BrokerAdapter) Start(ctx context.Context, bus event.Bus, clk pipeclock.Clock) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		return fmt.Errorf("adapter already running")
+	}
+
+	ln, err := net.Listen("tcp", a.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.addr, err)
+	}
+	ln = metrics.NewListener(ln, &a.stats)
+	if a.opts.tlsConfig != nil {
+		ln = tls.NewListener(ln, a.opts.tlsConfig)
+	}
+
+	a.ln = ln
+	a.bus = bus
+	a.clk = clock.From(clk)
+	a.conns = make(map[net.Conn]struct{})
+
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	a.wg.Add(1)
+	go a.acceptLoop()
+
+	a.running = true
+	return nil
+}
+
+// Stop closes the listener and every client connection. Sessions and
+// retained messages are kept for the next Start.
+func (a *BrokerAdapter) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.running {
+		return nil
+	}
+
+	a.cancel()
+	a.ln.Close()
+
+	a.state.Lock()
+	for _, sess := range a.sessions {
+		if sess.conn != nil {
+			sess.conn.kick(codeServerShuttingDown)
+		}
+	}
+	for nc := range a.conns {
+		nc.Close()
+	}
+	a.state.Unlock()
+
+	a.wg.Wait()
+
+	a.running = false
+	return nil
+}
+
+// Addr returns the listening address, or nil before Start
+func (a *BrokerAdapter) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ln == nil {
+		return nil
+	}
+	return a.ln.Addr()
+}
+
+// Dropped returns how many messages were discarded because a client
+// session's queue was full
+func (a *BrokerAdapter) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Metrics returns the broker's traffic counters. Bytes are counted on client
+// connections, including MQTT framing; errors are rejected connections,
+// connections that failed and messages dropped from full session queues.
+func (a *BrokerAdapter) Metrics() metrics.NetworkMetrics {
+	return a.stats.Snapshot(a.id, a.Type(), "mqtt", a.clk.Wall())
+}
+
+// Deliver routes msg to the subscribed devices as if a client had published
+// it, storing it as the topic's retained message when Retained is set.
+// Messages from the pipeline are not subject to the ACL.
+func (a *BrokerAdapter) Deliver(msg MQTTMessagePayload) error {
+	if !validTopic(msg.Topic) {
+		return fmt.Errorf("invalid topic %q", msg.Topic)
+	}
+	if msg.QoS > 2 {
+		return fmt.Errorf("invalid qos %d", msg.QoS)
+	}
+
+	a.route(publishPacketFor(msg), "")
+	return nil
+}
+
+// acceptLoop accepts connections until the listener is closed
+func (a *BrokerAdapter) acceptLoop() {
+	defer a.wg.Done()
+
+	for {
+		nc, err := a.ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) || a.ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		a.state.Lock()
+		a.conns[nc] = struct{}{}
+		a.state.Unlock()
+
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			a.serve(nc)
+
+			a.state.Lock()
+			delete(a.conns, nc)
+			a.state.Unlock()
+		}()
+	}
+}
+
+// serve runs one client connection from CONNECT to close
+func (a *BrokerAdapter) serve(nc net.Conn) {
+	defer nc.Close()
+
+	nc.SetDeadline(time.Now().Add(a.opts.connectTimeout))
+	br := bufio.NewReader(nc)
+	p, err := readPacket(br, ProtocolV311, a.opts.maxPacketSize)
+	if err != nil {
+		return
+	}
+	connect, ok := p.(*connectPacket)
+	if !ok {
+		return
+	}
+
+	conn := &clientConn{
+		nc:           nc,
+		version:      connect.version,
+		clientID:     connect.clientID,
+		username:     connect.username,
+		remoteAddr:   nc.RemoteAddr().String(),
+		will:         connect.will,
+		writeTimeout: a.opts.writeTimeout,
+	}
+
+	sess, ack, err := a.connect(conn, connect)
+	if err != nil {
+		a.stats.Error()
+		conn.write(ack)
+		a.clientEvent("net.mqtt.disconnect", conn, false, "rejected", err)
+		return
+	}
+	nc.SetDeadline(time.Time{})
+	if conn.write(ack) != nil {
+		a.disconnect(conn, sess)
+		return
+	}
+	a.clientEvent("net.mqtt.connect", conn, ack.sessionPresent, "", nil)
+
+	window := a.opts.maxInflight
+	if rm := connect.props.receiveMaximum; rm > 0 && int(rm) < window {
+		window = int(rm)
+	}
+	sess.mu.Lock()
+	sess.out.connect(conn.write, window, ack.sessionPresent)
+	sess.out.drain()
+	sess.mu.Unlock()
+
+	keepAlive := time.Duration(connect.keepAlive) * time.Second
+	err = a.readLoop(conn, sess, br, keepAlive)
+
+	reason := closeReason(err)
+	if a.disconnect(conn, sess) {
+		reason = "taken_over"
+	}
+	if a.ctx.Err() != nil {
+		reason = "shutdown"
+	} else if conn.will != nil {
+		a.publishWill(conn)
+	}
+	if reason == "disconnect" || reason == "taken_over" || reason == "shutdown" {
+		err = nil
+	} else if reason != "eof" {
+		a.stats.Error()
+	}
+	a.clientEvent("net.mqtt.disconnect", conn, false, reason, err)
+}
+
+// connect authenticates the client and attaches it to its session, taking
+// over any existing connection for the same client ID. On error the
+// returned CONNACK carries the refusal.
+func (a *BrokerAdapter) connect(conn *clientConn, p *connectPacket) (*clientSession, *connackPacket, error) {
+	ack := &connackPacket{}
+	if p.version != ProtocolV311 && p.version != ProtocolV5 {
+		conn.version = ProtocolV311
+		ack.code = codeUnsupportedVersion
+		return nil, ack, fmt.Errorf("unsupported protocol version %d", p.version)
+	}
+
+	if conn.clientID == "" {
+		if !p.cleanStart && p.version < ProtocolV5 {
+			ack.code = codeInvalidClientID
+			return nil, ack, fmt.Errorf("empty client ID requires a clean session")
+		}
+		conn.clientID = "auto-" + uuid.New().String()
+		ack.props.assignedClientID = conn.clientID
+	}
+	if p.will != nil && !validTopic(p.will.topic) {
+		ack.code = codeTopicNameInvalid
+		return nil, ack, fmt.Errorf("invalid will topic %q", p.will.topic)
+	}
+
+	if a.opts.auth != nil {
+		err := a.opts.auth.Authenticate(ConnectInfo{
+			ClientID:        conn.clientID,
+			Username:        conn.username,
+			Password:        p.password,
+			RemoteAddr:      conn.remoteAddr,
+			ProtocolVersion: conn.version,
+		})
+		if err != nil {
+			ack.code = codeNotAuthorized
+			if errors.Is(err, ErrBadCredentials) {
+				ack.code = codeBadCredentials
+			}
+			return nil, ack, err
+		}
+	}
+
+	// 3.1.1 sessions either end with the connection or never expire
+	var expiry time.Duration
+	switch {
+	case p.version >= ProtocolV5 && p.props.sessionExpiry == math.MaxUint32:
+		expiry = -1
+	case p.version >= ProtocolV5:
+		expiry = time.Duration(p.props.sessionExpiry) * time.Second
+	case !p.cleanStart:
+		expiry = -1
+	}
+
+	a.state.Lock()
+	defer a.state.Unlock()
+
+	sess, ok := a.sessions[conn.clientID]
+	if ok && sess.conn != nil {
+		sess.conn.takenOver = true
+		sess.conn.kick(codeSessionTakenOver)
+	}
+	if ok && sess.expire != nil {
+		sess.expire()
+		sess.expire = nil
+	}
+	if !ok || p.cleanStart {
+		sess = &clientSession{
+			id:       conn.clientID,
+			subs:     make(map[string]subscription),
+			out:      newOutbox(a.opts.queueSize),
+			received: make(map[uint16]bool),
+		}
+		a.sessions[conn.clientID] = sess
+		ok = false
+	}
+	sess.conn = conn
+	sess.expiry = expiry
+
+	noShared := byte(0)
+	ack.sessionPresent = ok
+	ack.props.receiveMaximum = uint16(a.opts.maxInflight)
+	ack.props.maximumPacketSize = uint32(a.opts.maxPacketSize)
+	ack.props.sharedAvailable = &noShared
+	return sess, ack, nil
+}
+
+// disconnect detaches conn from its session, discarding or scheduling the
+// expiry of the session. It reports whether conn was taken over.
+func (a *BrokerAdapter) disconnect(conn *clientConn, sess *clientSession) bool {
+	a.state.Lock()
+	defer a.state.Unlock()
+
+	if sess.conn != conn {
+		return conn.takenOver
+	}
+	sess.conn = nil
+
+	sess.mu.Lock()
+	sess.out.disconnect()
+	sess.mu.Unlock()
+
+	switch {
+	case sess.expiry == 0:
+		if a.sessions[sess.id] == sess {
+			delete(a.sessions, sess.id)
+		}
+	case sess.expiry > 0:
+		sess.expire = afterFunc(a.clk, sess.expiry, func() {
+			a.state.Lock()
+			defer a.state.Unlock()
+
+			if sess.conn == nil && a.sessions[sess.id] == sess {
+				delete(a.sessions, sess.id)
+			}
+		})
+	}
+	return conn.takenOver
+}
+
+// readLoop handles packets from a connected client until it disconnects.
+// A graceful DISCONNECT returns nil.
+func (a *BrokerAdapter) readLoop(conn *clientConn, sess *clientSession, br *bufio.Reader, keepAlive time.Duration) error {
+	for {
+		if keepAlive > 0 {
+			conn.nc.SetReadDeadline(time.Now().Add(keepAlive * 3 / 2))
+		}
+		p, err := readPacket(br, conn.version, a.opts.maxPacketSize)
+		if err != nil {
+			return err
+		}
+
+		switch p := p.(type) {
+		case *publishPacket:
+			err = a.handlePublish(conn, sess, p)
+		case *ackPacket:
+			sess.mu.Lock()
+			if p.kind == packetPubrel {
+				delete(sess.received, p.id)
+				err = conn.write(&ackPacket{kind: packetPubcomp, id: p.id})
+			} else {
+				err = sess.out.acknowledge(p)
+			}
+			sess.mu.Unlock()
+		case *subscribePacket:
+			err = a.handleSubscribe(conn, sess, p)
+		case *unsubscribePacket:
+			err = a.handleUnsubscribe(conn, sess, p)
+		case *pingPacket:
+			if p.kind == packetPingreq {
+				err = conn.write(&pingPacket{kind: packetPingresp})
+			}
+		case *disconnectPacket:
+			if p.code != codeDisconnectWithWill {
+				conn.will = nil
+			}
+			return nil
+		default:
+			return fmt.Errorf("%w: unexpected packet %T", errMalformed, p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handlePublish acknowledges and routes a client publish. Publishes the
+// ACL denies are acknowledged (with "not authorized" on 5.0) and dropped.
+func (a *BrokerAdapter) handlePublish(conn *clientConn, sess *clientSession, p *publishPacket) error {
+	if !validTopic(p.topic) {
+		return fmt.Errorf("%w: invalid topic %q", errMalformed, p.topic)
+	}
+	if p.qos > 2 {
+		return fmt.Errorf("%w: invalid qos %d", errMalformed, p.qos)
+	}
+
+	permitted := allowed(a.opts.acl, conn.clientID, conn.username, p.topic, AccessPublish)
+	code := codeSuccess
+	if !permitted {
+		code = codeNotAuthorized
+	}
+
+	switch p.qos {
+	case 0:
+		if permitted {
+			a.publish(conn.clientID, p)
+		}
+		return nil
+	case 1:
+		if permitted {
+			a.publish(conn.clientID, p)
+		}
+		return conn.write(&ackPacket{kind: packetPuback, id: p.id, code: code})
+	default:
+		// QoS 2 is routed once; retransmissions before PUBREL are only acknowledged
+		sess.mu.Lock()
+		seen := sess.received[p.id]
+		if permitted {
+			sess.received[p.id] = true
+		}
+		sess.mu.Unlock()
+
+		if permitted && !seen {
+			a.publish(conn.clientID, p)
+		}
+		return conn.write(&ackPacket{kind: packetPubrec, id: p.id, code: code})
+	}
+}
+
+// handleSubscribe adds subscriptions and sends matching retained messages
+func (a *BrokerAdapter) handleSubscribe(conn *clientConn, sess *clientSession, p *subscribePacket) error {
+	type pending struct {
+		msg *publishPacket
+		qos byte
+	}
+
+	codes := make([]byte, len(p.subs))
+	var retained []pending
+
+	a.state.Lock()
+	for i, sub := range p.subs {
+		switch {
+		case !validFilter(sub.filter):
+			codes[i] = codeTopicFilterInvalid
+		case strings.HasPrefix(sub.filter, "$share/"):
+			codes[i] = codeSharedNotSupported
+		case !allowed(a.opts.acl, conn.clientID, conn.username, sub.filter, AccessSubscribe):
+			codes[i] = codeNotAuthorized
+		default:
+			sub.qos = min(sub.qos, 2)
+			_, existed := sess.subs[sub.filter]
+			sess.subs[sub.filter] = sub
+			codes[i] = sub.qos
+
+			if sub.retainHandling == 0 || (sub.retainHandling == 1 && !existed) {
+				for topic, msg := range a.retained {
+					if matchTopic(sub.filter, topic) {
+						retained = append(retained, pending{msg: msg, qos: min(msg.qos, sub.qos)})
+					}
+				}
+			}
+		}
+	}
+	a.state.Unlock()
+
+	if err := conn.write(&subackPacket{kind: packetSuback, id: p.id, codes: codes}); err != nil {
+		return err
+	}
+
+	sort.Slice(retained, func(i, j int) bool {
+		return retained[i].msg.topic < retained[j].msg.topic
+	})
+	for _, r := range retained {
+		a.deliver(sess, r.msg, r.qos, true)
+	}
+	return nil
+}
+
+// handleUnsubscribe removes subscriptions
+func (a *BrokerAdapter) handleUnsubscribe(conn *clientConn, sess *clientSession, p *unsubscribePacket) error {
+	codes := make([]byte, len(p.filters))
+
+	a.state.Lock()
+	for i, filter := range p.filters {
+		if _, ok := sess.subs[filter]; ok {
+			delete(sess.subs, filter)
+			codes[i] = codeSuccess
+		} else {
+			codes[i] = codeNoSubscription
+		}
+	}
+	a.state.Unlock()
+
+	return conn.write(&subackPacket{kind: packetUnsuback, id: p.id, codes: codes})
+}
+
+// publish publishes a client's message as an event and routes it
+func (a *BrokerAdapter) publish(clientID string, p *publishPacket) {
+	payload := messagePayload(p, a.clk.Wall())
+	payload.ClientID = clientID
+
+	evt, err := codec.NewEvent("net.mqtt.message", a.id, payload, a.opts.codec)
+	if err == nil {
+		evt.WithMetadata("adapter_id", a.id).
+			WithMetadata("client_id", clientID).
+			WithMetadata("topic", p.topic)
+		a.bus.Publish(a.ctx, evt)
+	}
+
+	a.route(p, clientID)
+}
+
+// publishWill publishes the will of a client that disconnected ungracefully
+func (a *BrokerAdapter) publishWill(conn *clientConn) {
+	w := conn.will
+	if !allowed(a.opts.acl, conn.clientID, conn.username, w.topic, AccessPublish) {
+		return
+	}
+	a.publish(conn.clientID, &publishPacket{
+		topic:   w.topic,
+		payload: w.payload,
+		qos:     w.qos,
+		retain:  w.retain,
+		props:   w.props,
+	})
+}
+
+// route stores retained messages and delivers p to every session with a
+// matching subscription, once per session at the highest granted QoS.
+// from is the publishing client ID, "" for the pipeline.
+func (a *BrokerAdapter) route(p *publishPacket, from string) {
+	type target struct {
+		sess   *clientSession
+		qos    byte
+		retain bool
+	}
+	var targets []target
+
+	a.state.Lock()
+	if p.retain {
+		if len(p.payload) == 0 {
+			delete(a.retained, p.topic)
+		} else {
+			a.retained[p.topic] = p
+		}
+	}
+	for _, sess := range a.sessions {
+		matched := false
+		t := target{sess: sess}
+		for _, sub := range sess.subs {
+			if !matchTopic(sub.filter, p.topic) || (sub.noLocal && sess.id == from) {
+				continue
+			}
+			matched = true
+			t.qos = max(t.qos, sub.qos)
+			t.retain = t.retain || (sub.retainAsPublished && p.retain)
+		}
+		if matched {
+			t.qos = min(t.qos, p.qos)
+			targets = append(targets, t)
+		}
+	}
+	a.state.Unlock()
+
+	for _, t := range targets {
+		a.deliver(t.sess, p, t.qos, t.retain)
+	}
+}
+
+// deliver queues a copy of p for sess. QoS 0 messages are not kept for
+// offline sessions; messages beyond the session queue are dropped.
+func (a *BrokerAdapter) deliver(sess *clientSession, p *publishPacket, qos byte, retain bool) {
+	out := *p
+	out.id = 0
+	out.dup = false
+	out.qos = qos
+	out.retain = retain
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if qos == 0 && sess.out.send == nil {
+		return
+	}
+	if sess.out.push(&out) != nil {
+		a.dropped.Add(1)
+		a.stats.Error()
+	}
+}
+
+// clientEvent publishes a "net.mqtt.connect" or "net.mqtt.disconnect"
+// event. Events are published even after Stop so shutdown disconnects are
+// not lost.
+func (a *BrokerAdapter) clientEvent(eventType string, conn *clientConn, sessionPresent bool, reason string, err error) {
+	payload := MQTTClientPayload{
+		ClientID:        conn.clientID,
+		Username:        conn.username,
+		ProtocolVersion: conn.version,
+		SessionPresent:  sessionPresent,
+		Reason:          reason,
+		RemoteAddr:      conn.remoteAddr,
+		Timestamp:       a.clk.Wall(),
+	}
+	if err != nil {
+		payload.Error = err.Error()
+	}
+
+	evt, err := codec.NewEvent(eventType, a.id, payload, a.opts.codec)
+	if err != nil {
+		return
+	}
+	evt.WithMetadata("adapter_id", a.id).
+		WithMetadata("client_id", conn.clientID)
+
+	a.bus.Publish(context.WithoutCancel(a.ctx), evt)
+}
+
+// closeReason classifies why a client's read loop ended
+func closeReason(err error) string {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return "disconnect"
+	case errors.Is(err, io.EOF):
+		return "eof"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, errMalformed), errors.Is(err, ErrPacketTooLarge):
+		return "protocol_error"
+	default:
+		return "error"
+	}
+}
+
+// write sends p, closing the connection if the write fails
+func (c *clientConn) write(p packet) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.nc.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	if err := writePacket(c.nc, p, c.version); err != nil {
+		c.nc.Close()
+		return err
+	}
+	return nil
+}
+
+// kick closes the connection, telling 5.0 clients why
+func (c *clientConn) kick(code byte) {
+	if c.version >= ProtocolV5 {
+		c.write(&disconnectPacket{code: code})
+	}
+	c.nc.Close()
+}
+
+// BrokerEmitter delivers "net.mqtt.deliver" events to a BrokerAdapter's
+// subscribed devices. It consumes MQTTMessagePayload events; Topic, QoS
+// and Retained decide how each message is delivered.
+type BrokerEmitter struct {
+	id     string
+	broker *BrokerAdapter
+	opts   options
+	stats  metrics.Counters
+}
+
+// NewBrokerEmitter creates an emitter that delivers through broker
+func NewBrokerEmitter(broker *BrokerAdapter, opts ...Option) *BrokerEmitter {
+	return &BrokerEmitter{
+		id:     fmt.Sprintf("mqtt-broker-emitter-%s", broker.addr),
+		broker: broker,
+		opts:   newOptions(opts),
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *BrokerEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *BrokerEmitter) Type() string {
+	return "mqtt-broker"
+}
+
+// Emit delivers the event's message to subscribed devices. QoS 1/2
+// messages for offline persistent sessions are queued until they reconnect.
+func (e *BrokerEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var payload MQTTMessagePayload
+	if err := evt.DecodePayload(&payload, c); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if err := e.broker.Deliver(payload); err != nil {
+		return err
+	}
+	e.stats.Sent(len(payload.Payload))
+	return nil
+}
+
+// Metrics returns the emitter's counters: message payload bytes handed to the
+// broker and rejected events. Traffic to devices is counted by the broker.
+func (e *BrokerEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "mqtt", time.Now())
+}
+
+// Close is a no-op; the broker's lifetime belongs to the BrokerAdapter
+func (e *BrokerEmitter) Close() error {
+	return nil
+}
+
+// afterFunc calls f once d has passed on clk, unless the returned function
+// is called first
+func afterFunc(clk clock.Clock, d time.Duration, f func()) func() {
+	timer := clk.NewTimer(d)
+	cancel := make(chan struct{})
+	go func() {
+		select {
+		case <-timer.C():
+			f()
+		case <-cancel:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			timer.Stop()
+			close(cancel)
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package mqtt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// startBrokerAdapter runs a BrokerAdapter on a free port and subscribes to
+// its events
+func startBrokerAdapter(t *testing.T, opts ...Option) (*BrokerAdapter, event.Subscription) {
+	t.Helper()
+
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.mqtt.message", "net.mqtt.connect", "net.mqtt.disconnect"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+
+	adapter := NewBrokerAdapter("127.0.0.1:0", opts...)
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	t.Cleanup(func() { adapterMgr.Stop() })
+	return adapter, sub
+}
+
+func TestBrokerAdapter_PublishAndDeliver(t *testing.T) {
+	for _, version := range []byte{ProtocolV311, ProtocolV5} {
+		t.Run(map[byte]string{ProtocolV311: "v3.1.1", ProtocolV5: "v5"}[version], func(t *testing.T) {
+			broker, events := startBrokerAdapter(t)
+			addr := broker.Addr().String()
+
+			_, devSub := startSubscriber(t, addr, []string{"commands/dev-1/#"},
+				WithClientID("dev-1"),
+				WithProtocolVersion(version),
+				WithQoS(1),
+			)
+			var conn MQTTConnectionPayload
+			nextEvent(t, devSub, "net.mqtt.connected", &conn)
+
+			var client MQTTClientPayload
+			nextEvent(t, events, "net.mqtt.connect", &client)
+			if client.ClientID != "dev-1" || client.ProtocolVersion != version {
+				t.Errorf("Expected connect from dev-1, got %+v", client)
+			}
+
+			// Device to pipeline
+			publisher := NewPublisherEmitter(addr, WithClientID("dev-2"), WithProtocolVersion(version))
+			defer publisher.Close()
+			err := publisher.Emit(context.Background(), messageEvent(t, MQTTMessagePayload{
+				Topic:   "telemetry/dev-2",
+				Payload: []byte("21.5"),
+				QoS:     1,
+			}))
+			if err != nil {
+				t.Fatalf("Failed to emit: %v", err)
+			}
+
+			var msg MQTTMessagePayload
+			evt := nextEvent(t, events, "net.mqtt.message", &msg)
+			if msg.ClientID != "dev-2" || msg.Topic != "telemetry/dev-2" || string(msg.Payload) != "21.5" {
+				t.Errorf("Unexpected device message %+v", msg)
+			}
+			if evt.Metadata["client_id"] != "dev-2" {
+				t.Errorf("Expected client_id metadata, got %v", evt.Metadata)
+			}
+
+			// Pipeline to device
+			emitter := NewBrokerEmitter(broker)
+			deliver := messageEvent(t, MQTTMessagePayload{Topic: "commands/dev-1/reboot", Payload: []byte("now"), QoS: 1})
+			deliver.Type = "net.mqtt.deliver"
+			if err := emitter.Emit(context.Background(), deliver); err != nil {
+				t.Fatalf("Failed to deliver: %v", err)
+			}
+
+			nextEvent(t, devSub, "net.mqtt.message", &msg)
+			if msg.Topic != "commands/dev-1/reboot" || string(msg.Payload) != "now" || msg.QoS != 1 {
+				t.Errorf("Unexpected delivered message %+v", msg)
+			}
+		})
+	}
+}
+
+func TestBrokerAdapter_Authentication(t *testing.T) {
+	broker, events := startBrokerAdapter(t, WithAuthenticator(AuthenticatorFunc(func(info ConnectInfo) error {
+		if info.Username != "sensor" || string(info.Password) != "secret" {
+			return ErrBadCredentials
+		}
+		return nil
+	})))
+	addr := broker.Addr().String()
+
+	rejected := NewSubscriberAdapter(addr, nil, WithClientID("intruder"), WithCredentials("sensor", "guess"))
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+	if err := rejected.Start(context.Background(), eng.ExternalBus(), nil); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+
+	var client MQTTClientPayload
+	nextEvent(t, events, "net.mqtt.disconnect", &client)
+	rejected.Stop()
+	if client.ClientID != "intruder" || client.Reason != "rejected" || client.Error == "" {
+		t.Errorf("Expected rejected intruder, got %+v", client)
+	}
+
+	_, sub := startSubscriber(t, addr, nil, WithClientID("sensor-1"), WithCredentials("sensor", "secret"))
+	var conn MQTTConnectionPayload
+	nextEvent(t, sub, "net.mqtt.connected", &conn)
+
+	for {
+		nextEvent(t, events, "net.mqtt.connect", &client)
+		if client.ClientID == "sensor-1" {
+			break
+		}
+	}
+	if client.Username != "sensor" {
+		t.Errorf("Expected username sensor, got %+v", client)
+	}
+}
+
+func TestBrokerAdapter_ACL(t *testing.T) {
+	broker, events := startBrokerAdapter(t, WithACL("devices/%c/", AccessAll))
+	addr := broker.Addr().String()
+
+	publisher := NewPublisherEmitter(addr, WithClientID("dev-1"))
+	defer publisher.Close()
+
+	// QoS 1 keeps the two publishes in order; the denied one is dropped
+	for _, topic := range []string{"devices/dev-2/temp", "devices/dev-1/temp"} {
+		err := publisher.Emit(context.Background(), messageEvent(t, MQTTMessagePayload{Topic: topic, Payload: []byte("1"), QoS: 1}))
+		if err != nil {
+			t.Fatalf("Failed to emit: %v", err)
+		}
+	}
+
+	var msg MQTTMessagePayload
+	nextEvent(t, events, "net.mqtt.message", &msg)
+	if msg.Topic != "devices/dev-1/temp" {
+		t.Errorf("Expected only the device's own topic, got %q", msg.Topic)
+	}
+
+	// A rejected subscription drops the subscriber's connection
+	_, sub := startSubscriber(t, addr, []string{"devices/dev-1/#"}, WithClientID("dev-2"))
+	var conn MQTTConnectionPayload
+	nextEvent(t, sub, "net.mqtt.disconnected", &conn)
+	if conn.Error == "" {
+		t.Error("Expected subscription to another device's topics to be rejected")
+	}
+}
+
+func TestBrokerAdapter_ACLClientIDIsNotUsername(t *testing.T) {
+	broker, _ := startBrokerAdapter(t, WithACL("admin/", AccessAll, "admin"))
+
+	// A client that picks a privileged username as its ID gains nothing
+	_, sub := startSubscriber(t, broker.Addr().String(), []string{"admin/#"}, WithClientID("admin"))
+	var conn MQTTConnectionPayload
+	nextEvent(t, sub, "net.mqtt.disconnected", &conn)
+	if conn.Error == "" {
+		t.Error("Expected a client ID matching a username rule to be refused")
+	}
+}
+
+func TestBrokerAdapter_RetainedAndDisconnect(t *testing.T) {
+	broker, events := startBrokerAdapter(t)
+	addr := broker.Addr().String()
+
+	if err := broker.Deliver(MQTTMessagePayload{Topic: "config/rate", Payload: []byte("5s"), QoS: 1, Retained: true}); err != nil {
+		t.Fatalf("Failed to deliver: %v", err)
+	}
+
+	adapter, sub := startSubscriber(t, addr, []string{"config/#"}, WithClientID("dev-1"), WithQoS(1))
+	var msg MQTTMessagePayload
+	nextEvent(t, sub, "net.mqtt.message", &msg)
+	if !msg.Retained || string(msg.Payload) != "5s" {
+		t.Errorf("Expected retained message, got %+v", msg)
+	}
+
+	adapter.Stop()
+	var client MQTTClientPayload
+	for {
+		nextEvent(t, events, "net.mqtt.disconnect", &client)
+		if client.ClientID == "dev-1" {
+			break
+		}
+	}
+	if client.Reason != "disconnect" || client.Error != "" {
+		t.Errorf("Expected graceful disconnect, got %+v", client)
+	}
+}
+
+func TestBrokerAdapter_InvalidDelivery(t *testing.T) {
+	broker := NewBrokerAdapter("127.0.0.1:0")
+	if err := broker.Deliver(MQTTMessagePayload{Topic: "a/+"}); err == nil {
+		t.Error("Expected error for wildcard topic")
+	}
+
+	emitter := NewBrokerEmitter(broker)
+	evt, err := codec.NewEvent("net.mqtt.deliver", "test", "not a message", codec.JSON{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if err := emitter.Emit(context.Background(), evt); err == nil {
+		t.Error("Expected decode error")
+	}
+}
+
+func TestBrokerAdapter_SessionExpiryOnClock(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	events, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.mqtt.connect", "net.mqtt.disconnect"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer events.Close()
+
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	broker := NewBrokerAdapter("127.0.0.1:0")
+	if err := broker.Start(context.Background(), eng.ExternalBus(), clk); err != nil {
+		t.Fatalf("Failed to start broker: %v", err)
+	}
+	defer broker.Stop()
+
+	device, _ := startSubscriber(t, broker.Addr().String(), []string{"commands/#"},
+		WithClientID("dev-1"),
+		WithProtocolVersion(ProtocolV5),
+		WithPersistentSession(time.Minute),
+	)
+
+	var client MQTTClientPayload
+	nextEvent(t, events, "net.mqtt.connect", &client)
+	if !client.Timestamp.Equal(start) {
+		t.Errorf("Expected timestamp %v from the clock, got %v", start, client.Timestamp)
+	}
+
+	device.Stop()
+	nextEvent(t, events, "net.mqtt.disconnect", &client)
+
+	hasSession := func() bool {
+		broker.state.Lock()
+		defer broker.state.Unlock()
+		_, ok := broker.sessions["dev-1"]
+		return ok
+	}
+	if !hasSession() {
+		t.Fatal("Expected the session to outlive the connection")
+	}
+
+	// The session expires a minute later on the broker's clock
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+	for deadline := time.Now().Add(2 * time.Second); hasSession() && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if hasSession() {
+		t.Error("Expected the session to expire after advancing the clock")
+	}
+}
@@ -0,0 +1,465 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/internal/backoff"
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrQueueFull is returned when the outbound queue cannot take another publish
+	ErrQueueFull = errors.New("mqtt outbound queue full")
+
+	// ErrNotConnected is returned when an operation needs a live broker connection
+	ErrNotConnected = errors.New("mqtt not connected")
+)
+
+// hooks connect a client to the adapter or emitter that owns it
+type hooks struct {
+	// connected runs on every new connection before queued publishes are
+	// flushed; an error drops the connection
+	connected func(ctx context.Context, sessionPresent bool) error
+
+	// message receives inbound publishes. QoS 1/2 acknowledgements wait for
+	// it; an error leaves the message unacknowledged and drops the connection.
+	message func(p *publishPacket) error
+
+	// status receives "net.mqtt.connected" and "net.mqtt.disconnected" payloads
+	status func(eventType string, payload MQTTConnectionPayload)
+
+	// stats counts the client's connection, socket traffic and failures
+	stats *metrics.Counters
+}
+
+// client keeps an MQTT session alive. It dials and reconnects with backoff,
+// and holds in-flight, queued and partially received messages across
+// connections so QoS 1/2 guarantees survive reconnects.
+type client struct {
+	broker   string
+	addr     string
+	useTLS   bool
+	clientID string
+	opts     options
+	clk      clock.Clock
+	hooks    hooks
+
+	mu       sync.Mutex
+	conn     net.Conn // nil while disconnected
+	out      *outbox
+	received map[uint16]bool // Inbound QoS 2 IDs awaiting PUBREL
+	waiters  map[uint16]chan *subackPacket
+
+	writeMu sync.Mutex
+}
+
+// newClient validates the broker address and creates a disconnected client
+// that takes its delays and timeouts from clk
+func newClient(broker string, opts options, clk clock.Clock, h hooks) (*client, error) {
+	addr, useTLS, err := parseBroker(broker)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := opts.clientID
+	if clientID == "" {
+		clientID = "netadapters-" + strings.ReplaceAll(uuid.New().String(), "-", "")[:16]
+	}
+
+	c := &client{
+		broker:   broker,
+		addr:     addr,
+		useTLS:   useTLS,
+		clientID: clientID,
+		opts:     opts,
+		clk:      clk,
+		hooks:    h,
+		out:      newOutbox(opts.queueSize),
+		received: make(map[uint16]bool),
+		waiters:  make(map[uint16]chan *subackPacket),
+	}
+	c.out.reserved = func(id uint16) bool {
+		_, busy := c.waiters[id]
+		return busy
+	}
+	return c, nil
+}
+
+// parseBroker accepts tcp://, mqtt://, ssl://, tls:// and mqtts:// URLs or a bare host:port
+func parseBroker(broker string) (string, bool, error) {
+	if !strings.Contains(broker, "://") {
+		if _, _, err := net.SplitHostPort(broker); err != nil {
+			return "", false, fmt.Errorf("invalid broker address %q: %w", broker, err)
+		}
+		return broker, false, nil
+	}
+
+	u, err := url.Parse(broker)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid broker address %q: %w", broker, err)
+	}
+
+	var useTLS bool
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS, port = true, "8883"
+	default:
+		return "", false, fmt.Errorf("unsupported broker scheme %q", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// run connects and serves connections until ctx is cancelled
+func (c *client) run(ctx context.Context) {
+	attempt := 0
+	for {
+		attempt++
+		nc, br, ack, err := c.connect(ctx)
+		if err == nil {
+			connected := c.clk.Now()
+			c.serve(ctx, nc, br, ack, attempt)
+			if c.clk.Since(connected) >= backoff.StableAfter {
+				attempt = 0
+			}
+		} else if ctx.Err() == nil {
+			c.hooks.stats.Error()
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := c.clk.NewTimer(c.opts.backoff.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return
+		case <-wait.C():
+		}
+	}
+}
+
+// connect dials the broker and completes the CONNECT handshake
+func (c *client) connect(ctx context.Context) (net.Conn, *bufio.Reader, *connackPacket, error) {
+	dialer := net.Dialer{Timeout: c.opts.connectTimeout}
+	tcpConn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var nc net.Conn = metrics.NewConn(tcpConn, c.hooks.stats)
+
+	if c.useTLS {
+		cfg := c.opts.tlsConfig.Clone()
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName, _, _ = net.SplitHostPort(c.addr)
+		}
+		nc = tls.Client(nc, cfg)
+	}
+
+	nc.SetDeadline(time.Now().Add(c.opts.connectTimeout))
+	if err := writePacket(nc, c.connectPacket(), c.opts.version); err != nil {
+		nc.Close()
+		return nil, nil, nil, err
+	}
+
+	br := bufio.NewReader(nc)
+	p, err := readPacket(br, c.opts.version, c.opts.maxPacketSize)
+	if err != nil {
+		nc.Close()
+		return nil, nil, nil, err
+	}
+	ack, ok := p.(*connackPacket)
+	if !ok {
+		nc.Close()
+		return nil, nil, nil, fmt.Errorf("%w: expected CONNACK", errMalformed)
+	}
+	if ack.code != codeSuccess {
+		nc.Close()
+		return nil, nil, nil, fmt.Errorf("connection refused by %s: reason 0x%02X", c.broker, ack.code)
+	}
+
+	nc.SetDeadline(time.Time{})
+	return nc, br, ack, nil
+}
+
+// connectPacket builds the CONNECT packet from the options
+func (c *client) connectPacket() *connectPacket {
+	p := &connectPacket{
+		version:    c.opts.version,
+		clientID:   c.clientID,
+		cleanStart: !c.opts.persistent,
+		keepAlive:  uint16(min(c.opts.keepAlive/time.Second, math.MaxUint16)),
+		username:   c.opts.username,
+		password:   c.opts.password,
+		will:       c.opts.will,
+	}
+	if c.opts.version >= ProtocolV5 {
+		p.props.maximumPacketSize = uint32(c.opts.maxPacketSize)
+		if c.opts.persistent {
+			p.props.sessionExpiry = math.MaxUint32 // Never expires
+			if c.opts.sessionExpiry > 0 {
+				p.props.sessionExpiry = uint32(min(c.opts.sessionExpiry/time.Second, math.MaxUint32-1))
+			}
+		}
+	}
+	return p
+}
+
+// serve runs one connection until it drops or ctx is cancelled
+func (c *client) serve(ctx context.Context, nc net.Conn, br *bufio.Reader, ack *connackPacket, attempt int) {
+	// Stop disconnects cleanly so the broker discards the will
+	stop := context.AfterFunc(ctx, func() {
+		c.write(nc, &disconnectPacket{})
+		nc.Close()
+	})
+	defer stop()
+
+	keepAlive := c.opts.keepAlive
+	if ka := ack.props.serverKeepAlive; ka != nil && *ka > 0 {
+		keepAlive = time.Duration(*ka) * time.Second
+	}
+	window := c.opts.maxInflight
+	if ack.props.receiveMaximum > 0 {
+		window = min(window, int(ack.props.receiveMaximum))
+	}
+
+	c.mu.Lock()
+	c.conn = nc
+	if !ack.sessionPresent {
+		clear(c.received)
+	}
+	c.out.connect(func(p packet) error { return c.write(nc, p) }, window, ack.sessionPresent)
+	c.mu.Unlock()
+
+	readErr := make(chan error, 1)
+	go func() {
+		err := c.readLoop(nc, br, keepAlive)
+		nc.Close()
+
+		// Queue new publishes and release subscribers waiting for a SUBACK
+		c.mu.Lock()
+		c.conn = nil
+		c.out.disconnect()
+		for id, ch := range c.waiters {
+			close(ch)
+			delete(c.waiters, id)
+		}
+		c.mu.Unlock()
+
+		readErr <- err
+	}()
+	done := make(chan struct{})
+	go c.pingLoop(nc, keepAlive, done)
+	defer close(done)
+
+	var err error
+	if c.hooks.connected != nil {
+		err = c.hooks.connected(ctx, ack.sessionPresent)
+	}
+	if err == nil {
+		c.status("net.mqtt.connected", MQTTConnectionPayload{
+			SessionPresent: ack.sessionPresent,
+			Attempt:        attempt,
+		})
+
+		c.mu.Lock()
+		c.out.drain()
+		c.mu.Unlock()
+
+		err = <-readErr
+	} else {
+		nc.Close()
+		<-readErr
+	}
+
+	payload := MQTTConnectionPayload{}
+	if ctx.Err() == nil && err != nil {
+		payload.Error = err.Error()
+		c.hooks.stats.Error()
+	}
+	c.status("net.mqtt.disconnected", payload)
+}
+
+// readLoop handles packets until the connection fails
+func (c *client) readLoop(nc net.Conn, br *bufio.Reader, keepAlive time.Duration) error {
+	for {
+		nc.SetReadDeadline(time.Now().Add(keepAlive * 3 / 2))
+		p, err := readPacket(br, c.opts.version, c.opts.maxPacketSize)
+		if err != nil {
+			return err
+		}
+		if err := c.handle(nc, p); err != nil {
+			return err
+		}
+	}
+}
+
+// handle processes one packet from the broker
+func (c *client) handle(nc net.Conn, p packet) error {
+	switch p := p.(type) {
+	case *publishPacket:
+		if p.topic == "" {
+			return fmt.Errorf("%w: publish without topic", errMalformed)
+		}
+		switch p.qos {
+		case 0:
+			c.deliver(p)
+		case 1:
+			if err := c.deliver(p); err != nil {
+				return err
+			}
+			return c.write(nc, &ackPacket{kind: packetPuback, id: p.id})
+		case 2:
+			c.mu.Lock()
+			seen := c.received[p.id]
+			c.mu.Unlock()
+
+			if !seen {
+				if err := c.deliver(p); err != nil {
+					return err
+				}
+				c.mu.Lock()
+				c.received[p.id] = true
+				c.mu.Unlock()
+			}
+			return c.write(nc, &ackPacket{kind: packetPubrec, id: p.id})
+		}
+
+	case *ackPacket:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if p.kind == packetPubrel {
+			delete(c.received, p.id)
+			return c.write(nc, &ackPacket{kind: packetPubcomp, id: p.id})
+		}
+		return c.out.acknowledge(p)
+
+	case *subackPacket:
+		c.mu.Lock()
+		ch, ok := c.waiters[p.id]
+		delete(c.waiters, p.id)
+		c.mu.Unlock()
+		if ok {
+			ch <- p
+		}
+
+	case *pingPacket:
+		// Any packet resets the read deadline
+
+	case *disconnectPacket:
+		return fmt.Errorf("disconnected by broker: reason 0x%02X %s", p.code, p.props.reasonString)
+
+	default:
+		return fmt.Errorf("%w: unexpected packet %T", errMalformed, p)
+	}
+	return nil
+}
+
+// pingLoop sends PINGREQ every keepAlive until done is closed. Pings run on
+// real time, like the broker's read deadline they keep from expiring.
+func (c *client) pingLoop(nc net.Conn, keepAlive time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			c.write(nc, &pingPacket{kind: packetPingreq})
+		}
+	}
+}
+
+// publish sends p now, or queues it while disconnected or while the
+// in-flight window is full
+func (c *client) publish(p *publishPacket) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.out.push(p)
+}
+
+// subscribe sends SUBSCRIBE and waits for the broker's reason codes
+func (c *client) subscribe(ctx context.Context, subs []subscription) ([]byte, error) {
+	c.mu.Lock()
+	if c.conn == nil {
+		c.mu.Unlock()
+		return nil, ErrNotConnected
+	}
+	id := c.out.allocID()
+	ch := make(chan *subackPacket, 1)
+	c.waiters[id] = ch
+	err := c.write(c.conn, &subscribePacket{id: id, subs: subs})
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	timer := c.clk.NewTimer(c.opts.connectTimeout)
+	defer timer.Stop()
+
+	select {
+	case ack, ok := <-ch:
+		if !ok {
+			return nil, ErrNotConnected
+		}
+		return ack.codes, nil
+	case <-timer.C():
+		return nil, fmt.Errorf("timed out waiting for SUBACK from %s", c.broker)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// write sends one packet; a failed write closes the connection
+func (c *client) write(nc net.Conn, p packet) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	nc.SetWriteDeadline(time.Now().Add(c.opts.writeTimeout))
+	if err := writePacket(nc, p, c.opts.version); err != nil {
+		nc.Close()
+		return err
+	}
+	return nil
+}
+
+// deliver hands an inbound publish to the owner, if it listens
+func (c *client) deliver(p *publishPacket) error {
+	if c.hooks.message == nil {
+		return nil
+	}
+	return c.hooks.message(p)
+}
+
+// status reports a connection change to the owner, if it listens
+func (c *client) status(eventType string, payload MQTTConnectionPayload) {
+	if c.hooks.status == nil {
+		return
+	}
+	payload.ClientID = c.clientID
+	payload.Broker = c.broker
+	payload.Timestamp = c.clk.Wall()
+	c.hooks.status(eventType, payload)
+}
@@ -0,0 +1,111 @@
+package mqtt
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+// startMochi runs an independent broker implementation (mochi-mqtt), so the
+// clients are checked against a peer that does not share this package's
+// packet codec
+func startMochi(t *testing.T) (*mochi.Server, string) {
+	t.Helper()
+
+	server := mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := server.AddHook(new(auth.AllowHook), nil); err != nil {
+		t.Fatalf("Failed to add hook: %v", err)
+	}
+	ln := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	if err := server.AddListener(ln); err != nil {
+		t.Fatalf("Failed to add listener: %v", err)
+	}
+	if err := server.Serve(); err != nil {
+		t.Fatalf("Failed to start broker: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server, ln.Address()
+}
+
+func TestInterop_Subscriber(t *testing.T) {
+	for _, version := range []byte{ProtocolV311, ProtocolV5} {
+		t.Run(map[byte]string{ProtocolV311: "v3.1.1", ProtocolV5: "v5"}[version], func(t *testing.T) {
+			broker, addr := startMochi(t)
+			_, sub := startSubscriber(t, "tcp://"+addr, []string{"sensors/+/temp"},
+				WithProtocolVersion(version),
+				WithQoS(2),
+			)
+
+			var conn MQTTConnectionPayload
+			nextEvent(t, sub, "net.mqtt.connected", &conn)
+			time.Sleep(50 * time.Millisecond) // Let the SUBSCRIBE be acknowledged
+
+			if err := broker.Publish("sensors/kitchen/temp", []byte("21.5"), false, 2); err != nil {
+				t.Fatalf("Failed to publish: %v", err)
+			}
+			broker.Publish("sensors/kitchen/humidity", []byte("40"), false, 1)
+
+			var msg MQTTMessagePayload
+			nextEvent(t, sub, "net.mqtt.message", &msg)
+			if msg.Topic != "sensors/kitchen/temp" || string(msg.Payload) != "21.5" || msg.QoS != 2 {
+				t.Errorf("Unexpected message: %+v", msg)
+			}
+		})
+	}
+}
+
+func TestInterop_Publisher(t *testing.T) {
+	for _, version := range []byte{ProtocolV311, ProtocolV5} {
+		t.Run(map[byte]string{ProtocolV311: "v3.1.1", ProtocolV5: "v5"}[version], func(t *testing.T) {
+			broker, addr := startMochi(t)
+
+			received := make(chan packets.Packet, 4)
+			err := broker.Subscribe("devices/#", 1, func(cl *mochi.Client, sub packets.Subscription, pk packets.Packet) {
+				received <- pk
+			})
+			if err != nil {
+				t.Fatalf("Failed to subscribe: %v", err)
+			}
+
+			emitter := NewPublisherEmitter("mqtt://"+addr, WithProtocolVersion(version))
+			defer emitter.Close()
+
+			for _, qos := range []byte{1, 2} {
+				err := emitter.Emit(context.Background(), messageEvent(t, MQTTMessagePayload{
+					Topic:          "devices/1/state",
+					Payload:        []byte(`{"on":true}`),
+					QoS:            qos,
+					ContentType:    "application/json",
+					UserProperties: map[string]string{"site": "north"},
+				}))
+				if err != nil {
+					t.Fatalf("Emit failed: %v", err)
+				}
+
+				select {
+				case pk := <-received:
+					if pk.TopicName != "devices/1/state" || string(pk.Payload) != `{"on":true}` {
+						t.Errorf("Unexpected publish: %s %q", pk.TopicName, pk.Payload)
+					}
+					if version == ProtocolV5 {
+						if pk.Properties.ContentType != "application/json" || len(pk.Properties.User) != 1 || pk.Properties.User[0].Val != "north" {
+							t.Errorf("Expected 5.0 properties, got %+v", pk.Properties)
+						}
+					}
+				case <-time.After(2 * time.Second):
+					t.Fatalf("Timed out waiting for QoS %d publish", qos)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/internal/backoff"
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+)
+
+// Option configures a SubscriberAdapter, PublisherEmitter or BrokerAdapter.
+// The three share one option set, so each ignores the options meant for the
+// others (a PublisherEmitter ignores WithAuthenticator, for instance).
+type Option func(*options)
+
+// options holds the settings shared by adapters and emitters
+type options struct {
+	codec codec.Codec
+
+	// Session
+	version       byte
+	clientID      string
+	username      string
+	password      []byte
+	persistent    bool
+	sessionExpiry time.Duration
+	will          *willMessage
+
+	// Connection
+	keepAlive      time.Duration
+	connectTimeout time.Duration
+	writeTimeout   time.Duration
+	backoff        backoff.Policy
+	tlsConfig      *tls.Config
+	maxPacketSize  int
+
+	// Delivery
+	qos         byte
+	queueSize   int
+	maxInflight int
+
+	// Broker access control
+	auth Authenticator
+	acl  []aclRule
+
+	clk clock.Clock
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		codec:          codec.JSON{},
+		version:        ProtocolV311,
+		keepAlive:      30 * time.Second,
+		connectTimeout: 10 * time.Second,
+		writeTimeout:   10 * time.Second,
+		backoff:        backoff.Policy{Min: 500 * time.Millisecond, Max: 30 * time.Second},
+		maxPacketSize:  1 << 20, // 1MB
+		queueSize:      1000,
+		maxInflight:    64,
+		clk:            clock.System(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCodec sets the payload codec.
+// Adapters encode events with it; emitters use it to decode events that
+// carry no "codec" metadata.
+func WithCodec(c codec.Codec) Option {
+	return func(o *options) {
+		if c != nil {
+			o.codec = c
+		}
+	}
+}
+
+// WithProtocolVersion selects ProtocolV311 (default) or ProtocolV5
+func WithProtocolVersion(version byte) Option {
+	return func(o *options) {
+		if version == ProtocolV311 || version == ProtocolV5 {
+			o.version = version
+		}
+	}
+}
+
+// WithClientID sets the MQTT client identifier.
+// A random identifier is generated when none is set.
+func WithClientID(id string) Option {
+	return func(o *options) {
+		o.clientID = id
+	}
+}
+
+// WithCredentials sets the username and password sent in CONNECT
+func WithCredentials(username, password string) Option {
+	return func(o *options) {
+		o.username = username
+		o.password = []byte(password)
+	}
+}
+
+// WithPersistentSession keeps the broker session (subscriptions and queued
+// QoS 1/2 messages) across reconnects instead of starting clean.
+// expiry is sent as the 5.0 session expiry interval; 3.1.1 brokers keep
+// sessions until they are cleaned. Use it together with WithClientID.
+func WithPersistentSession(expiry time.Duration) Option {
+	return func(o *options) {
+		o.persistent = true
+		o.sessionExpiry = expiry
+	}
+}
+
+// WithWill sets the message the broker publishes when the connection is lost
+func WithWill(topic string, payload []byte, qos byte, retain bool) Option {
+	return func(o *options) {
+		o.will = &willMessage{topic: topic, payload: payload, qos: min(qos, 2), retain: retain}
+	}
+}
+
+// WithKeepAlive sets the keepalive interval; the connection is considered
+// lost when nothing arrives for 1.5 times this interval
+func WithKeepAlive(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.keepAlive = d
+		}
+	}
+}
+
+// WithConnectTimeout bounds dialing and the CONNECT handshake
+func WithConnectTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.connectTimeout = d
+		}
+	}
+}
+
+// WithWriteTimeout bounds each packet write
+func WithWriteTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.writeTimeout = d
+		}
+	}
+}
+
+// WithBackoff sets the reconnect delay range; delays double from min up to max
+func WithBackoff(min, max time.Duration) Option {
+	return func(o *options) {
+		if p := (backoff.Policy{Min: min, Max: max}); p.Valid() {
+			o.backoff = p
+		}
+	}
+}
+
+// WithTLSConfig sets the TLS configuration for ssl://, tls:// and mqtts://
+// brokers. A BrokerAdapter with a TLS configuration accepts only TLS clients.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithMaxPacketSize limits the size of packets accepted from the peer
+func WithMaxPacketSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxPacketSize = n
+		}
+	}
+}
+
+// WithQoS sets the QoS requested for subscriptions (default 0)
+func WithQoS(qos byte) Option {
+	return func(o *options) {
+		o.qos = min(qos, 2)
+	}
+}
+
+// WithQueueSize sets how many publishes are held while disconnected or while
+// the in-flight window is full; beyond it Emit returns ErrQueueFull.
+// A BrokerAdapter applies it to each client session and drops the excess.
+func WithQueueSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.queueSize = n
+		}
+	}
+}
+
+// WithMaxInflight limits unacknowledged QoS 1/2 publishes.
+// A lower 5.0 receive maximum announced by the peer takes precedence;
+// a BrokerAdapter also announces it as its own receive maximum.
+func WithMaxInflight(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxInflight = min(n, 65535)
+		}
+	}
+}
+
+// WithClock sets the clock the PublisherEmitter takes redial delays and
+// timeouts from (defaults to the system clock). Adapters use the clock passed
+// to Start; keepalive pings and socket deadlines always use real time.
+func WithClock(clk clock.Clock) Option {
+	return func(o *options) {
+		if clk != nil {
+			o.clk = clk
+		}
+	}
+}
+
+// WithAuthenticator makes a BrokerAdapter authenticate every CONNECT.
+// Rejected clients get "bad username or password" when the error is
+// ErrBadCredentials and "not authorized" otherwise.
+func WithAuthenticator(auth Authenticator) Option {
+	return func(o *options) {
+		o.auth = auth
+	}
+}
+
+// WithACL adds a BrokerAdapter access rule for topics starting with prefix.
+// users lists the usernames the rule applies to; none means every client.
+// Usernames are only trustworthy with WithAuthenticator, which should check
+// them. "%c" and "%u" in prefix stand for the client's ID and username. The
+// longest matching prefix decides (later rules win ties), and once any rule
+// is set, topics that no rule matches are denied.
+func WithACL(prefix string, access Access, users ...string) Option {
+	return func(o *options) {
+		o.acl = append(o.acl, aclRule{prefix: prefix, access: access, users: users})
+	}
+}
+
+// WithClientACL adds a BrokerAdapter access rule, like WithACL, that applies
+// to the listed client IDs. Clients choose their own IDs, so such rules are
+// only as strong as the authenticator's check of the ID.
+func WithClientACL(prefix string, access Access, clientIDs ...string) Option {
+	return func(o *options) {
+		o.acl = append(o.acl, aclRule{prefix: prefix, access: access, clientIDs: clientIDs})
+	}
+}
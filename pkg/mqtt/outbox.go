@@ -0,0 +1,155 @@
+package mqtt
+
+// outbound is a QoS 1/2 publish awaiting acknowledgement
+type outbound struct {
+	p        *publishPacket
+	released bool // PUBREC received and PUBREL sent; awaiting PUBCOMP
+}
+
+// outbox holds one session's outbound publishes: those waiting for a
+// connection or a free in-flight slot, and QoS 1/2 publishes awaiting
+// acknowledgement, which are retransmitted on the next connection.
+// It is shared by the client and the broker; callers serialize access.
+type outbox struct {
+	send     func(packet) error // Writes to the current connection; nil while disconnected
+	window   int                // In-flight limit for the current connection
+	size     int                // Queue limit
+	reserved func(uint16) bool  // Reports packet IDs used outside the outbox; may be nil
+
+	nextID   uint16
+	inflight map[uint16]*outbound
+	order    []uint16 // In-flight IDs in send order, for retransmission
+	queue    []*publishPacket
+}
+
+// newOutbox creates a disconnected outbox that queues up to size publishes
+func newOutbox(size int) *outbox {
+	return &outbox{
+		size:     size,
+		inflight: make(map[uint16]*outbound),
+	}
+}
+
+// push sends p now, or queues it while disconnected or while the in-flight
+// window is full. It returns ErrQueueFull when the queue is full.
+func (o *outbox) push(p *publishPacket) error {
+	if o.send == nil || len(o.queue) > 0 || (p.qos > 0 && len(o.inflight) >= o.window) {
+		if len(o.queue) >= o.size {
+			return ErrQueueFull
+		}
+		o.queue = append(o.queue, p)
+		return nil
+	}
+	return o.transmit(p)
+}
+
+// transmit writes p, tracking it until acknowledged when QoS > 0.
+// A failed QoS 1/2 write is retransmitted on the next connection.
+func (o *outbox) transmit(p *publishPacket) error {
+	if p.qos > 0 {
+		p.id = o.allocID()
+		o.inflight[p.id] = &outbound{p: p}
+		o.order = append(o.order, p.id)
+	}
+	if err := o.send(p); err != nil && p.qos == 0 {
+		return err
+	}
+	return nil
+}
+
+// drain sends queued publishes while connected and the window allows
+func (o *outbox) drain() {
+	for len(o.queue) > 0 && o.send != nil {
+		p := o.queue[0]
+		if p.qos > 0 && len(o.inflight) >= o.window {
+			return
+		}
+		o.queue[0] = nil
+		o.queue = o.queue[1:]
+		if o.transmit(p) != nil {
+			return
+		}
+	}
+}
+
+// acknowledge handles PUBACK, PUBREC and PUBCOMP for an outbound publish
+func (o *outbox) acknowledge(ack *ackPacket) error {
+	switch ack.kind {
+	case packetPuback, packetPubcomp:
+		o.complete(ack.id)
+	case packetPubrec:
+		out, ok := o.inflight[ack.id]
+		if !ok || ack.code >= codeUnspecified {
+			o.complete(ack.id)
+			return nil
+		}
+		out.released = true
+		if o.send != nil {
+			return o.send(&ackPacket{kind: packetPubrel, id: ack.id})
+		}
+	}
+	return nil
+}
+
+// complete forgets an acknowledged publish and fills the freed window slot
+func (o *outbox) complete(id uint16) {
+	if _, ok := o.inflight[id]; !ok {
+		return
+	}
+	delete(o.inflight, id)
+	for i, v := range o.order {
+		if v == id {
+			o.order = append(o.order[:i], o.order[i+1:]...)
+			break
+		}
+	}
+	o.drain()
+}
+
+// connect attaches a new connection and retransmits in-flight publishes.
+// With a resumed session they are resent as duplicates; on a fresh session,
+// publishes the peer already received (PUBREC) are done and the rest start over.
+// Queued publishes are left for drain.
+func (o *outbox) connect(send func(packet) error, window int, sessionPresent bool) {
+	o.send = send
+	o.window = window
+
+	order := o.order
+	o.order = nil
+	for _, id := range order {
+		out := o.inflight[id]
+		switch {
+		case out.released && sessionPresent:
+			o.order = append(o.order, id)
+			send(&ackPacket{kind: packetPubrel, id: id})
+		case out.released:
+			delete(o.inflight, id)
+		default:
+			o.order = append(o.order, id)
+			out.p.dup = sessionPresent
+			send(out.p)
+		}
+	}
+}
+
+// disconnect detaches the connection; later publishes are queued
+func (o *outbox) disconnect() {
+	o.send = nil
+}
+
+// allocID returns a packet identifier not in use
+func (o *outbox) allocID() uint16 {
+	for {
+		o.nextID++
+		if o.nextID == 0 {
+			continue
+		}
+		if _, busy := o.inflight[o.nextID]; busy {
+			continue
+		}
+		if o.reserved != nil && o.reserved(o.nextID) {
+			continue
+		}
+		return o.nextID
+	}
+}
@@ -0,0 +1,913 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Protocol versions, as carried in the CONNECT protocol level byte
+const (
+	ProtocolV311 byte = 4 // MQTT 3.1.1
+	ProtocolV5   byte = 5 // MQTT 5.0
+)
+
+// Control packet types
+const (
+	packetConnect     byte = 1
+	packetConnack     byte = 2
+	packetPublish     byte = 3
+	packetPuback      byte = 4
+	packetPubrec      byte = 5
+	packetPubrel      byte = 6
+	packetPubcomp     byte = 7
+	packetSubscribe   byte = 8
+	packetSuback      byte = 9
+	packetUnsubscribe byte = 10
+	packetUnsuback    byte = 11
+	packetPingreq     byte = 12
+	packetPingresp    byte = 13
+	packetDisconnect  byte = 14
+	packetAuth        byte = 15
+)
+
+// Reason codes (MQTT 5.0). MQTT 3.1.1 CONNACK return codes are mapped onto
+// these by connackCode and reasonFromConnack.
+const (
+	codeSuccess             byte = 0x00
+	codeGrantedQoS1         byte = 0x01
+	codeGrantedQoS2         byte = 0x02
+	codeDisconnectWithWill  byte = 0x04
+	codeNoMatchingListeners byte = 0x10
+	codeNoSubscription      byte = 0x11
+	codeUnspecified         byte = 0x80
+	codeMalformed           byte = 0x81
+	codeProtocolError       byte = 0x82
+	codeUnsupportedVersion  byte = 0x84
+	codeInvalidClientID     byte = 0x85
+	codeBadCredentials      byte = 0x86
+	codeNotAuthorized       byte = 0x87
+	codeServerUnavailable   byte = 0x88
+	codeServerShuttingDown  byte = 0x8B
+	codeSessionTakenOver    byte = 0x8E
+	codeTopicFilterInvalid  byte = 0x8F
+	codeTopicNameInvalid    byte = 0x90
+	codePacketIDNotFound    byte = 0x92
+	codePacketTooLarge      byte = 0x95
+	codeSharedNotSupported  byte = 0x9E
+)
+
+var (
+	// ErrPacketTooLarge is returned when a packet exceeds the configured maximum size
+	ErrPacketTooLarge = errors.New("mqtt packet too large")
+
+	errMalformed = errors.New("malformed mqtt packet")
+)
+
+// packet is a decoded MQTT control packet
+type packet interface {
+	// encode returns the complete packet, fixed header included
+	encode(version byte) []byte
+}
+
+// readPacket reads one control packet. CONNECT packets carry their own
+// protocol version; every other packet is decoded for version.
+func readPacket(r *bufio.Reader, version byte, maxSize int) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := readVarint(r)
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	if maxSize > 0 && length > maxSize {
+		return nil, ErrPacketTooLarge
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, noEOF(err)
+	}
+	return decodePacket(header, body, version)
+}
+
+// writePacket writes one control packet
+func writePacket(w io.Writer, p packet, version byte) error {
+	_, err := w.Write(p.encode(version))
+	return err
+}
+
+// decodePacket decodes the body of a packet whose fixed header byte is header
+func decodePacket(header byte, body []byte, version byte) (packet, error) {
+	r := &reader{b: body}
+	kind, flags := header>>4, header&0x0F
+
+	var p packet
+	switch kind {
+	case packetConnect:
+		p = decodeConnect(r)
+	case packetConnack:
+		p = decodeConnack(r, version)
+	case packetPublish:
+		p = decodePublish(r, flags, version)
+	case packetPuback, packetPubrec, packetPubrel, packetPubcomp:
+		p = decodeAck(r, kind, version)
+	case packetSubscribe:
+		p = decodeSubscribe(r, version)
+	case packetSuback, packetUnsuback:
+		p = decodeSuback(r, kind, version)
+	case packetUnsubscribe:
+		p = decodeUnsubscribe(r, version)
+	case packetPingreq, packetPingresp:
+		p = &pingPacket{kind: kind}
+	case packetDisconnect:
+		p = decodeDisconnect(r, version)
+	default:
+		return nil, fmt.Errorf("%w: unsupported packet type %d", errMalformed, kind)
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("%w: packet type %d: %v", errMalformed, kind, r.err)
+	}
+	return p, nil
+}
+
+// frame prefixes body with a fixed header
+func frame(header byte, body []byte) []byte {
+	w := &writer{b: make([]byte, 0, len(body)+5)}
+	w.byte(header)
+	w.varint(len(body))
+	w.b = append(w.b, body...)
+	return w.b
+}
+
+// willMessage is the last will carried in a CONNECT packet
+type willMessage struct {
+	topic   string
+	payload []byte
+	qos     byte
+	retain  bool
+	props   properties
+}
+
+// connectPacket is a CONNECT packet
+type connectPacket struct {
+	version    byte
+	clientID   string
+	cleanStart bool
+	keepAlive  uint16
+	username   string
+	password   []byte
+	will       *willMessage
+	props      properties
+}
+
+func (p *connectPacket) encode(byte) []byte {
+	var flags byte
+	if p.cleanStart {
+		flags |= 0x02
+	}
+	if p.will != nil {
+		flags |= 0x04 | p.will.qos<<3
+		if p.will.retain {
+			flags |= 0x20
+		}
+	}
+	if p.password != nil {
+		flags |= 0x40
+	}
+	if p.username != "" {
+		flags |= 0x80
+	}
+
+	w := &writer{}
+	w.string("MQTT")
+	w.byte(p.version)
+	w.byte(flags)
+	w.uint16(p.keepAlive)
+	if p.version >= ProtocolV5 {
+		w.props(&p.props)
+	}
+	w.string(p.clientID)
+	if p.will != nil {
+		if p.version >= ProtocolV5 {
+			w.props(&p.will.props)
+		}
+		w.string(p.will.topic)
+		w.binary(p.will.payload)
+	}
+	if p.username != "" {
+		w.string(p.username)
+	}
+	if p.password != nil {
+		w.binary(p.password)
+	}
+	return frame(packetConnect<<4, w.b)
+}
+
+func decodeConnect(r *reader) *connectPacket {
+	p := &connectPacket{}
+	if name := r.string(); r.err == nil && name != "MQTT" {
+		r.fail("unknown protocol name %q", name)
+	}
+	p.version = r.byte()
+	if r.err == nil && p.version != ProtocolV311 && p.version != ProtocolV5 {
+		// Leave the body unparsed; the receiver rejects the version
+		return p
+	}
+
+	flags := r.byte()
+	p.cleanStart = flags&0x02 != 0
+	p.keepAlive = r.uint16()
+	if p.version >= ProtocolV5 {
+		p.props = r.props()
+	}
+	p.clientID = r.string()
+	if flags&0x04 != 0 {
+		p.will = &willMessage{qos: flags >> 3 & 0x03, retain: flags&0x20 != 0}
+		if p.version >= ProtocolV5 {
+			p.will.props = r.props()
+		}
+		p.will.topic = r.string()
+		p.will.payload = r.binary()
+	}
+	if flags&0x80 != 0 {
+		p.username = r.string()
+	}
+	if flags&0x40 != 0 {
+		p.password = r.binary()
+	}
+	return p
+}
+
+// connackPacket is a CONNACK packet. code is always a 5.0 reason code;
+// it is translated to and from 3.1.1 return codes on the wire.
+type connackPacket struct {
+	sessionPresent bool
+	code           byte
+	props          properties
+}
+
+func (p *connackPacket) encode(version byte) []byte {
+	w := &writer{}
+	if p.sessionPresent {
+		w.byte(1)
+	} else {
+		w.byte(0)
+	}
+	if version >= ProtocolV5 {
+		w.byte(p.code)
+		w.props(&p.props)
+	} else {
+		w.byte(connackCode(p.code))
+	}
+	return frame(packetConnack<<4, w.b)
+}
+
+func decodeConnack(r *reader, version byte) *connackPacket {
+	p := &connackPacket{sessionPresent: r.byte()&0x01 != 0}
+	if version >= ProtocolV5 {
+		p.code = r.byte()
+		p.props = r.props()
+	} else {
+		p.code = reasonFromConnack(r.byte())
+	}
+	return p
+}
+
+// connackCode maps a 5.0 reason code to the closest 3.1.1 return code
+func connackCode(code byte) byte {
+	switch code {
+	case codeSuccess:
+		return 0
+	case codeUnsupportedVersion:
+		return 1
+	case codeInvalidClientID:
+		return 2
+	case codeBadCredentials:
+		return 4
+	case codeNotAuthorized:
+		return 5
+	default:
+		return 3 // Server unavailable
+	}
+}
+
+// reasonFromConnack maps a 3.1.1 return code to a 5.0 reason code
+func reasonFromConnack(code byte) byte {
+	switch code {
+	case 0:
+		return codeSuccess
+	case 1:
+		return codeUnsupportedVersion
+	case 2:
+		return codeInvalidClientID
+	case 4:
+		return codeBadCredentials
+	case 5:
+		return codeNotAuthorized
+	default:
+		return codeServerUnavailable
+	}
+}
+
+// publishPacket is a PUBLISH packet
+type publishPacket struct {
+	dup     bool
+	qos     byte
+	retain  bool
+	topic   string
+	id      uint16 // Only for QoS 1 and 2
+	payload []byte
+	props   properties
+}
+
+func (p *publishPacket) encode(version byte) []byte {
+	header := packetPublish<<4 | p.qos<<1
+	if p.dup {
+		header |= 0x08
+	}
+	if p.retain {
+		header |= 0x01
+	}
+
+	w := &writer{b: make([]byte, 0, len(p.topic)+len(p.payload)+8)}
+	w.string(p.topic)
+	if p.qos > 0 {
+		w.uint16(p.id)
+	}
+	if version >= ProtocolV5 {
+		w.props(&p.props)
+	}
+	w.b = append(w.b, p.payload...)
+	return frame(header, w.b)
+}
+
+func decodePublish(r *reader, flags byte, version byte) *publishPacket {
+	p := &publishPacket{
+		dup:    flags&0x08 != 0,
+		qos:    flags >> 1 & 0x03,
+		retain: flags&0x01 != 0,
+	}
+	if p.qos > 2 {
+		r.fail("invalid qos %d", p.qos)
+		return p
+	}
+	p.topic = r.string()
+	if p.qos > 0 {
+		p.id = r.uint16()
+	}
+	if version >= ProtocolV5 {
+		p.props = r.props()
+	}
+	p.payload = r.rest()
+	return p
+}
+
+// ackPacket is a PUBACK, PUBREC, PUBREL or PUBCOMP packet
+type ackPacket struct {
+	kind  byte
+	id    uint16
+	code  byte // 5.0 only
+	props properties
+}
+
+func (p *ackPacket) encode(version byte) []byte {
+	header := p.kind << 4
+	if p.kind == packetPubrel {
+		header |= 0x02
+	}
+
+	w := &writer{}
+	w.uint16(p.id)
+	if version >= ProtocolV5 && (p.code != codeSuccess || !p.props.empty()) {
+		w.byte(p.code)
+		w.props(&p.props)
+	}
+	return frame(header, w.b)
+}
+
+func decodeAck(r *reader, kind byte, version byte) *ackPacket {
+	p := &ackPacket{kind: kind, id: r.uint16()}
+	if version >= ProtocolV5 && r.len() > 0 {
+		p.code = r.byte()
+		if r.len() > 0 {
+			p.props = r.props()
+		}
+	}
+	return p
+}
+
+// subscription is one topic filter in a SUBSCRIBE packet
+type subscription struct {
+	filter            string
+	qos               byte
+	noLocal           bool // 5.0: do not receive own publishes
+	retainAsPublished bool // 5.0: keep the retain flag on forwarded messages
+	retainHandling    byte // 5.0: 0 send retained, 1 only for new subscriptions, 2 never
+}
+
+// subscribePacket is a SUBSCRIBE packet
+type subscribePacket struct {
+	id    uint16
+	subs  []subscription
+	props properties
+}
+
+func (p *subscribePacket) encode(version byte) []byte {
+	w := &writer{}
+	w.uint16(p.id)
+	if version >= ProtocolV5 {
+		w.props(&p.props)
+	}
+	for _, s := range p.subs {
+		w.string(s.filter)
+		opts := s.qos
+		if version >= ProtocolV5 {
+			if s.noLocal {
+				opts |= 0x04
+			}
+			if s.retainAsPublished {
+				opts |= 0x08
+			}
+			opts |= s.retainHandling << 4
+		}
+		w.byte(opts)
+	}
+	return frame(packetSubscribe<<4|0x02, w.b)
+}
+
+func decodeSubscribe(r *reader, version byte) *subscribePacket {
+	p := &subscribePacket{id: r.uint16()}
+	if version >= ProtocolV5 {
+		p.props = r.props()
+	}
+	for r.err == nil && r.len() > 0 {
+		s := subscription{filter: r.string()}
+		opts := r.byte()
+		s.qos = opts & 0x03
+		if version >= ProtocolV5 {
+			s.noLocal = opts&0x04 != 0
+			s.retainAsPublished = opts&0x08 != 0
+			s.retainHandling = opts >> 4 & 0x03
+		}
+		p.subs = append(p.subs, s)
+	}
+	if r.err == nil && len(p.subs) == 0 {
+		r.fail("subscribe without topic filters")
+	}
+	return p
+}
+
+// subackPacket is a SUBACK or UNSUBACK packet
+type subackPacket struct {
+	kind  byte
+	id    uint16
+	codes []byte // One per filter; 3.1.1 UNSUBACK carries none
+	props properties
+}
+
+func (p *subackPacket) encode(version byte) []byte {
+	w := &writer{}
+	w.uint16(p.id)
+	if version >= ProtocolV5 {
+		w.props(&p.props)
+	}
+	if version >= ProtocolV5 || p.kind == packetSuback {
+		for _, code := range p.codes {
+			if version < ProtocolV5 && code >= codeUnspecified {
+				code = codeUnspecified // 3.1.1 has a single failure code
+			}
+			w.byte(code)
+		}
+	}
+	return frame(p.kind<<4, w.b)
+}
+
+func decodeSuback(r *reader, kind byte, version byte) *subackPacket {
+	p := &subackPacket{kind: kind, id: r.uint16()}
+	if version >= ProtocolV5 {
+		p.props = r.props()
+	}
+	p.codes = r.rest()
+	return p
+}
+
+// unsubscribePacket is an UNSUBSCRIBE packet
+type unsubscribePacket struct {
+	id      uint16
+	filters []string
+	props   properties
+}
+
+func (p *unsubscribePacket) encode(version byte) []byte {
+	w := &writer{}
+	w.uint16(p.id)
+	if version >= ProtocolV5 {
+		w.props(&p.props)
+	}
+	for _, f := range p.filters {
+		w.string(f)
+	}
+	return frame(packetUnsubscribe<<4|0x02, w.b)
+}
+
+func decodeUnsubscribe(r *reader, version byte) *unsubscribePacket {
+	p := &unsubscribePacket{id: r.uint16()}
+	if version >= ProtocolV5 {
+		p.props = r.props()
+	}
+	for r.err == nil && r.len() > 0 {
+		p.filters = append(p.filters, r.string())
+	}
+	return p
+}
+
+// pingPacket is a PINGREQ or PINGRESP packet
+type pingPacket struct {
+	kind byte
+}
+
+func (p *pingPacket) encode(byte) []byte {
+	return []byte{p.kind << 4, 0}
+}
+
+// disconnectPacket is a DISCONNECT packet
+type disconnectPacket struct {
+	code  byte // 5.0 only
+	props properties
+}
+
+func (p *disconnectPacket) encode(version byte) []byte {
+	w := &writer{}
+	if version >= ProtocolV5 && (p.code != codeSuccess || !p.props.empty()) {
+		w.byte(p.code)
+		w.props(&p.props)
+	}
+	return frame(packetDisconnect<<4, w.b)
+}
+
+func decodeDisconnect(r *reader, version byte) *disconnectPacket {
+	p := &disconnectPacket{}
+	if version >= ProtocolV5 && r.len() > 0 {
+		p.code = r.byte()
+		if r.len() > 0 {
+			p.props = r.props()
+		}
+	}
+	return p
+}
+
+// userProperty is a 5.0 user property; keys may repeat
+type userProperty struct {
+	key, value string
+}
+
+// properties holds the MQTT 5.0 properties this package uses.
+// Zero values are not written; pointers mark properties whose zero value is meaningful.
+type properties struct {
+	payloadFormat     byte
+	messageExpiry     uint32
+	contentType       string
+	responseTopic     string
+	correlationData   []byte
+	subscriptionIDs   []int
+	sessionExpiry     uint32
+	assignedClientID  string
+	serverKeepAlive   *uint16
+	reasonString      string
+	receiveMaximum    uint16
+	topicAliasMaximum uint16
+	topicAlias        uint16
+	maximumQoS        *byte
+	retainAvailable   *byte
+	maximumPacketSize uint32
+	wildcardAvailable *byte
+	sharedAvailable   *byte
+	userProperties    []userProperty
+}
+
+// empty reports whether no property is set
+func (p *properties) empty() bool {
+	w := &writer{}
+	p.write(w)
+	return len(w.b) == 0
+}
+
+// write appends the properties without their length prefix
+func (p *properties) write(w *writer) {
+	optByte := func(id byte, v *byte) {
+		if v != nil {
+			w.byte(id)
+			w.byte(*v)
+		}
+	}
+
+	if p.payloadFormat != 0 {
+		w.byte(0x01)
+		w.byte(p.payloadFormat)
+	}
+	if p.messageExpiry != 0 {
+		w.byte(0x02)
+		w.uint32(p.messageExpiry)
+	}
+	if p.contentType != "" {
+		w.byte(0x03)
+		w.string(p.contentType)
+	}
+	if p.responseTopic != "" {
+		w.byte(0x08)
+		w.string(p.responseTopic)
+	}
+	if p.correlationData != nil {
+		w.byte(0x09)
+		w.binary(p.correlationData)
+	}
+	for _, id := range p.subscriptionIDs {
+		w.byte(0x0B)
+		w.varint(id)
+	}
+	if p.sessionExpiry != 0 {
+		w.byte(0x11)
+		w.uint32(p.sessionExpiry)
+	}
+	if p.assignedClientID != "" {
+		w.byte(0x12)
+		w.string(p.assignedClientID)
+	}
+	if p.serverKeepAlive != nil {
+		w.byte(0x13)
+		w.uint16(*p.serverKeepAlive)
+	}
+	if p.reasonString != "" {
+		w.byte(0x1F)
+		w.string(p.reasonString)
+	}
+	if p.receiveMaximum != 0 {
+		w.byte(0x21)
+		w.uint16(p.receiveMaximum)
+	}
+	if p.topicAliasMaximum != 0 {
+		w.byte(0x22)
+		w.uint16(p.topicAliasMaximum)
+	}
+	if p.topicAlias != 0 {
+		w.byte(0x23)
+		w.uint16(p.topicAlias)
+	}
+	optByte(0x24, p.maximumQoS)
+	optByte(0x25, p.retainAvailable)
+	for _, up := range p.userProperties {
+		w.byte(0x26)
+		w.string(up.key)
+		w.string(up.value)
+	}
+	if p.maximumPacketSize != 0 {
+		w.byte(0x27)
+		w.uint32(p.maximumPacketSize)
+	}
+	optByte(0x28, p.wildcardAvailable)
+	optByte(0x2A, p.sharedAvailable)
+}
+
+// read parses properties until the reader is exhausted. Properties this
+// package does not use are validated and skipped.
+func (p *properties) read(r *reader) {
+	for r.err == nil && r.len() > 0 {
+		id := r.varint()
+		switch id {
+		case 0x01:
+			p.payloadFormat = r.byte()
+		case 0x02:
+			p.messageExpiry = r.uint32()
+		case 0x03:
+			p.contentType = r.string()
+		case 0x08:
+			p.responseTopic = r.string()
+		case 0x09:
+			p.correlationData = r.binary()
+		case 0x0B:
+			p.subscriptionIDs = append(p.subscriptionIDs, r.varint())
+		case 0x11:
+			p.sessionExpiry = r.uint32()
+		case 0x12:
+			p.assignedClientID = r.string()
+		case 0x13:
+			v := r.uint16()
+			p.serverKeepAlive = &v
+		case 0x1F:
+			p.reasonString = r.string()
+		case 0x21:
+			p.receiveMaximum = r.uint16()
+		case 0x22:
+			p.topicAliasMaximum = r.uint16()
+		case 0x23:
+			p.topicAlias = r.uint16()
+		case 0x24:
+			v := r.byte()
+			p.maximumQoS = &v
+		case 0x25:
+			v := r.byte()
+			p.retainAvailable = &v
+		case 0x26:
+			p.userProperties = append(p.userProperties, userProperty{key: r.string(), value: r.string()})
+		case 0x27:
+			p.maximumPacketSize = r.uint32()
+		case 0x28:
+			v := r.byte()
+			p.wildcardAvailable = &v
+		case 0x2A:
+			v := r.byte()
+			p.sharedAvailable = &v
+		case 0x17, 0x19, 0x29: // Request problem/response information, subscription IDs available
+			r.byte()
+		case 0x18: // Will delay interval
+			r.uint32()
+		case 0x15, 0x1A, 0x1C: // Authentication method, response information, server reference
+			r.string()
+		case 0x16: // Authentication data
+			r.binary()
+		default:
+			r.fail("unknown property 0x%02X", id)
+		}
+	}
+}
+
+// writer appends MQTT data types to a byte slice
+type writer struct {
+	b []byte
+}
+
+func (w *writer) byte(v byte) {
+	w.b = append(w.b, v)
+}
+
+func (w *writer) uint16(v uint16) {
+	w.b = binary.BigEndian.AppendUint16(w.b, v)
+}
+
+func (w *writer) uint32(v uint32) {
+	w.b = binary.BigEndian.AppendUint32(w.b, v)
+}
+
+func (w *writer) varint(v int) {
+	for {
+		digit := byte(v % 128)
+		v /= 128
+		if v > 0 {
+			digit |= 0x80
+		}
+		w.b = append(w.b, digit)
+		if v == 0 {
+			return
+		}
+	}
+}
+
+func (w *writer) string(s string) {
+	w.uint16(uint16(len(s)))
+	w.b = append(w.b, s...)
+}
+
+func (w *writer) binary(b []byte) {
+	w.uint16(uint16(len(b)))
+	w.b = append(w.b, b...)
+}
+
+// props writes properties with their length prefix
+func (w *writer) props(p *properties) {
+	inner := &writer{}
+	p.write(inner)
+	w.varint(len(inner.b))
+	w.b = append(w.b, inner.b...)
+}
+
+// reader consumes MQTT data types from a packet body.
+// The first error sticks; later reads return zero values.
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) fail(format string, args ...any) {
+	if r.err == nil {
+		r.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (r *reader) len() int {
+	return len(r.b)
+}
+
+func (r *reader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.b) {
+		r.fail("truncated packet")
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) byte() byte {
+	if b := r.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) uint16() uint16 {
+	if b := r.take(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) varint() int {
+	v, mult := 0, 1
+	for i := 0; i < 4; i++ {
+		digit := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		v += int(digit&0x7F) * mult
+		if digit&0x80 == 0 {
+			return v
+		}
+		mult *= 128
+	}
+	r.fail("variable byte integer too long")
+	return 0
+}
+
+func (r *reader) string() string {
+	return string(r.binary())
+}
+
+func (r *reader) binary() []byte {
+	n := int(r.uint16())
+	if b := r.take(n); b != nil {
+		return append([]byte(nil), b...)
+	}
+	return nil
+}
+
+func (r *reader) rest() []byte {
+	v := r.b
+	r.b = nil
+	return v
+}
+
+// props reads length-prefixed properties
+func (r *reader) props() properties {
+	var p properties
+	n := r.varint()
+	inner := &reader{b: r.take(n)}
+	if r.err != nil {
+		return p
+	}
+	p.read(inner)
+	if inner.err != nil {
+		r.fail("%v", inner.err)
+	}
+	return p
+}
+
+// readVarint reads the remaining length of a fixed header
+func readVarint(r io.ByteReader) (int, error) {
+	v, mult := 0, 1
+	for i := 0; i < 4; i++ {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v += int(digit&0x7F) * mult
+		if digit&0x80 == 0 {
+			return v, nil
+		}
+		mult *= 128
+	}
+	return 0, fmt.Errorf("%w: remaining length too long", errMalformed)
+}
+
+// noEOF reports a stream that ends mid-packet as io.ErrUnexpectedEOF
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
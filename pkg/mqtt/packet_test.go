@@ -0,0 +1,308 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func roundTrip(t *testing.T, p packet, version byte) packet {
+	t.Helper()
+
+	got, err := readPacket(bufio.NewReader(bytes.NewReader(p.encode(version))), version, 0)
+	if err != nil {
+		t.Fatalf("Failed to decode %T: %v", p, err)
+	}
+	return got
+}
+
+func TestPacket_RoundTrip(t *testing.T) {
+	qos1 := byte(1)
+	keepAlive := uint16(20)
+
+	tests := []struct {
+		name    string
+		version byte
+		packet  packet
+	}{
+		{
+			name:    "connect v3",
+			version: ProtocolV311,
+			packet: &connectPacket{
+				version: ProtocolV311, clientID: "dev-1", cleanStart: true, keepAlive: 30,
+				username: "user", password: []byte("secret"),
+				will: &willMessage{topic: "status/dev-1", payload: []byte("offline"), qos: 1, retain: true},
+			},
+		},
+		{
+			name:    "connect v5",
+			version: ProtocolV5,
+			packet: &connectPacket{
+				version: ProtocolV5, clientID: "dev-1", keepAlive: 60,
+				props: properties{sessionExpiry: 3600, maximumPacketSize: 1 << 20},
+				will: &willMessage{topic: "status/dev-1", payload: []byte("offline"),
+					props: properties{contentType: "text/plain"}},
+			},
+		},
+		{
+			name:    "connack v5",
+			version: ProtocolV5,
+			packet: &connackPacket{sessionPresent: true, code: codeSuccess,
+				props: properties{receiveMaximum: 10, maximumQoS: &qos1, serverKeepAlive: &keepAlive}},
+		},
+		{
+			name:    "connack v3 refused",
+			version: ProtocolV311,
+			packet:  &connackPacket{code: codeBadCredentials},
+		},
+		{
+			name:    "publish qos0",
+			version: ProtocolV311,
+			packet:  &publishPacket{topic: "a/b", payload: []byte("hello"), retain: true},
+		},
+		{
+			name:    "publish qos2 v5",
+			version: ProtocolV5,
+			packet: &publishPacket{topic: "a/b", payload: []byte("hello"), qos: 2, id: 7, dup: true,
+				props: properties{
+					contentType: "application/json", responseTopic: "reply/1", correlationData: []byte{1, 2},
+					messageExpiry: 60, userProperties: []userProperty{{"k", "v"}, {"k", "w"}},
+				}},
+		},
+		{
+			name:    "pubrel",
+			version: ProtocolV311,
+			packet:  &ackPacket{kind: packetPubrel, id: 9},
+		},
+		{
+			name:    "puback v5 reason",
+			version: ProtocolV5,
+			packet:  &ackPacket{kind: packetPuback, id: 9, code: codeNoMatchingListeners},
+		},
+		{
+			name:    "subscribe v5",
+			version: ProtocolV5,
+			packet: &subscribePacket{id: 3, subs: []subscription{
+				{filter: "sensors/#", qos: 1, noLocal: true, retainHandling: 2},
+				{filter: "+/status", qos: 2, retainAsPublished: true},
+			}},
+		},
+		{
+			name:    "suback",
+			version: ProtocolV311,
+			packet:  &subackPacket{kind: packetSuback, id: 3, codes: []byte{1, codeUnspecified}},
+		},
+		{
+			name:    "unsubscribe",
+			version: ProtocolV311,
+			packet:  &unsubscribePacket{id: 4, filters: []string{"a/#", "b"}},
+		},
+		{
+			name:    "unsuback v5",
+			version: ProtocolV5,
+			packet:  &subackPacket{kind: packetUnsuback, id: 4, codes: []byte{0, 0x11}},
+		},
+		{
+			name:    "pingreq",
+			version: ProtocolV311,
+			packet:  &pingPacket{kind: packetPingreq},
+		},
+		{
+			name:    "disconnect v5",
+			version: ProtocolV5,
+			packet:  &disconnectPacket{code: codeSessionTakenOver, props: properties{reasonString: "bye"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roundTrip(t, tt.packet, tt.version)
+			if !reflect.DeepEqual(got, tt.packet) {
+				t.Errorf("Round trip mismatch:\n got  %+v\n want %+v", got, tt.packet)
+			}
+		})
+	}
+}
+
+func TestPacket_V311DropsV5Fields(t *testing.T) {
+	p := &publishPacket{topic: "t", payload: []byte("x"), qos: 1, id: 1,
+		props: properties{contentType: "text/plain"}}
+
+	got := roundTrip(t, p, ProtocolV311).(*publishPacket)
+	if got.props.contentType != "" || string(got.payload) != "x" {
+		t.Errorf("Expected properties to be dropped, got %+v", got)
+	}
+
+	// 3.1.1 has a single subscription failure code
+	ack := roundTrip(t, &subackPacket{kind: packetSuback, id: 1, codes: []byte{codeNotAuthorized}}, ProtocolV311).(*subackPacket)
+	if ack.codes[0] != codeUnspecified {
+		t.Errorf("Expected 0x80, got 0x%02X", ack.codes[0])
+	}
+}
+
+func TestPacket_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "truncated body", data: []byte{packetPublish << 4, 10, 0, 1}, want: io.ErrUnexpectedEOF},
+		{name: "bad string length", data: []byte{packetPublish << 4, 3, 0, 9, 'a'}, want: errMalformed},
+		{name: "qos 3", data: []byte{packetPublish<<4 | 0x06, 3, 0, 1, 'a'}, want: errMalformed},
+		{name: "unknown property", data: []byte{packetDisconnect << 4, 3, 0, 1, 0x7F}, want: errMalformed},
+		{name: "too large", data: []byte{packetPublish << 4, 0xFF, 0x7F}, want: ErrPacketTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readPacket(bufio.NewReader(bytes.NewReader(tt.data)), ProtocolV5, 1024)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestPacket_Golden checks encoding and decoding against byte sequences built
+// from the MQTT 3.1.1 and 5.0 specifications, so a mistake made the same way
+// in both directions cannot pass unnoticed
+func TestPacket_Golden(t *testing.T) {
+	tests := []struct {
+		name    string
+		version byte
+		packet  packet
+		wire    []byte
+	}{
+		{
+			// 3.1.1 section 3.1.2.10: flags 0xCE (user name, password, will QoS 1, will, clean session), keep alive 10
+			name:    "connect v3.1.1",
+			version: ProtocolV311,
+			packet: &connectPacket{
+				version: ProtocolV311, clientID: "dev", cleanStart: true, keepAlive: 10,
+				will:     &willMessage{topic: "w", payload: []byte("x"), qos: 1},
+				username: "u", password: []byte("p"),
+			},
+			wire: []byte{
+				0x10, 0x1B,
+				0x00, 0x04, 'M', 'Q', 'T', 'T', 0x04, 0xCE, 0x00, 0x0A,
+				0x00, 0x03, 'd', 'e', 'v',
+				0x00, 0x01, 'w', 0x00, 0x01, 'x',
+				0x00, 0x01, 'u', 0x00, 0x01, 'p',
+			},
+		},
+		{
+			// 5.0 section 3.1.2.11: properties length 5, session expiry interval (0x11) of 10 seconds
+			name:    "connect v5",
+			version: ProtocolV5,
+			packet: &connectPacket{
+				version: ProtocolV5, clientID: "dev", cleanStart: true, keepAlive: 10,
+				props: properties{sessionExpiry: 10},
+			},
+			wire: []byte{
+				0x10, 0x15,
+				0x00, 0x04, 'M', 'Q', 'T', 'T', 0x05, 0x02, 0x00, 0x0A,
+				0x05, 0x11, 0x00, 0x00, 0x00, 0x0A,
+				0x00, 0x03, 'd', 'e', 'v',
+			},
+		},
+		{
+			// 3.1.1 section 3.3.2.3: topic "a/b", packet identifier 10
+			name:    "publish qos1 v3.1.1",
+			version: ProtocolV311,
+			packet:  &publishPacket{qos: 1, topic: "a/b", id: 10, payload: []byte("hi")},
+			wire:    []byte{0x32, 0x09, 0x00, 0x03, 'a', '/', 'b', 0x00, 0x0A, 'h', 'i'},
+		},
+		{
+			// 5.0 section 3.3.2.3: payload format indicator (0x01), content type (0x03), user property (0x26)
+			name:    "publish with properties v5",
+			version: ProtocolV5,
+			packet: &publishPacket{qos: 1, retain: true, topic: "a/b", id: 10, payload: []byte("hi"),
+				props: properties{payloadFormat: 1, contentType: "text", userProperties: []userProperty{{"k", "v"}}}},
+			wire: []byte{
+				0x33, 0x1A,
+				0x00, 0x03, 'a', '/', 'b', 0x00, 0x0A,
+				0x10,
+				0x01, 0x01,
+				0x03, 0x00, 0x04, 't', 'e', 'x', 't',
+				0x26, 0x00, 0x01, 'k', 0x00, 0x01, 'v',
+				'h', 'i',
+			},
+		},
+		{
+			// 3.1.1 section 3.8.3: "a/b" at QoS 1 and "c/d" at QoS 2
+			name:    "subscribe v3.1.1",
+			version: ProtocolV311,
+			packet:  &subscribePacket{id: 10, subs: []subscription{{filter: "a/b", qos: 1}, {filter: "c/d", qos: 2}}},
+			wire: []byte{
+				0x82, 0x0E, 0x00, 0x0A,
+				0x00, 0x03, 'a', '/', 'b', 0x01,
+				0x00, 0x03, 'c', '/', 'd', 0x02,
+			},
+		},
+		{
+			// 5.0 section 3.8.3.1: options byte 0x2D is QoS 1, no local, retain as published, retain handling 2
+			name:    "subscribe options v5",
+			version: ProtocolV5,
+			packet: &subscribePacket{id: 10, subs: []subscription{
+				{filter: "a/b", qos: 1, noLocal: true, retainAsPublished: true, retainHandling: 2},
+				{filter: "c/d", qos: 2},
+			}},
+			wire: []byte{
+				0x82, 0x0F, 0x00, 0x0A, 0x00,
+				0x00, 0x03, 'a', '/', 'b', 0x2D,
+				0x00, 0x03, 'c', '/', 'd', 0x02,
+			},
+		},
+		{
+			// 3.1.1 section 3.9.3: granted QoS 0, granted QoS 2, failure
+			name:    "suback v3.1.1",
+			version: ProtocolV311,
+			packet:  &subackPacket{kind: packetSuback, id: 10, codes: []byte{0x00, 0x02, 0x80}},
+			wire:    []byte{0x90, 0x05, 0x00, 0x0A, 0x00, 0x02, 0x80},
+		},
+		{
+			// 5.0 section 3.9.3: granted QoS 1, granted QoS 2, not authorized (0x87)
+			name:    "suback reason codes v5",
+			version: ProtocolV5,
+			packet:  &subackPacket{kind: packetSuback, id: 10, codes: []byte{0x01, 0x02, 0x87}},
+			wire:    []byte{0x90, 0x06, 0x00, 0x0A, 0x00, 0x01, 0x02, 0x87},
+		},
+		{
+			// 3.1.1 section 3.11: UNSUBACK carries only the packet identifier
+			name:    "unsuback v3.1.1",
+			version: ProtocolV311,
+			packet:  &subackPacket{kind: packetUnsuback, id: 11},
+			wire:    []byte{0xB0, 0x02, 0x00, 0x0B},
+		},
+		{
+			// 5.0 section 3.11.3: success, no subscription existed (0x11)
+			name:    "unsuback reason codes v5",
+			version: ProtocolV5,
+			packet:  &subackPacket{kind: packetUnsuback, id: 11, codes: []byte{0x00, 0x11}},
+			wire:    []byte{0xB0, 0x05, 0x00, 0x0B, 0x00, 0x00, 0x11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.packet.encode(tt.version); !bytes.Equal(got, tt.wire) {
+				t.Errorf("Encoding mismatch:\n got  % X\n want % X", got, tt.wire)
+			}
+
+			got, err := readPacket(bufio.NewReader(bytes.NewReader(tt.wire)), tt.version, 0)
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+			// 3.1.1 UNSUBACK decodes with no codes rather than an empty slice
+			if ack, ok := got.(*subackPacket); ok && len(ack.codes) == 0 {
+				ack.codes = nil
+			}
+			if !reflect.DeepEqual(got, tt.packet) {
+				t.Errorf("Decoding mismatch:\n got  %+v\n want %+v", got, tt.packet)
+			}
+		})
+	}
+}
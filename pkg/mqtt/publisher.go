@@ -0,0 +1,156 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// PublisherEmitter publishes "net.mqtt.message" events to an MQTT broker.
+// The payload's Topic, QoS and Retained fields decide how each message is
+// published. It connects on the first Emit and keeps the connection up;
+// while disconnected, messages wait in a bounded outbound queue.
+type PublisherEmitter struct {
+	id     string
+	broker string
+	opts   options
+	stats  metrics.Counters
+
+	mu     sync.Mutex
+	client *client
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPublisherEmitter creates an emitter for broker (tcp://host:1883,
+// ssl://host:8883 or host:port)
+func NewPublisherEmitter(broker string, opts ...Option) *PublisherEmitter {
+	return &PublisherEmitter{
+		id:     fmt.Sprintf("mqtt-publisher-%s", broker),
+		broker: broker,
+		opts:   newOptions(opts),
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *PublisherEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *PublisherEmitter) Type() string {
+	return "mqtt-publisher"
+}
+
+// Emit publishes the event's message. It returns once the message has been
+// written or queued; QoS 1/2 delivery then continues across reconnects.
+// It returns ErrQueueFull when the outbound queue is full.
+func (e *PublisherEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var payload MQTTMessagePayload
+	if err := evt.DecodePayload(&payload, c); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	if !validTopic(payload.Topic) {
+		return fmt.Errorf("invalid topic %q", payload.Topic)
+	}
+	if payload.QoS > 2 {
+		return fmt.Errorf("invalid qos %d", payload.QoS)
+	}
+
+	client, err := e.connect()
+	if err != nil {
+		return err
+	}
+	return client.publish(publishPacketFor(payload))
+}
+
+// Metrics returns the emitter's traffic counters. Bytes are counted on the
+// socket, including MQTT framing; errors are failed connection attempts,
+// dropped connections and rejected or unqueueable messages.
+func (e *PublisherEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "mqtt", e.opts.clk.Wall())
+}
+
+// publishPacketFor builds the PUBLISH packet for payload. The 5.0
+// properties are dropped when the packet is encoded for 3.1.1.
+func publishPacketFor(payload MQTTMessagePayload) *publishPacket {
+	p := &publishPacket{
+		topic:   payload.Topic,
+		payload: payload.Payload,
+		qos:     payload.QoS,
+		retain:  payload.Retained,
+	}
+	p.props.contentType = payload.ContentType
+	p.props.responseTopic = payload.ResponseTopic
+	p.props.correlationData = payload.CorrelationData
+	p.props.messageExpiry = payload.MessageExpiry
+
+	// Sorted so identical events produce identical packets
+	keys := make([]string, 0, len(payload.UserProperties))
+	for k := range payload.UserProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p.props.userProperties = append(p.props.userProperties, userProperty{key: k, value: payload.UserProperties[k]})
+	}
+	return p
+}
+
+// connect starts the client on first use
+func (e *PublisherEmitter) connect() (*client, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.client != nil {
+		return e.client, nil
+	}
+
+	client, err := newClient(e.broker, e.opts, e.opts.clk, hooks{stats: &e.stats})
+	if err != nil {
+		return nil, err
+	}
+
+	var ctx context.Context
+	ctx, e.cancel = context.WithCancel(context.Background())
+	e.done = make(chan struct{})
+	go func() {
+		defer close(e.done)
+		client.run(ctx)
+	}()
+
+	e.client = client
+	return client, nil
+}
+
+// Close disconnects from the broker. Messages still queued or awaiting
+// acknowledgement are discarded.
+func (e *PublisherEmitter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.client == nil {
+		return nil
+	}
+
+	e.cancel()
+	<-e.done
+	e.client = nil
+	return nil
+}
@@ -0,0 +1,111 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+func messageEvent(t *testing.T, payload MQTTMessagePayload) *event.Event {
+	t.Helper()
+
+	evt, err := codec.NewEvent("net.mqtt.message", "test", payload, codec.JSON{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	return evt
+}
+
+// nextPublish waits for the broker to receive a publish
+func nextPublish(t *testing.T, broker *testBroker) *publishPacket {
+	t.Helper()
+
+	select {
+	case p := <-broker.received:
+		return p
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for publish")
+		return nil
+	}
+}
+
+func TestPublisherEmitter_MapsFields(t *testing.T) {
+	broker := startBroker(t, "127.0.0.1:0")
+	emitter := NewPublisherEmitter("mqtt://"+broker.addr(), WithProtocolVersion(ProtocolV5))
+	defer emitter.Close()
+
+	err := emitter.Emit(context.Background(), messageEvent(t, MQTTMessagePayload{
+		Topic:          "devices/1/state",
+		Payload:        []byte(`{"on":true}`),
+		QoS:            2,
+		Retained:       true,
+		ContentType:    "application/json",
+		UserProperties: map[string]string{"site": "north"},
+	}))
+	if err != nil {
+		t.Fatalf("Emit failed: %v", err)
+	}
+
+	p := nextPublish(t, broker)
+	if p.topic != "devices/1/state" || p.qos != 2 || !p.retain || string(p.payload) != `{"on":true}` {
+		t.Errorf("Unexpected publish: %+v", p)
+	}
+	if p.props.contentType != "application/json" || len(p.props.userProperties) != 1 || p.props.userProperties[0].value != "north" {
+		t.Errorf("Expected 5.0 properties, got %+v", p.props)
+	}
+
+	// The retained message reaches a subscriber that arrives later
+	_, sub := startSubscriber(t, broker.addr(), []string{"devices/+/state"}, WithProtocolVersion(ProtocolV5), WithQoS(2))
+	var msg MQTTMessagePayload
+	nextEvent(t, sub, "net.mqtt.message", &msg)
+	if !msg.Retained || msg.ContentType != "application/json" || msg.UserProperties["site"] != "north" {
+		t.Errorf("Unexpected delivered message: %+v", msg)
+	}
+}
+
+func TestPublisherEmitter_OfflineQueue(t *testing.T) {
+	// Reserve an address with nothing listening yet
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	emitter := NewPublisherEmitter(addr,
+		WithQueueSize(3),
+		WithBackoff(10*time.Millisecond, 20*time.Millisecond),
+	)
+	defer emitter.Close()
+
+	for _, msg := range []string{"one", "two", "three"} {
+		if err := emitter.Emit(context.Background(), messageEvent(t, MQTTMessagePayload{Topic: "log", Payload: []byte(msg), QoS: 1})); err != nil {
+			t.Fatalf("Emit %s failed: %v", msg, err)
+		}
+	}
+	err := emitter.Emit(context.Background(), messageEvent(t, MQTTMessagePayload{Topic: "log", Payload: []byte("four")}))
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+
+	broker := startBroker(t, addr)
+	for _, want := range []string{"one", "two", "three"} {
+		if p := nextPublish(t, broker); string(p.payload) != want {
+			t.Errorf("Expected %q, got %q", want, p.payload)
+		}
+	}
+}
+
+func TestPublisherEmitter_InvalidMessage(t *testing.T) {
+	emitter := NewPublisherEmitter("127.0.0.1:1883")
+	defer emitter.Close()
+
+	if err := emitter.Emit(context.Background(), messageEvent(t, MQTTMessagePayload{Topic: "a/+"})); err == nil {
+		t.Error("Expected error for wildcard topic")
+	}
+	if err := emitter.Emit(context.Background(), messageEvent(t, MQTTMessagePayload{Topic: "a", QoS: 3})); err == nil {
+		t.Error("Expected error for QoS 3")
+	}
+}
@@ -0,0 +1,220 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/google/uuid"
+)
+
+// SubscriberAdapter subscribes to topic filters on an MQTT broker and
+// publishes every received message as a "net.mqtt.message" event.
+// It reconnects with backoff, resubscribes whenever the broker has no
+// session to resume, and publishes "net.mqtt.connected" and
+// "net.mqtt.disconnected" events.
+type SubscriberAdapter struct {
+	id      string
+	broker  string
+	filters []string
+	bus     event.Bus
+	clk     clock.Clock
+	ctx     context.Context
+	opts    options
+	client  *client
+	stats   metrics.Counters
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewSubscriberAdapter creates an adapter for broker (tcp://host:1883,
+// ssl://host:8883 or host:port) subscribed to filters, which may use the
+// "+" and "#" wildcards
+func NewSubscriberAdapter(broker string, filters []string, opts ...Option) *SubscriberAdapter {
+	return &SubscriberAdapter{
+		id:      fmt.Sprintf("mqtt-subscriber-%s", broker),
+		broker:  broker,
+		filters: filters,
+		clk:     clock.System(),
+		opts:    newOptions(opts),
+	}
+}
+
+// ID returns the adapter's unique identifier
+func (a *SubscriberAdapter) ID() string {
+	return a.id
+}
+
+// Type returns the adapter type
+func (a *SubscriberAdapter) Type() string {
+	return "mqtt-subscriber"
+}
+
+// Start validates the filters and begins connecting in the background.
+// Timestamps, redial delays and timeouts are taken from clk (see clock.From);
+// keepalive pings and socket deadlines use real time.
+func (a *SubscriberAdapter) Start(ctx context.Context, bus event.Bus, clk pipeclock.Clock) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		return fmt.Errorf("adapter already running")
+	}
+
+	for _, filter := range a.filters {
+		if !validFilter(filter) {
+			return fmt.Errorf("invalid topic filter %q", filter)
+		}
+	}
+
+	a.clk = clock.From(clk)
+	client, err := newClient(a.broker, a.opts, a.clk, hooks{
+		connected: a.subscribe,
+		message:   a.message,
+		status:    a.status,
+		stats:     &a.stats,
+	})
+	if err != nil {
+		return err
+	}
+
+	a.client = client
+	a.bus = bus
+
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	a.done = make(chan struct{})
+	go func() {
+		defer close(a.done)
+		client.run(a.ctx)
+	}()
+
+	a.running = true
+	return nil
+}
+
+// Stop disconnects from the broker and stops reconnecting
+func (a *SubscriberAdapter) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.running {
+		return nil
+	}
+
+	a.cancel()
+	<-a.done
+
+	a.running = false
+	return nil
+}
+
+// ClientID returns the MQTT client identifier, or "" before Start
+func (a *SubscriberAdapter) ClientID() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.client == nil {
+		return ""
+	}
+	return a.client.clientID
+}
+
+// Metrics returns the adapter's traffic counters. Bytes are counted on the
+// socket, including MQTT framing; errors are failed connection attempts,
+// dropped connections and messages that could not be published.
+func (a *SubscriberAdapter) Metrics() metrics.NetworkMetrics {
+	return a.stats.Snapshot(a.id, a.Type(), "mqtt", a.clk.Wall())
+}
+
+// subscribe (re)subscribes on connect unless the broker resumed the session,
+// which still holds the subscriptions. A rejected filter drops the connection.
+func (a *SubscriberAdapter) subscribe(ctx context.Context, sessionPresent bool) error {
+	if sessionPresent || len(a.filters) == 0 {
+		return nil
+	}
+
+	subs := make([]subscription, len(a.filters))
+	for i, filter := range a.filters {
+		subs[i] = subscription{filter: filter, qos: a.opts.qos}
+	}
+
+	codes, err := a.client.subscribe(ctx, subs)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+	for i, code := range codes {
+		if code >= codeUnspecified && i < len(a.filters) {
+			return fmt.Errorf("subscription to %q rejected: reason 0x%02X", a.filters[i], code)
+		}
+	}
+	return nil
+}
+
+// message publishes a received message; the broker's acknowledgement
+// waits until the bus has taken it
+func (a *SubscriberAdapter) message(p *publishPacket) error {
+	payload := messagePayload(p, a.clk.Wall())
+	payload.Broker = a.broker
+
+	evt, err := codec.NewEvent("net.mqtt.message", a.id, payload, a.opts.codec)
+	if err != nil {
+		a.stats.Error()
+		return nil // Undeliverable; acknowledge rather than see it redelivered
+	}
+	evt.WithMetadata("adapter_id", a.id).
+		WithMetadata("broker", a.broker).
+		WithMetadata("topic", p.topic)
+
+	if err := a.bus.Publish(a.ctx, evt); err != nil {
+		a.stats.Error()
+		return err
+	}
+	return nil
+}
+
+// status publishes connection changes. Events are published even after
+// Stop so the final disconnect is not lost.
+func (a *SubscriberAdapter) status(eventType string, payload MQTTConnectionPayload) {
+	evt, err := codec.NewEvent(eventType, a.id, payload, a.opts.codec)
+	if err != nil {
+		return
+	}
+	evt.WithMetadata("adapter_id", a.id).
+		WithMetadata("broker", a.broker).
+		WithMetadata("client_id", payload.ClientID)
+
+	a.bus.Publish(context.WithoutCancel(a.ctx), evt)
+}
+
+// messagePayload converts a PUBLISH received at now into an event payload
+func messagePayload(p *publishPacket, now time.Time) MQTTMessagePayload {
+	payload := MQTTMessagePayload{
+		MessageID:       uuid.New().String(),
+		Topic:           p.topic,
+		Payload:         p.payload,
+		QoS:             p.qos,
+		Retained:        p.retain,
+		ContentType:     p.props.contentType,
+		ResponseTopic:   p.props.responseTopic,
+		CorrelationData: p.props.correlationData,
+		MessageExpiry:   p.props.messageExpiry,
+		Timestamp:       now,
+	}
+	if len(p.props.userProperties) > 0 {
+		payload.UserProperties = make(map[string]string, len(p.props.userProperties))
+		for _, up := range p.props.userProperties {
+			payload.UserProperties[up.key] = up.value
+		}
+	}
+	return payload
+}
@@ -0,0 +1,188 @@
+package mqtt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// startSubscriber runs a SubscriberAdapter and subscribes to its events
+func startSubscriber(t *testing.T, broker string, filters []string, opts ...Option) (*SubscriberAdapter, event.Subscription) {
+	t.Helper()
+
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.mqtt.message", "net.mqtt.connected", "net.mqtt.disconnected"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+
+	opts = append([]Option{WithBackoff(10*time.Millisecond, 50*time.Millisecond)}, opts...)
+	adapter := NewSubscriberAdapter(broker, filters, opts...)
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	t.Cleanup(func() { adapterMgr.Stop() })
+	return adapter, sub
+}
+
+// nextEvent waits for the next event of eventType, skipping others
+func nextEvent(t *testing.T, sub event.Subscription, eventType string, payload any) *event.Event {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case evt := <-sub.Events():
+			if evt.Type != eventType {
+				continue
+			}
+			if err := codec.Decode(evt, payload); err != nil {
+				t.Fatalf("Failed to decode %s: %v", eventType, err)
+			}
+			return evt
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %s", eventType)
+			return nil
+		}
+	}
+}
+
+func TestSubscriberAdapter_Wildcards(t *testing.T) {
+	for _, version := range []byte{ProtocolV311, ProtocolV5} {
+		t.Run(map[byte]string{ProtocolV311: "v3.1.1", ProtocolV5: "v5"}[version], func(t *testing.T) {
+			broker := startBroker(t, "127.0.0.1:0")
+			_, sub := startSubscriber(t, "tcp://"+broker.addr(), []string{"sensors/+/temp", "alerts/#"},
+				WithProtocolVersion(version),
+				WithQoS(1),
+			)
+
+			var conn MQTTConnectionPayload
+			nextEvent(t, sub, "net.mqtt.connected", &conn)
+
+			broker.publishTo("sensors/kitchen/temp", []byte("21.5"), 2, false)
+			broker.publishTo("sensors/kitchen/humidity", []byte("40"), 1, false)
+			broker.publishTo("alerts/fire/floor1", []byte("!"), 0, false)
+
+			var msg MQTTMessagePayload
+			evt := nextEvent(t, sub, "net.mqtt.message", &msg)
+			if msg.Topic != "sensors/kitchen/temp" || string(msg.Payload) != "21.5" || evt.Metadata["topic"] != msg.Topic {
+				t.Errorf("Unexpected message: %+v", msg)
+			}
+			if msg.QoS != 1 {
+				t.Errorf("Expected QoS downgraded to 1, got %d", msg.QoS)
+			}
+
+			// The humidity reading matches no filter
+			nextEvent(t, sub, "net.mqtt.message", &msg)
+			if msg.Topic != "alerts/fire/floor1" || msg.QoS != 0 {
+				t.Errorf("Expected alert, got %+v", msg)
+			}
+		})
+	}
+}
+
+func TestSubscriberAdapter_Retained(t *testing.T) {
+	broker := startBroker(t, "127.0.0.1:0")
+	broker.publishTo("config/rate", []byte("5s"), 1, true)
+
+	_, sub := startSubscriber(t, broker.addr(), []string{"config/#"}, WithQoS(1))
+
+	var msg MQTTMessagePayload
+	nextEvent(t, sub, "net.mqtt.message", &msg)
+	if !msg.Retained || string(msg.Payload) != "5s" {
+		t.Errorf("Expected retained message, got %+v", msg)
+	}
+
+	broker.publishTo("config/rate", []byte("10s"), 1, true)
+	nextEvent(t, sub, "net.mqtt.message", &msg)
+	if msg.Retained {
+		t.Error("Live messages must not carry the retained flag")
+	}
+}
+
+func TestSubscriberAdapter_ReconnectResubscribes(t *testing.T) {
+	broker := startBroker(t, "127.0.0.1:0")
+	_, sub := startSubscriber(t, broker.addr(), []string{"cmd/#"})
+
+	var conn MQTTConnectionPayload
+	nextEvent(t, sub, "net.mqtt.connected", &conn)
+
+	broker.dropClients()
+	nextEvent(t, sub, "net.mqtt.disconnected", &conn)
+	if conn.Error == "" {
+		t.Error("Expected the lost connection to carry an error")
+	}
+
+	nextEvent(t, sub, "net.mqtt.connected", &conn)
+	if conn.SessionPresent {
+		t.Error("Expected a clean session")
+	}
+
+	broker.publishTo("cmd/reboot", []byte("now"), 0, false)
+	var msg MQTTMessagePayload
+	nextEvent(t, sub, "net.mqtt.message", &msg)
+	if msg.Topic != "cmd/reboot" {
+		t.Errorf("Expected message after resubscribe, got %+v", msg)
+	}
+}
+
+func TestSubscriberAdapter_PersistentSession(t *testing.T) {
+	broker := startBroker(t, "127.0.0.1:0")
+	opts := []Option{
+		WithClientID("gateway-1"),
+		WithPersistentSession(time.Hour),
+		WithProtocolVersion(ProtocolV5),
+		WithQoS(1),
+	}
+
+	first := NewSubscriberAdapter(broker.addr(), []string{"orders/#"}, opts...)
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+	sub, _ := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.mqtt.connected"}})
+	defer sub.Close()
+
+	if err := first.Start(context.Background(), eng.ExternalBus(), nil); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	var conn MQTTConnectionPayload
+	nextEvent(t, sub, "net.mqtt.connected", &conn)
+	first.Stop()
+	for broker.online("gateway-1") {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Published while the gateway is offline; the broker holds it in the session
+	broker.publishTo("orders/42", []byte("queued"), 1, false)
+
+	_, events := startSubscriber(t, broker.addr(), []string{"orders/#"}, opts...)
+	nextEvent(t, events, "net.mqtt.connected", &conn)
+	if !conn.SessionPresent || conn.ClientID != "gateway-1" {
+		t.Errorf("Expected resumed session for gateway-1, got %+v", conn)
+	}
+
+	var msg MQTTMessagePayload
+	nextEvent(t, events, "net.mqtt.message", &msg)
+	if msg.Topic != "orders/42" || string(msg.Payload) != "queued" {
+		t.Errorf("Expected queued message, got %+v", msg)
+	}
+}
+
+func TestSubscriberAdapter_InvalidFilter(t *testing.T) {
+	adapter := NewSubscriberAdapter("127.0.0.1:1883", []string{"a/#/b"})
+	if err := adapter.Start(context.Background(), nil, nil); err == nil {
+		t.Error("Expected error for invalid filter")
+	}
+}
@@ -0,0 +1,275 @@
+package mqtt
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"testing"
+)
+
+// testBroker is a small in-process MQTT broker for tests. It speaks 3.1.1
+// and 5.0, keeps persistent sessions with offline QoS 1/2 queues, stores
+// retained messages and records every publish it receives.
+type testBroker struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	sessions map[string]*brokerSession
+	retained map[string]*publishPacket
+	received chan *publishPacket
+}
+
+// brokerSession is the broker side of one client's session.
+// conn is changed with both the broker lock and writeMu held.
+type brokerSession struct {
+	id         string
+	version    byte
+	conn       net.Conn // nil while offline
+	subs       map[string]byte
+	offline    []*publishPacket
+	nextID     uint16
+	persistent bool
+	writeMu    sync.Mutex
+}
+
+// startBroker runs a broker on addr ("127.0.0.1:0" for any port)
+func startBroker(t *testing.T, addr string) *testBroker {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	b := &testBroker{
+		ln:       ln,
+		sessions: make(map[string]*brokerSession),
+		retained: make(map[string]*publishPacket),
+		received: make(chan *publishPacket, 256),
+	}
+	t.Cleanup(b.close)
+
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(nc)
+		}
+	}()
+	return b
+}
+
+// addr returns the broker's address
+func (b *testBroker) addr() string {
+	return b.ln.Addr().String()
+}
+
+// close stops listening and drops every client
+func (b *testBroker) close() {
+	b.ln.Close()
+	b.dropClients()
+}
+
+// dropClients closes every connection without touching sessions
+func (b *testBroker) dropClients() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range b.sessions {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}
+}
+
+// online reports whether clientID has a live connection
+func (b *testBroker) online(clientID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.sessions[clientID]
+	return ok && s.conn != nil
+}
+
+// publishTo routes a message as if a client had published it
+func (b *testBroker) publishTo(topic string, payload []byte, qos byte, retain bool) {
+	b.route(&publishPacket{topic: topic, payload: payload, qos: qos, retain: retain})
+}
+
+func (b *testBroker) serve(nc net.Conn) {
+	defer nc.Close()
+	br := bufio.NewReader(nc)
+
+	p, err := readPacket(br, 0, 0)
+	if err != nil {
+		return
+	}
+	connect, ok := p.(*connectPacket)
+	if !ok {
+		return
+	}
+	version := connect.version
+
+	b.mu.Lock()
+	s, present := b.sessions[connect.clientID]
+	if present && s.conn != nil {
+		s.conn.Close() // Take over
+	}
+	if !present || connect.cleanStart {
+		s = &brokerSession{id: connect.clientID, subs: make(map[string]byte)}
+		b.sessions[connect.clientID] = s
+		present = false
+	}
+	s.writeMu.Lock()
+	s.version = version
+	s.conn = nc
+	s.writeMu.Unlock()
+	s.persistent = !connect.cleanStart
+	offline := s.offline
+	s.offline = nil
+	b.mu.Unlock()
+
+	s.write(&connackPacket{sessionPresent: present})
+	for _, msg := range offline {
+		s.deliver(msg, msg.qos)
+	}
+
+	defer func() {
+		b.mu.Lock()
+		if s.conn == nc {
+			s.writeMu.Lock()
+			s.conn = nil
+			s.writeMu.Unlock()
+			if !s.persistent {
+				delete(b.sessions, s.id)
+			}
+		}
+		b.mu.Unlock()
+	}()
+
+	for {
+		p, err := readPacket(br, version, 0)
+		if err != nil {
+			return
+		}
+
+		switch p := p.(type) {
+		case *publishPacket:
+			switch p.qos {
+			case 1:
+				s.write(&ackPacket{kind: packetPuback, id: p.id})
+			case 2:
+				s.write(&ackPacket{kind: packetPubrec, id: p.id})
+				if p.dup {
+					continue // Already routed
+				}
+			}
+			b.received <- p
+			b.route(p)
+
+		case *ackPacket:
+			switch p.kind {
+			case packetPubrec:
+				s.write(&ackPacket{kind: packetPubrel, id: p.id})
+			case packetPubrel:
+				s.write(&ackPacket{kind: packetPubcomp, id: p.id})
+			}
+
+		case *subscribePacket:
+			codes := make([]byte, len(p.subs))
+			b.mu.Lock()
+			for i, sub := range p.subs {
+				s.subs[sub.filter] = sub.qos
+				codes[i] = sub.qos
+			}
+			var retained []*publishPacket
+			for topic, msg := range b.retained {
+				for _, sub := range p.subs {
+					if matchTopic(sub.filter, topic) {
+						retained = append(retained, msg)
+						break
+					}
+				}
+			}
+			b.mu.Unlock()
+
+			s.write(&subackPacket{kind: packetSuback, id: p.id, codes: codes})
+			for _, msg := range retained {
+				s.deliver(msg, msg.qos)
+			}
+
+		case *pingPacket:
+			s.write(&pingPacket{kind: packetPingresp})
+
+		case *disconnectPacket:
+			return
+		}
+	}
+}
+
+// route stores retained messages and delivers p to matching sessions
+func (b *testBroker) route(p *publishPacket) {
+	b.mu.Lock()
+	if p.retain {
+		b.retained[p.topic] = p
+	}
+
+	type target struct {
+		s   *brokerSession
+		qos byte
+	}
+	// Forwarded messages keep the retain flag only when sent for a new subscription
+	fwd := *p
+	fwd.retain = false
+
+	var targets []target
+	for _, s := range b.sessions {
+		for filter, qos := range s.subs {
+			if !matchTopic(filter, p.topic) {
+				continue
+			}
+			qos = min(qos, p.qos)
+			if s.conn == nil {
+				if qos > 0 {
+					s.offline = append(s.offline, &fwd)
+				}
+			} else {
+				targets = append(targets, target{s, qos})
+			}
+			break
+		}
+	}
+	b.mu.Unlock()
+
+	for _, tg := range targets {
+		tg.s.deliver(&fwd, tg.qos)
+	}
+}
+
+// deliver sends a message to the session's client
+func (s *brokerSession) deliver(p *publishPacket, qos byte) {
+	out := *p
+	out.qos = qos
+	out.dup = false
+	if qos > 0 {
+		s.writeMu.Lock()
+		s.nextID++
+		if s.nextID == 0 {
+			s.nextID = 1
+		}
+		out.id = s.nextID
+		s.writeMu.Unlock()
+	}
+	s.write(&out)
+}
+
+func (s *brokerSession) write(p packet) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if s.conn != nil {
+		writePacket(s.conn, p, s.version)
+	}
+}
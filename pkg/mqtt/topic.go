@@ -0,0 +1,56 @@
+package mqtt
+
+import "strings"
+
+// validTopic reports whether topic is a valid topic name for publishing
+func validTopic(topic string) bool {
+	return topic != "" && len(topic) <= 65535 &&
+		!strings.ContainsAny(topic, "+#\x00")
+}
+
+// validFilter reports whether filter is a valid topic filter.
+// "+" must occupy a whole level; "#" must occupy the last level.
+func validFilter(filter string) bool {
+	if filter == "" || len(filter) > 65535 || strings.ContainsRune(filter, 0) {
+		return false
+	}
+
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if strings.ContainsAny(level, "+#") && len(level) > 1 {
+			return false
+		}
+		if level == "#" && i != len(levels)-1 {
+			return false
+		}
+	}
+	return true
+}
+
+// matchTopic reports whether topic matches filter. Topics starting with "$"
+// are not matched by a leading wildcard.
+func matchTopic(filter, topic string) bool {
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+
+	for {
+		fLevel, fRest, fMore := strings.Cut(filter, "/")
+		tLevel, tRest, tMore := strings.Cut(topic, "/")
+
+		switch {
+		case fLevel == "#":
+			return true
+		case fLevel != "+" && fLevel != tLevel:
+			return false
+		case !fMore && !tMore:
+			return true
+		case !tMore:
+			// "a/#" also matches "a"
+			return fRest == "#"
+		case !fMore:
+			return false
+		}
+		filter, topic = fRest, tRest
+	}
+}
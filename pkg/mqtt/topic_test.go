@@ -0,0 +1,51 @@
+package mqtt
+
+import "testing"
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		filter string
+		topic  string
+		want   bool
+	}{
+		{"sensors/temp", "sensors/temp", true},
+		{"sensors/temp", "sensors/humidity", false},
+		{"sensors/+", "sensors/temp", true},
+		{"sensors/+", "sensors/temp/1", false},
+		{"sensors/+/1", "sensors/temp/1", true},
+		{"sensors/#", "sensors", true},
+		{"sensors/#", "sensors/temp/1", true},
+		{"#", "anything/at/all", true},
+		{"+/+", "a/b", true},
+		{"+", "a/b", false},
+		{"+/temp", "/temp", true},
+		{"#", "$SYS/uptime", false},
+		{"+/uptime", "$SYS/uptime", false},
+		{"$SYS/#", "$SYS/uptime", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchTopic(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("matchTopic(%q, %q) = %v, want %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestValidFilter(t *testing.T) {
+	valid := []string{"a", "a/b", "a/+", "+/b", "#", "a/#", "+/+/#", "/"}
+	invalid := []string{"", "a#", "a/#/b", "a+/b", "a/b+", "##"}
+
+	for _, f := range valid {
+		if !validFilter(f) {
+			t.Errorf("Expected %q to be valid", f)
+		}
+	}
+	for _, f := range invalid {
+		if validFilter(f) {
+			t.Errorf("Expected %q to be invalid", f)
+		}
+	}
+	if validTopic("a/+") || validTopic("") || !validTopic("a/b") {
+		t.Error("validTopic must reject wildcards and empty topics")
+	}
+}
@@ -0,0 +1,70 @@
+package mqtt
+
+import "time"
+
+// MQTTMessagePayload represents a "net.mqtt.message" event.
+// The SubscriberAdapter publishes it for every received message, and the
+// PublisherEmitter consumes it: Topic, QoS and Retained decide how the
+// message is published.
+type MQTTMessagePayload struct {
+	// Identity
+	MessageID string `json:"message_id"` // UUID
+
+	// MQTT data
+	Topic    string `json:"topic"`    // sensors/temp
+	Payload  []byte `json:"payload"`  // Message content
+	QoS      byte   `json:"qos"`      // 0, 1, or 2
+	Retained bool   `json:"retained"` // Retained flag
+
+	// MQTT 5.0 properties
+	ContentType     string            `json:"content_type,omitempty"`     // MIME type of Payload
+	ResponseTopic   string            `json:"response_topic,omitempty"`   // Topic for request/response replies
+	CorrelationData []byte            `json:"correlation_data,omitempty"` // Matches replies to requests
+	MessageExpiry   uint32            `json:"message_expiry,omitempty"`   // Seconds until the broker discards it
+	UserProperties  map[string]string `json:"user_properties,omitempty"`  // Application properties
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"`           // When received
+	Broker    string    `json:"broker,omitempty"`    // Broker address
+	ClientID  string    `json:"client_id,omitempty"` // Publishing device (BrokerAdapter only)
+}
+
+// MQTTConnectionPayload represents "net.mqtt.connected" and
+// "net.mqtt.disconnected" events
+type MQTTConnectionPayload struct {
+	// Identity
+	ClientID string `json:"client_id"` // MQTT client identifier
+	Broker   string `json:"broker"`    // Broker address
+
+	// Session
+	SessionPresent bool `json:"session_present,omitempty"` // Broker resumed a persistent session
+	Attempt        int  `json:"attempt,omitempty"`         // Dial attempts for this connection
+
+	// Error data
+	Error string `json:"error,omitempty"` // Why the connection was lost or refused
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When connected or disconnected
+}
+
+// MQTTClientPayload represents "net.mqtt.connect" and "net.mqtt.disconnect"
+// events published by a BrokerAdapter for its clients
+type MQTTClientPayload struct {
+	// Identity
+	ClientID string `json:"client_id"`          // MQTT client identifier
+	Username string `json:"username,omitempty"` // Username from CONNECT
+
+	// Session
+	ProtocolVersion byte `json:"protocol_version"`          // 4 (3.1.1) or 5 (5.0)
+	SessionPresent  bool `json:"session_present,omitempty"` // A persistent session was resumed
+
+	// Close data
+	Reason string `json:"reason,omitempty"` // disconnect, eof, timeout, taken_over, protocol_error, error, shutdown or rejected
+	Error  string `json:"error,omitempty"`  // Underlying error, if any
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Client IP:port
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When connected or disconnected
+}
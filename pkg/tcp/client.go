@@ -0,0 +1,363 @@
+package tcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/internal/backoff"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotConnected is returned when an outbound target could not be reached within the dial timeout
+	ErrNotConnected = errors.New("tcp target not connected")
+
+	// ErrTooManyTargets is returned when sending to a new address while the pool is full
+	ErrTooManyTargets = errors.New("tcp target pool full")
+)
+
+// ClientEmitter writes frames described by "net.tcp.send" events, either back
+// on an open connection by ConnectionID or to a host:port target through a
+// pool of outbound connections.
+//
+// Pooled connections are kept open and redialed with backoff when they drop.
+// A target nothing has been sent to for the idle timeout is closed and
+// dropped from the pool, and the pool holds at most WithMaxTargets
+// addresses. Frames they receive are published as "net.tcp.data" events, their lifecycle
+// as "net.tcp.open" / "net.tcp.close", and failed dials and writes as
+// "net.tcp.error" events, all with the target in the "address" metadata.
+type ClientEmitter struct {
+	id     string
+	bus    event.Bus
+	opts   options
+	dialer net.Dialer
+	stats  metrics.Counters
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	targets  map[string]*target // address → target
+	evicting bool               // Whether the idle eviction loop is running
+	wg       sync.WaitGroup
+}
+
+// target is a pooled outbound connection maintained by its own goroutine
+type target struct {
+	addr     string
+	ctx      context.Context // Done once the target is evicted or the emitter closed
+	cancel   context.CancelFunc
+	lastUsed atomic.Int64 // Clock reading (MonoTime) of the last send
+
+	mu    sync.Mutex
+	conn  *Conn
+	ready chan struct{} // Closed while conn is set
+}
+
+// NewClientEmitter creates a TCP emitter that publishes connection events and errors to bus
+func NewClientEmitter(bus event.Bus, opts ...Option) *ClientEmitter {
+	o := newOptions(opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ClientEmitter{
+		id:      "tcp-client-emitter",
+		bus:     bus,
+		opts:    o,
+		dialer:  net.Dialer{Timeout: o.dialTimeout},
+		ctx:     ctx,
+		cancel:  cancel,
+		targets: make(map[string]*target),
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *ClientEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *ClientEmitter) Type() string {
+	return "tcp-client"
+}
+
+// Emit writes the event's data as one frame. The write is counted in the
+// metrics of whoever owns the connection: this emitter for pooled targets,
+// the listener for connections addressed by ID.
+func (e *ClientEmitter) Emit(ctx context.Context, evt *event.Event) error {
+	conn, payload, err := e.resolve(ctx, evt)
+	if err != nil {
+		e.stats.Error()
+		return err
+	}
+
+	if err := conn.WriteFrame(payload.Data); err != nil {
+		// A failed write leaves the stream mid-frame, so drop the connection
+		conn.Close()
+		e.publishError(conn.id, payload.Address, "write", err)
+		return err
+	}
+	return nil
+}
+
+// resolve decodes a send event and finds the connection it targets
+func (e *ClientEmitter) resolve(ctx context.Context, evt *event.Event) (*Conn, TCPSendPayload, error) {
+	var payload TCPSendPayload
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return nil, payload, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	if err := evt.DecodePayload(&payload, c); err != nil {
+		return nil, payload, fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	switch {
+	case payload.ConnectionID != "":
+		conn, ok := GetConnection(payload.ConnectionID)
+		if !ok {
+			return nil, payload, fmt.Errorf("no connection found for ID %s", payload.ConnectionID)
+		}
+		return conn, payload, nil
+	case payload.Address != "":
+		conn, err := e.connect(ctx, payload.Address)
+		return conn, payload, err
+	default:
+		return nil, payload, fmt.Errorf("send event has neither connection ID nor address")
+	}
+}
+
+// connect returns the pooled connection for addr, waiting up to the dial
+// timeout while it is being (re)established
+func (e *ClientEmitter) connect(ctx context.Context, addr string) (*Conn, error) {
+	t, err := e.target(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.opts.dialTimeout)
+	defer cancel()
+
+	for {
+		t.mu.Lock()
+		conn, ready := t.conn, t.ready
+		t.mu.Unlock()
+		if conn != nil {
+			return conn, nil
+		}
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s", ErrNotConnected, addr)
+		}
+	}
+}
+
+// target returns the pool entry for addr, starting its connection on first use
+func (e *ClientEmitter) target(addr string) (*target, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.ctx.Err() != nil {
+		return nil, fmt.Errorf("emitter closed")
+	}
+	if t, ok := e.targets[addr]; ok {
+		t.lastUsed.Store(int64(e.opts.clk.Now()))
+		return t, nil
+	}
+	if e.opts.maxTargets > 0 && len(e.targets) >= e.opts.maxTargets {
+		return nil, fmt.Errorf("%w: %d targets", ErrTooManyTargets, len(e.targets))
+	}
+
+	t := &target{addr: addr, ready: make(chan struct{})}
+	t.ctx, t.cancel = context.WithCancel(e.ctx)
+	t.lastUsed.Store(int64(e.opts.clk.Now()))
+	e.targets[addr] = t
+
+	e.wg.Add(1)
+	go e.maintain(t)
+
+	if e.opts.idleTimeout > 0 && !e.evicting {
+		e.evicting = true
+		e.wg.Add(1)
+		go e.evictIdle()
+	}
+	return t, nil
+}
+
+// evictIdle closes and forgets targets that have not been sent to for the
+// idle timeout, until the emitter is closed
+func (e *ClientEmitter) evictIdle() {
+	defer e.wg.Done()
+
+	ticker := e.opts.clk.NewTicker(max(e.opts.idleTimeout/2, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C():
+		}
+
+		cutoff := int64(e.opts.clk.Now()) - int64(e.opts.idleTimeout)
+		e.mu.Lock()
+		for addr, t := range e.targets {
+			if t.lastUsed.Load() < cutoff {
+				delete(e.targets, addr)
+				t.cancel()
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+// maintain keeps a target connected until it is evicted or the emitter is closed
+func (e *ClientEmitter) maintain(t *target) {
+	defer e.wg.Done()
+	defer t.cancel()
+
+	attempt := 0
+	for {
+		nc, err := e.dialer.DialContext(t.ctx, "tcp", t.addr)
+		if err != nil {
+			if t.ctx.Err() != nil {
+				return
+			}
+			attempt++
+			e.stats.Error()
+			e.publishError("", t.addr, "dial", err)
+		} else {
+			connected := e.opts.clk.Now()
+			e.serve(t, nc)
+			// Back off before redialing a connection that dropped, and keep
+			// escalating while connections drop soon after they open
+			if e.opts.clk.Since(connected) >= backoff.StableAfter {
+				attempt = 1
+			} else {
+				attempt++
+			}
+		}
+
+		wait := e.opts.clk.NewTimer(e.opts.backoff.Delay(attempt))
+		select {
+		case <-t.ctx.Done():
+			wait.Stop()
+			return
+		case <-wait.C():
+		}
+	}
+}
+
+// serve publishes a pooled connection's frames until it drops
+func (e *ClientEmitter) serve(t *target, nc net.Conn) {
+	connID := uuid.New().String()
+	conn := newConn(connID, nc, e.opts.framer, e.opts.writeTimeout, &e.stats)
+	remoteAddr, localAddr := conn.RemoteAddr(), conn.LocalAddr()
+	e.stats.Opened()
+
+	// Close stops the read loop when the target is evicted or the emitter closed
+	stop := context.AfterFunc(t.ctx, func() { nc.Close() })
+	defer stop()
+
+	globalConnections.Store(connID, conn)
+	t.mu.Lock()
+	t.conn = conn
+	close(t.ready)
+	t.mu.Unlock()
+
+	e.publish("net.tcp.open", connID, t.addr, TCPOpenPayload{
+		ConnectionID: connID,
+		RemoteAddr:   remoteAddr,
+		LocalAddr:    localAddr,
+		Timestamp:    e.opts.clk.Wall(),
+	})
+
+	framesIn, bytesIn, err := readFrames(nc, e.opts, func(frame []byte) {
+		e.stats.Received(len(frame))
+		e.publish("net.tcp.data", connID, t.addr, TCPDataPayload{
+			ConnectionID: connID,
+			Data:         frame,
+			RemoteAddr:   remoteAddr,
+			LocalAddr:    localAddr,
+			Timestamp:    e.opts.clk.Wall(),
+		})
+	})
+
+	t.mu.Lock()
+	t.conn = nil
+	t.ready = make(chan struct{})
+	t.mu.Unlock()
+	globalConnections.Delete(connID)
+	nc.Close()
+	e.stats.Closed()
+
+	reason, errText := closeReason(err)
+	if failed(reason) {
+		e.stats.Error()
+	}
+	e.publish("net.tcp.close", connID, t.addr, TCPClosePayload{
+		ConnectionID: connID,
+		Reason:       reason,
+		Error:        errText,
+		FramesIn:     framesIn,
+		BytesIn:      bytesIn,
+		RemoteAddr:   remoteAddr,
+		LocalAddr:    localAddr,
+		Timestamp:    e.opts.clk.Wall(),
+	})
+}
+
+// Metrics returns the emitter's traffic counters: pooled connections, frame
+// payload bytes and failed dials, writes and sends
+func (e *ClientEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "tcp", e.opts.clk.Wall())
+}
+
+// publishError publishes a "net.tcp.error" event
+func (e *ClientEmitter) publishError(connID, addr, op string, err error) {
+	e.publish("net.tcp.error", connID, addr, TCPErrorPayload{
+		ConnectionID: connID,
+		Address:      addr,
+		Op:           op,
+		Error:        err.Error(),
+		Timestamp:    e.opts.clk.Wall(),
+	})
+}
+
+// publish encodes payload with the emitter codec and publishes it
+func (e *ClientEmitter) publish(eventType, connID, addr string, payload any) {
+	if e.bus == nil {
+		return
+	}
+	evt, err := codec.NewEvent(eventType, e.id, payload, e.opts.codec)
+	if err != nil {
+		return
+	}
+	evt.WithMetadata("emitter_id", e.id)
+	if connID != "" {
+		evt.WithMetadata("connection_id", connID)
+	}
+	if addr != "" {
+		evt.WithMetadata("address", addr)
+	}
+
+	e.bus.Publish(context.Background(), evt)
+}
+
+// Close closes all pooled connections and stops reconnecting.
+// Inbound connections belong to their listener and stay open.
+func (e *ClientEmitter) Close() error {
+	e.mu.Lock()
+	e.cancel()
+	e.mu.Unlock()
+
+	e.wg.Wait()
+	return nil
+}
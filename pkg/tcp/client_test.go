@@ -0,0 +1,204 @@
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+func sendEvent(t *testing.T, payload TCPSendPayload) *event.Event {
+	t.Helper()
+
+	evt, err := codec.NewEvent("net.tcp.send", "test", payload, codec.JSON{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	return evt
+}
+
+func TestClientEmitter_ReplyOnConnection(t *testing.T) {
+	adapter, eng, sub := startListener(t, WithFramer(Lines()))
+	nc := dial(t, adapter)
+
+	var open TCPOpenPayload
+	nextEvent(t, sub, "net.tcp.open", &open)
+
+	emitter := NewClientEmitter(eng.ExternalBus(), WithFramer(Lines()))
+	defer emitter.Close()
+
+	if err := emitter.Emit(context.Background(), sendEvent(t, TCPSendPayload{
+		ConnectionID: open.ConnectionID,
+		Data:         []byte("ACK"),
+	})); err != nil {
+		t.Fatalf("Emit failed: %v", err)
+	}
+
+	nc.SetReadDeadline(time.Now().Add(time.Second))
+	line, err := bufio.NewReader(nc).ReadString('\n')
+	if err != nil || line != "ACK\n" {
+		t.Errorf("Expected framed reply, got %q (%v)", line, err)
+	}
+
+	if err := emitter.Emit(context.Background(), sendEvent(t, TCPSendPayload{ConnectionID: "missing"})); err == nil {
+		t.Error("Expected error for unknown connection")
+	}
+}
+
+func TestClientEmitter_PoolReconnect(t *testing.T) {
+	framer := LengthPrefixed(2, binary.BigEndian)
+
+	// Device stand-in: reads one frame, answers it, then hangs up
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 4)
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			frame, err := framer.ReadFrame(bufio.NewReader(nc), 1024)
+			if err == nil {
+				received <- string(frame)
+				framer.WriteFrame(nc, append([]byte("re:"), frame...))
+			}
+			time.Sleep(50 * time.Millisecond)
+			nc.Close()
+		}
+	}()
+
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.tcp.open", "net.tcp.data", "net.tcp.close"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	emitter := NewClientEmitter(eng.ExternalBus(),
+		WithFramer(framer),
+		WithBackoff(10*time.Millisecond, 50*time.Millisecond),
+	)
+	defer emitter.Close()
+
+	addr := ln.Addr().String()
+	for i, msg := range []string{"one", "two"} {
+		if err := emitter.Emit(context.Background(), sendEvent(t, TCPSendPayload{Address: addr, Data: []byte(msg)})); err != nil {
+			t.Fatalf("Emit %d failed: %v", i, err)
+		}
+		select {
+		case got := <-received:
+			if got != msg {
+				t.Errorf("Expected %q, got %q", msg, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for frame %q", msg)
+		}
+
+		var reply TCPDataPayload
+		evt := nextEvent(t, sub, "net.tcp.data", &reply)
+		if string(reply.Data) != "re:"+msg || evt.Metadata["address"] != addr {
+			t.Errorf("Expected reply event for %s, got %q (%v)", addr, reply.Data, evt.Metadata)
+		}
+
+		// The device hangs up; the next send goes over a redialed connection
+		var closed TCPClosePayload
+		nextEvent(t, sub, "net.tcp.close", &closed)
+		if closed.Reason != "eof" {
+			t.Errorf("Expected eof, got %q", closed.Reason)
+		}
+	}
+}
+
+func TestClientEmitter_DialError(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close() // Nothing listens here now
+
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.tcp.error"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	emitter := NewClientEmitter(eng.ExternalBus(), WithDialTimeout(200*time.Millisecond), WithBackoff(time.Hour, time.Hour))
+	defer emitter.Close()
+
+	err = emitter.Emit(context.Background(), sendEvent(t, TCPSendPayload{Address: addr, Data: []byte("x")}))
+	if !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Expected ErrNotConnected, got %v", err)
+	}
+
+	var dialErr TCPErrorPayload
+	nextEvent(t, sub, "net.tcp.error", &dialErr)
+	if dialErr.Op != "dial" || dialErr.Address != addr {
+		t.Errorf("Unexpected error payload: %+v", dialErr)
+	}
+}
+
+func TestClientEmitter_IdleEvictionAndMaxTargets(t *testing.T) {
+	// Two addresses nothing listens on
+	var addrs []string
+	for range 2 {
+		ln, _ := net.Listen("tcp", "127.0.0.1:0")
+		addrs = append(addrs, ln.Addr().String())
+		ln.Close()
+	}
+
+	clk := clock.NewFake(time.Now())
+	emitter := NewClientEmitter(nil,
+		WithDialTimeout(50*time.Millisecond),
+		WithBackoff(10*time.Millisecond, 10*time.Millisecond),
+		WithIdleTimeout(100*time.Millisecond),
+		WithMaxTargets(1),
+		WithClock(clk),
+	)
+	defer emitter.Close()
+
+	send := func(addr string) error {
+		return emitter.Emit(context.Background(), sendEvent(t, TCPSendPayload{Address: addr, Data: []byte("x")}))
+	}
+	if err := send(addrs[0]); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Expected ErrNotConnected, got %v", err)
+	}
+	if err := send(addrs[1]); !errors.Is(err, ErrTooManyTargets) {
+		t.Errorf("Expected ErrTooManyTargets, got %v", err)
+	}
+
+	// The unreachable target stops redialing once idle on the emitter's
+	// clock, freeing its slot
+	clk.BlockUntil(2) // The eviction ticker and the redial timer
+	clk.Advance(150 * time.Millisecond)
+	pooled := 1
+	for deadline := time.Now().Add(2 * time.Second); pooled != 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		emitter.mu.Lock()
+		pooled = len(emitter.targets)
+		emitter.mu.Unlock()
+	}
+	if pooled != 0 {
+		t.Errorf("Expected the idle target to be evicted, %d pooled", pooled)
+	}
+	if err := send(addrs[1]); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Expected the freed slot to take a new target, got %v", err)
+	}
+}
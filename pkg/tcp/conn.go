@@ -0,0 +1,132 @@
+package tcp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+)
+
+// Conn is an open TCP connection tracked in the connection registry
+type Conn struct {
+	id     string
+	nc     net.Conn
+	framer Framer
+	stats  *metrics.Counters // Owning adapter's or emitter's counters
+
+	bytesOut atomic.Uint64 // Payload bytes written
+
+	writeTimeout time.Duration
+	writeMu      sync.Mutex
+}
+
+// newConn wraps an accepted or dialed connection; frames written on it are
+// counted in stats
+func newConn(id string, nc net.Conn, framer Framer, writeTimeout time.Duration, stats *metrics.Counters) *Conn {
+	return &Conn{
+		id:           id,
+		nc:           nc,
+		framer:       framer,
+		stats:        stats,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// ID returns the connection ID
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// RemoteAddr returns the peer's address
+func (c *Conn) RemoteAddr() string {
+	return c.nc.RemoteAddr().String()
+}
+
+// LocalAddr returns the local address
+func (c *Conn) LocalAddr() string {
+	return c.nc.LocalAddr().String()
+}
+
+// WriteFrame writes data as one frame using the connection's framer
+func (c *Conn) WriteFrame(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.writeTimeout > 0 {
+		c.nc.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+	if err := c.framer.WriteFrame(c.nc, data); err != nil {
+		c.stats.Error()
+		return fmt.Errorf("failed to write to connection %s: %w", c.id, err)
+	}
+	c.stats.Sent(len(data))
+	c.bytesOut.Add(uint64(len(data)))
+	return nil
+}
+
+// Close closes the connection
+func (c *Conn) Close() error {
+	return c.nc.Close()
+}
+
+// readFrames passes frames read from nc to onFrame until reading fails,
+// applying the read deadline and maximum frame size from opts. It returns the
+// frames and payload bytes read and the error that ended the loop.
+func readFrames(nc net.Conn, opts options, onFrame func(frame []byte)) (frames, bytes uint64, err error) {
+	reader := bufio.NewReader(nc)
+	for {
+		if opts.readTimeout > 0 {
+			nc.SetReadDeadline(time.Now().Add(opts.readTimeout))
+		}
+
+		frame, err := opts.framer.ReadFrame(reader, opts.maxFrameSize)
+		if err != nil {
+			return frames, bytes, err
+		}
+
+		frames++
+		bytes += uint64(len(frame))
+		onFrame(frame)
+	}
+}
+
+// closeReason classifies the error that ended a read loop
+func closeReason(err error) (reason, errText string) {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, io.EOF):
+		return "eof", ""
+	case errors.Is(err, ErrFrameTooLarge):
+		return "frame_too_large", err.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout", err.Error()
+	case errors.Is(err, net.ErrClosed):
+		return "shutdown", ""
+	default:
+		return "error", err.Error()
+	}
+}
+
+// failed reports whether a close reason from closeReason is a failure
+// rather than an orderly close
+func failed(reason string) bool {
+	return reason != "eof" && reason != "shutdown"
+}
+
+// Global connection registry (shared by adapters and emitters)
+var globalConnections sync.Map
+
+// GetConnection retrieves an open connection by ID
+func GetConnection(connectionID string) (*Conn, bool) {
+	val, ok := globalConnections.Load(connectionID)
+	if !ok {
+		return nil, false
+	}
+	return val.(*Conn), true
+}
@@ -0,0 +1,211 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrFrameTooLarge is returned when a frame exceeds the maximum frame size
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// Framer splits a byte stream into frames and writes frames back to a stream.
+// The same Framer is used by the listener to read and by the emitter to write.
+type Framer interface {
+	// ReadFrame reads the next frame, failing with ErrFrameTooLarge when it
+	// would exceed max bytes
+	ReadFrame(r *bufio.Reader, max int) ([]byte, error)
+
+	// WriteFrame writes data as one frame
+	WriteFrame(w io.Writer, data []byte) error
+}
+
+// Raw returns a Framer without framing: each read yields whatever bytes are
+// available (up to the maximum frame size) and writes are sent as-is
+func Raw() Framer {
+	return rawFramer{}
+}
+
+type rawFramer struct{}
+
+func (rawFramer) ReadFrame(r *bufio.Reader, max int) ([]byte, error) {
+	buf := make([]byte, min(max, 32*1024))
+	n, err := r.Read(buf)
+	if n > 0 {
+		return buf[:n], nil
+	}
+	return nil, err
+}
+
+func (rawFramer) WriteFrame(w io.Writer, data []byte) error {
+	_, err := w.Write(data)
+	return err
+}
+
+// Delimited returns a Framer for frames terminated by delim.
+// The delimiter is stripped on read and appended on write.
+func Delimited(delim byte) Framer {
+	return delimitedFramer{delim: delim}
+}
+
+// Lines returns a Framer for newline-terminated frames.
+// A trailing "\r" is stripped as well, so CRLF input is accepted.
+func Lines() Framer {
+	return delimitedFramer{delim: '\n', trimCR: true}
+}
+
+type delimitedFramer struct {
+	delim  byte
+	trimCR bool
+}
+
+func (f delimitedFramer) ReadFrame(r *bufio.Reader, max int) ([]byte, error) {
+	var frame []byte
+	for {
+		chunk, err := r.ReadSlice(f.delim)
+		frame = append(frame, chunk...)
+
+		size := len(frame)
+		if err == nil {
+			size-- // Delimiter
+		}
+		if size > max {
+			return nil, fmt.Errorf("%w: over %d bytes without delimiter", ErrFrameTooLarge, max)
+		}
+
+		switch {
+		case err == nil:
+			frame = frame[:size]
+			if f.trimCR {
+				frame = bytes.TrimSuffix(frame, []byte{'\r'})
+			}
+			return frame, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && len(frame) > 0:
+			return nil, io.ErrUnexpectedEOF
+		default:
+			return nil, err
+		}
+	}
+}
+
+func (f delimitedFramer) WriteFrame(w io.Writer, data []byte) error {
+	if bytes.IndexByte(data, f.delim) >= 0 {
+		return fmt.Errorf("frame contains delimiter %q", f.delim)
+	}
+	_, err := w.Write(append(data[:len(data):len(data)], f.delim))
+	return err
+}
+
+// LengthPrefixed returns a Framer for frames preceded by their length as an
+// unsigned integer of width bytes (1, 2, 4 or 8) in the given byte order.
+// It panics for any other width.
+func LengthPrefixed(width int, order binary.ByteOrder) Framer {
+	switch width {
+	case 1, 2, 4, 8:
+	default:
+		panic(fmt.Sprintf("tcp: invalid length prefix width %d", width))
+	}
+	return lengthPrefixedFramer{width: width, order: order}
+}
+
+type lengthPrefixedFramer struct {
+	width int
+	order binary.ByteOrder
+}
+
+func (f lengthPrefixedFramer) ReadFrame(r *bufio.Reader, max int) ([]byte, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:f.width]); err != nil {
+		return nil, err
+	}
+
+	var length uint64
+	switch f.width {
+	case 1:
+		length = uint64(header[0])
+	case 2:
+		length = uint64(f.order.Uint16(header[:2]))
+	case 4:
+		length = uint64(f.order.Uint32(header[:4]))
+	case 8:
+		length = f.order.Uint64(header[:8])
+	}
+	if length > uint64(max) {
+		return nil, fmt.Errorf("%w: %d bytes (max %d)", ErrFrameTooLarge, length, max)
+	}
+
+	frame := make([]byte, length)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, noEOF(err)
+	}
+	return frame, nil
+}
+
+func (f lengthPrefixedFramer) WriteFrame(w io.Writer, data []byte) error {
+	length := uint64(len(data))
+	if f.width < 8 && length >= 1<<(8*f.width) {
+		return fmt.Errorf("%w: %d bytes do not fit a %d-byte length prefix", ErrFrameTooLarge, length, f.width)
+	}
+
+	buf := make([]byte, f.width, f.width+len(data))
+	switch f.width {
+	case 1:
+		buf[0] = byte(length)
+	case 2:
+		f.order.PutUint16(buf, uint16(length))
+	case 4:
+		f.order.PutUint32(buf, uint32(length))
+	case 8:
+		f.order.PutUint64(buf, length)
+	}
+	_, err := w.Write(append(buf, data...))
+	return err
+}
+
+// Fixed returns a Framer for frames of exactly size bytes.
+// It panics if size is not positive.
+func Fixed(size int) Framer {
+	if size <= 0 {
+		panic(fmt.Sprintf("tcp: invalid fixed frame size %d", size))
+	}
+	return fixedFramer{size: size}
+}
+
+type fixedFramer struct {
+	size int
+}
+
+func (f fixedFramer) ReadFrame(r *bufio.Reader, max int) ([]byte, error) {
+	if f.size > max {
+		return nil, fmt.Errorf("%w: fixed size %d exceeds max %d", ErrFrameTooLarge, f.size, max)
+	}
+	frame := make([]byte, f.size)
+	if n, err := io.ReadFull(r, frame); err != nil {
+		if n == 0 {
+			return nil, err
+		}
+		return nil, noEOF(err)
+	}
+	return frame, nil
+}
+
+func (f fixedFramer) WriteFrame(w io.Writer, data []byte) error {
+	if len(data) != f.size {
+		return fmt.Errorf("frame is %d bytes, want %d", len(data), f.size)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// noEOF reports a stream that ended inside a frame as io.ErrUnexpectedEOF
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFramers_RoundTrip(t *testing.T) {
+	framers := map[string]Framer{
+		"delimited": Delimited(0),
+		"lines":     Lines(),
+		"u8":        LengthPrefixed(1, binary.BigEndian),
+		"u16 be":    LengthPrefixed(2, binary.BigEndian),
+		"u16 le":    LengthPrefixed(2, binary.LittleEndian),
+		"u32 be":    LengthPrefixed(4, binary.BigEndian),
+		"u64 le":    LengthPrefixed(8, binary.LittleEndian),
+		"fixed":     Fixed(5),
+	}
+	frames := [][]byte{[]byte("alpha"), []byte("bravo"), []byte("charl")}
+
+	for name, f := range framers {
+		t.Run(name, func(t *testing.T) {
+			var stream bytes.Buffer
+			for _, frame := range frames {
+				if err := f.WriteFrame(&stream, frame); err != nil {
+					t.Fatalf("WriteFrame failed: %v", err)
+				}
+			}
+
+			r := bufio.NewReader(&stream)
+			for _, want := range frames {
+				got, err := f.ReadFrame(r, 1024)
+				if err != nil {
+					t.Fatalf("ReadFrame failed: %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("Expected %q, got %q", want, got)
+				}
+			}
+			if _, err := f.ReadFrame(r, 1024); !errors.Is(err, io.EOF) {
+				t.Errorf("Expected io.EOF at end of stream, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFramers_ByteOrder(t *testing.T) {
+	var be, le bytes.Buffer
+	LengthPrefixed(2, binary.BigEndian).WriteFrame(&be, []byte("hi"))
+	LengthPrefixed(2, binary.LittleEndian).WriteFrame(&le, []byte("hi"))
+
+	if !bytes.Equal(be.Bytes(), []byte{0, 2, 'h', 'i'}) {
+		t.Errorf("Unexpected big-endian encoding % x", be.Bytes())
+	}
+	if !bytes.Equal(le.Bytes(), []byte{2, 0, 'h', 'i'}) {
+		t.Errorf("Unexpected little-endian encoding % x", le.Bytes())
+	}
+}
+
+func TestFramers_Limits(t *testing.T) {
+	tests := []struct {
+		name   string
+		framer Framer
+		input  string
+		err    error
+	}{
+		{name: "line too long", framer: Lines(), input: strings.Repeat("x", 100) + "\n", err: ErrFrameTooLarge},
+		{name: "line without terminator", framer: Lines(), input: "partial", err: io.ErrUnexpectedEOF},
+		{name: "length too large", framer: LengthPrefixed(2, binary.BigEndian), input: "\x01\x00", err: ErrFrameTooLarge},
+		{name: "truncated frame", framer: LengthPrefixed(1, binary.BigEndian), input: "\x05ab", err: io.ErrUnexpectedEOF},
+		{name: "fixed too large", framer: Fixed(128), input: "", err: ErrFrameTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.framer.ReadFrame(bufio.NewReader(strings.NewReader(tt.input)), 64)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	if err := LengthPrefixed(1, binary.BigEndian).WriteFrame(io.Discard, make([]byte, 256)); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Expected ErrFrameTooLarge for oversized 1-byte prefix, got %v", err)
+	}
+	if err := Lines().WriteFrame(io.Discard, []byte("a\nb")); err == nil {
+		t.Error("Expected error writing a frame containing the delimiter")
+	}
+}
+
+func TestFramers_InvalidConfig(t *testing.T) {
+	tests := map[string]func(){
+		"prefix width 3": func() { LengthPrefixed(3, binary.BigEndian) },
+		"fixed size 0":   func() { Fixed(0) },
+		"fixed size -1":  func() { Fixed(-1) },
+	}
+
+	for name, build := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected a panic for the invalid framer")
+				}
+			}()
+			build()
+		})
+	}
+}
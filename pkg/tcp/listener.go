@@ -0,0 +1,231 @@
+package tcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/google/uuid"
+)
+
+// ListenerAdapter accepts TCP connections and publishes "net.tcp.open",
+// "net.tcp.data" (one event per frame) and "net.tcp.close" events
+type ListenerAdapter struct {
+	id       string
+	addr     string
+	listener net.Listener
+	bus      event.Bus
+	clk      clock.Clock
+	ctx      context.Context
+	opts     options
+	stats    metrics.Counters
+
+	conns  sync.Map // connID → *Conn, connections accepted by this adapter
+	active atomic.Int64
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewListenerAdapter creates a new TCP listener adapter
+func NewListenerAdapter(addr string, opts ...Option) *ListenerAdapter {
+	return &ListenerAdapter{
+		id:   fmt.Sprintf("tcp-listener-%s", addr),
+		addr: addr,
+		clk:  clock.System(),
+		opts: newOptions(opts),
+	}
+}
+
+// ID returns the adapter's unique identifier
+func (a *ListenerAdapter) ID() string {
+	return a.id
+}
+
+// Type returns the adapter type
+func (a *ListenerAdapter) Type() string {
+	return "tcp-listener"
+}
+
+// Addr returns the address the adapter listens on (useful with port 0),
+// or nil before Start
+func (a *ListenerAdapter) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.listener == nil {
+		return nil
+	}
+	return a.listener.Addr()
+}
+
+// Start begins accepting connections. Event timestamps are taken from clk
+// (see clock.From).
+func (a *ListenerAdapter) Start(ctx context.Context, bus event.Bus, clk pipeclock.Clock) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		return fmt.Errorf("adapter already running")
+	}
+
+	listener, err := net.Listen("tcp", a.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.addr, err)
+	}
+
+	a.listener = listener
+	a.bus = bus
+	a.clk = clock.From(clk)
+	a.ctx = ctx
+
+	a.wg.Add(1)
+	go a.acceptLoop()
+
+	a.running = true
+	return nil
+}
+
+// Stop closes the listener and all open connections
+func (a *ListenerAdapter) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.running {
+		return nil
+	}
+
+	err := a.listener.Close()
+	a.conns.Range(func(_, val any) bool {
+		val.(*Conn).Close()
+		return true
+	})
+	a.wg.Wait()
+
+	a.running = false
+	return err
+}
+
+// Metrics returns the adapter's traffic counters. Bytes are frame payloads;
+// errors are failed writes and connections that ended in an error.
+func (a *ListenerAdapter) Metrics() metrics.NetworkMetrics {
+	return a.stats.Snapshot(a.id, a.Type(), "tcp", a.clk.Wall())
+}
+
+// acceptLoop accepts connections until the listener is closed
+func (a *ListenerAdapter) acceptLoop() {
+	defer a.wg.Done()
+
+	for {
+		nc, err := a.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue // Temporary accept error
+		}
+
+		if a.opts.maxConns > 0 && a.active.Load() >= int64(a.opts.maxConns) {
+			nc.Close()
+			continue
+		}
+
+		a.active.Add(1)
+		a.wg.Add(1)
+		go a.serve(nc)
+	}
+}
+
+// serve registers the connection, publishes its frames and unregisters it when it closes
+func (a *ListenerAdapter) serve(nc net.Conn) {
+	defer a.wg.Done()
+	defer a.active.Add(-1)
+
+	connID := uuid.New().String()
+	conn := newConn(connID, nc, a.opts.framer, a.opts.writeTimeout, &a.stats)
+	remoteAddr, localAddr := conn.RemoteAddr(), conn.LocalAddr()
+
+	globalConnections.Store(connID, conn)
+	a.conns.Store(connID, conn)
+	a.stats.Opened()
+	defer func() {
+		globalConnections.Delete(connID)
+		a.conns.Delete(connID)
+		nc.Close()
+	}()
+
+	a.publish("net.tcp.open", connID, TCPOpenPayload{
+		ConnectionID: connID,
+		RemoteAddr:   remoteAddr,
+		LocalAddr:    localAddr,
+		Timestamp:    a.clk.Wall(),
+	})
+	a.connectionEvent("net.connection.open", "new", conn, 0, 0)
+
+	framesIn, bytesIn, err := readFrames(nc, a.opts, func(frame []byte) {
+		a.stats.Received(len(frame))
+		a.publish("net.tcp.data", connID, TCPDataPayload{
+			ConnectionID: connID,
+			Data:         frame,
+			RemoteAddr:   remoteAddr,
+			LocalAddr:    localAddr,
+			Timestamp:    a.clk.Wall(),
+		})
+	})
+
+	a.stats.Closed()
+	reason, errText := closeReason(err)
+	if failed(reason) {
+		a.stats.Error()
+	}
+	a.publish("net.tcp.close", connID, TCPClosePayload{
+		ConnectionID: connID,
+		Reason:       reason,
+		Error:        errText,
+		FramesIn:     framesIn,
+		BytesIn:      bytesIn,
+		RemoteAddr:   remoteAddr,
+		LocalAddr:    localAddr,
+		Timestamp:    a.clk.Wall(),
+	})
+	a.connectionEvent("net.connection.close", "closed", conn, framesIn, bytesIn)
+}
+
+// connectionEvent publishes a connection lifecycle event when enabled
+func (a *ListenerAdapter) connectionEvent(eventType, state string, conn *Conn, framesIn, bytesIn uint64) {
+	if !a.opts.connectionEvents {
+		return
+	}
+	a.publish(eventType, conn.id, ConnectionPayload{
+		ConnectionID: conn.id,
+		State:        state,
+		Protocol:     "tcp",
+		RemoteAddr:   conn.RemoteAddr(),
+		LocalAddr:    conn.LocalAddr(),
+		BytesIn:      bytesIn,
+		BytesOut:     conn.bytesOut.Load(),
+		Requests:     framesIn,
+		Timestamp:    a.clk.Wall(),
+	})
+}
+
+// publish encodes payload with the adapter codec and publishes it
+func (a *ListenerAdapter) publish(eventType, connID string, payload any) {
+	evt, err := codec.NewEvent(eventType, a.id, payload, a.opts.codec)
+	if err != nil {
+		return
+	}
+	evt.WithMetadata("adapter_id", a.id).
+		WithMetadata("connection_id", connID)
+
+	a.bus.Publish(a.ctx, evt)
+}
@@ -0,0 +1,211 @@
+package tcp
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// startListener runs a ListenerAdapter on a free port and subscribes to its events
+func startListener(t *testing.T, opts ...Option) (*ListenerAdapter, *engine.Engine, event.Subscription) {
+	t.Helper()
+
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+
+	adapter := NewListenerAdapter("127.0.0.1:0", opts...)
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	t.Cleanup(func() { adapterMgr.Stop() })
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.tcp.open", "net.tcp.data", "net.tcp.close", "net.connection.open", "net.connection.close"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+	return adapter, eng, sub
+}
+
+// nextEvent waits for the next event of the given type and decodes its payload
+func nextEvent(t *testing.T, sub event.Subscription, eventType string, payload any) *event.Event {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case evt := <-sub.Events():
+			if evt.Type != eventType {
+				continue
+			}
+			if err := codec.Decode(evt, payload); err != nil {
+				t.Fatalf("Failed to decode %s: %v", eventType, err)
+			}
+			return evt
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %s", eventType)
+			return nil
+		}
+	}
+}
+
+func dial(t *testing.T, adapter *ListenerAdapter) net.Conn {
+	t.Helper()
+
+	nc, err := net.Dial("tcp", adapter.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { nc.Close() })
+	return nc
+}
+
+func TestListenerAdapter_Lifecycle(t *testing.T) {
+	adapter, _, sub := startListener(t, WithFramer(Lines()))
+	nc := dial(t, adapter)
+
+	var open TCPOpenPayload
+	nextEvent(t, sub, "net.tcp.open", &open)
+	if open.ConnectionID == "" || open.RemoteAddr != nc.LocalAddr().String() {
+		t.Errorf("Unexpected open payload: %+v", open)
+	}
+	if _, ok := GetConnection(open.ConnectionID); !ok {
+		t.Error("Expected connection in registry")
+	}
+
+	nc.Write([]byte("first\r\nsec"))
+	nc.Write([]byte("ond\n"))
+
+	for _, want := range []string{"first", "second"} {
+		var data TCPDataPayload
+		evt := nextEvent(t, sub, "net.tcp.data", &data)
+		if string(data.Data) != want {
+			t.Errorf("Expected frame %q, got %q", want, data.Data)
+		}
+		if evt.Metadata["connection_id"] != open.ConnectionID {
+			t.Errorf("Expected connection_id metadata, got %q", evt.Metadata["connection_id"])
+		}
+	}
+
+	nc.Close()
+	var closed TCPClosePayload
+	nextEvent(t, sub, "net.tcp.close", &closed)
+	if closed.Reason != "eof" || closed.FramesIn != 2 || closed.BytesIn != 11 {
+		t.Errorf("Unexpected close payload: %+v", closed)
+	}
+}
+
+func TestListenerAdapter_ConnectionEvents(t *testing.T) {
+	adapter, _, sub := startListener(t, WithFramer(Lines()), WithConnectionEvents())
+	nc := dial(t, adapter)
+
+	var open ConnectionPayload
+	nextEvent(t, sub, "net.connection.open", &open)
+	if open.ConnectionID == "" || open.State != "new" || open.Protocol != "tcp" || open.RemoteAddr != nc.LocalAddr().String() {
+		t.Errorf("Unexpected open payload: %+v", open)
+	}
+
+	nc.Write([]byte("ping\n"))
+	nextEvent(t, sub, "net.tcp.data", &TCPDataPayload{})
+	conn, _ := GetConnection(open.ConnectionID)
+	if err := conn.WriteFrame([]byte("pong")); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+
+	nc.Close()
+	var closed ConnectionPayload
+	evt := nextEvent(t, sub, "net.connection.close", &closed)
+	if closed.ConnectionID != open.ConnectionID || closed.State != "closed" || closed.TLS {
+		t.Errorf("Unexpected close payload: %+v", closed)
+	}
+	if closed.BytesIn != 4 || closed.BytesOut != 4 || closed.Requests != 1 {
+		t.Errorf("Expected 4 bytes each way and 1 frame, got %+v", closed)
+	}
+	if evt.Metadata["connection_id"] != open.ConnectionID {
+		t.Errorf("Expected connection_id metadata, got %q", evt.Metadata["connection_id"])
+	}
+}
+
+func TestListenerAdapter_MaxFrameSize(t *testing.T) {
+	adapter, _, sub := startListener(t,
+		WithFramer(LengthPrefixed(4, binary.BigEndian)),
+		WithMaxFrameSize(16),
+	)
+	nc := dial(t, adapter)
+
+	nc.Write([]byte{0, 0, 1, 0}) // 256-byte frame
+
+	var closed TCPClosePayload
+	nextEvent(t, sub, "net.tcp.close", &closed)
+	if closed.Reason != "frame_too_large" {
+		t.Errorf("Expected frame_too_large, got %q", closed.Reason)
+	}
+}
+
+func TestListenerAdapter_ReadTimeout(t *testing.T) {
+	adapter, _, sub := startListener(t, WithFramer(Lines()), WithReadTimeout(100*time.Millisecond))
+	nc := dial(t, adapter)
+
+	nc.Write([]byte("no newline yet"))
+
+	var closed TCPClosePayload
+	nextEvent(t, sub, "net.tcp.close", &closed)
+	if closed.Reason != "timeout" {
+		t.Errorf("Expected timeout, got %q", closed.Reason)
+	}
+}
+
+func TestListenerAdapter_MaxConnections(t *testing.T) {
+	adapter, _, sub := startListener(t, WithMaxConnections(1))
+
+	dial(t, adapter)
+	nextEvent(t, sub, "net.tcp.open", &TCPOpenPayload{})
+
+	rejected := dial(t, adapter)
+	rejected.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := rejected.Read(make([]byte, 1)); err == nil || strings.Contains(err.Error(), "timeout") {
+		t.Errorf("Expected connection over the limit to be closed, got %v", err)
+	}
+}
+
+func TestListenerAdapter_Metrics(t *testing.T) {
+	adapter, _, sub := startListener(t, WithFramer(Lines()), WithReadTimeout(200*time.Millisecond))
+	nc := dial(t, adapter)
+
+	var open TCPOpenPayload
+	nextEvent(t, sub, "net.tcp.open", &open)
+	nc.Write([]byte("ping\n"))
+	nextEvent(t, sub, "net.tcp.data", &TCPDataPayload{})
+
+	conn, _ := GetConnection(open.ConnectionID)
+	if err := conn.WriteFrame([]byte("pong")); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+
+	m := adapter.Metrics()
+	if m.AdapterID != adapter.ID() || m.Protocol != "tcp" {
+		t.Errorf("Unexpected identity: %+v", m)
+	}
+	if m.ConnectionCount != 1 || m.BytesReceived != 4 || m.BytesSent != 4 || m.ErrorCount != 0 {
+		t.Errorf("Unexpected counters while open: %+v", m)
+	}
+
+	// The idle connection times out, which counts as an error
+	nextEvent(t, sub, "net.tcp.close", &TCPClosePayload{})
+	if m := adapter.Metrics(); m.ConnectionCount != 0 || m.ErrorCount != 1 {
+		t.Errorf("Unexpected counters after timeout: %+v", m)
+	}
+}
@@ -0,0 +1,164 @@
+package tcp
+
+import (
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/internal/backoff"
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+)
+
+// Option configures a ListenerAdapter or an emitter in this package
+type Option func(*options)
+
+// options holds the settings shared by adapters and emitters
+type options struct {
+	codec  codec.Codec
+	framer Framer
+
+	// Limits and deadlines
+	maxFrameSize int
+	maxConns     int
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+
+	// Outbound connections
+	dialTimeout time.Duration
+	backoff     backoff.Policy
+	idleTimeout time.Duration
+	maxTargets  int
+
+	connectionEvents bool
+	clk              clock.Clock
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		codec:        codec.JSON{},
+		framer:       Raw(),
+		maxFrameSize: 64 * 1024,
+		writeTimeout: 10 * time.Second,
+		dialTimeout:  5 * time.Second,
+		backoff:      backoff.Policy{Min: 500 * time.Millisecond, Max: 30 * time.Second},
+		idleTimeout:  5 * time.Minute,
+		maxTargets:   1024,
+		clk:          clock.System(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCodec sets the payload codec.
+// Adapters encode events with it; emitters use it to decode events that
+// carry no "codec" metadata.
+func WithCodec(c codec.Codec) Option {
+	return func(o *options) {
+		if c != nil {
+			o.codec = c
+		}
+	}
+}
+
+// WithFramer sets how the byte stream is split into frames (default Raw)
+func WithFramer(f Framer) Option {
+	return func(o *options) {
+		if f != nil {
+			o.framer = f
+		}
+	}
+}
+
+// WithMaxFrameSize sets the largest frame accepted, in bytes (default 64KB).
+// A connection sending a larger frame is closed.
+func WithMaxFrameSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxFrameSize = n
+		}
+	}
+}
+
+// WithMaxConnections limits concurrent connections; connections over the
+// limit are closed as soon as they are accepted. Zero means no limit.
+func WithMaxConnections(n int) Option {
+	return func(o *options) {
+		o.maxConns = n
+	}
+}
+
+// WithConnectionEvents makes the ListenerAdapter also publish
+// "net.connection.open" and "net.connection.close" events with a
+// ConnectionPayload, alongside its "net.tcp.open" and "net.tcp.close" events
+func WithConnectionEvents() Option {
+	return func(o *options) {
+		o.connectionEvents = true
+	}
+}
+
+// WithReadTimeout closes connections that send no complete frame within d.
+// Zero (the default) disables the deadline.
+func WithReadTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.readTimeout = d
+	}
+}
+
+// WithWriteTimeout sets the deadline for writing a single frame
+func WithWriteTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.writeTimeout = d
+	}
+}
+
+// WithDialTimeout sets how long the emitter waits for an outbound connection
+// when sending (default 5s)
+func WithDialTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.dialTimeout = d
+	}
+}
+
+// WithBackoff sets the emitter's reconnect delay for outbound targets.
+// The delay starts at min, doubles after each failed dial (or connection
+// that drops within 10 seconds) up to max, and has ±20% jitter. A min of
+// zero or less, or a max below min, is ignored.
+func WithBackoff(min, max time.Duration) Option {
+	return func(o *options) {
+		if p := (backoff.Policy{Min: min, Max: max}); p.Valid() {
+			o.backoff = p
+		}
+	}
+}
+
+// WithIdleTimeout sets how long the emitter keeps an outbound target that
+// nothing is sent to (default 5 minutes). The target's connection is closed
+// and its redialing stops; a later send dials it afresh. Zero keeps targets
+// until the emitter is closed.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.idleTimeout = max(d, 0)
+	}
+}
+
+// WithClock sets the clock the ClientEmitter takes timestamps, redial delays
+// and idle eviction from (defaults to the system clock). The ListenerAdapter
+// uses the clock passed to Start; socket deadlines always use real time.
+func WithClock(clk clock.Clock) Option {
+	return func(o *options) {
+		if clk != nil {
+			o.clk = clk
+		}
+	}
+}
+
+// WithMaxTargets limits how many outbound targets the emitter pools (default
+// 1024). Sends to a new address beyond the limit fail with
+// ErrTooManyTargets until idle targets are evicted. Zero means no limit.
+func WithMaxTargets(n int) Option {
+	return func(o *options) {
+		o.maxTargets = max(n, 0)
+	}
+}
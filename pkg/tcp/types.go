@@ -0,0 +1,104 @@
+package tcp
+
+import "time"
+
+// TCPOpenPayload represents a "net.tcp.open" event
+type TCPOpenPayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for connection
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Peer IP:port
+	LocalAddr  string `json:"local_addr"`  // Local IP:port
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When accepted
+}
+
+// TCPDataPayload represents a "net.tcp.data" event (one frame)
+type TCPDataPayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for connection
+
+	// Data
+	Data []byte `json:"data"` // Frame contents, without framing
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Peer IP:port
+	LocalAddr  string `json:"local_addr"`  // Local IP:port
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When received
+}
+
+// TCPClosePayload represents a "net.tcp.close" event
+type TCPClosePayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for connection
+
+	// Close data
+	Reason string `json:"reason"`          // eof, timeout, frame_too_large, shutdown or error
+	Error  string `json:"error,omitempty"` // Underlying error, if any
+
+	// Traffic
+	FramesIn uint64 `json:"frames_in"` // Frames received
+	BytesIn  uint64 `json:"bytes_in"`  // Payload bytes received
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Peer IP:port
+	LocalAddr  string `json:"local_addr"`  // Local IP:port
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When closed
+}
+
+// TCPSendPayload represents a "net.tcp.send" event.
+// ConnectionID writes to an open connection (inbound or pooled); otherwise
+// Address selects a pooled outbound connection, dialed on first use.
+type TCPSendPayload struct {
+	// Target
+	ConnectionID string `json:"connection_id,omitempty"` // Reply on an open connection
+	Address      string `json:"address,omitempty"`       // host:port of an outbound target
+
+	// Data
+	Data []byte `json:"data"` // Frame contents, framed by the emitter's Framer
+}
+
+// TCPErrorPayload represents a "net.tcp.error" event published by the emitter
+type TCPErrorPayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id,omitempty"` // Connection involved, if any
+	Address      string `json:"address,omitempty"`       // Outbound target, if any
+
+	// Error data
+	Op    string `json:"op"`    // dial or write
+	Error string `json:"error"` // Error message
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When the error occurred
+}
+
+// ConnectionPayload represents a "net.connection.open" or
+// "net.connection.close" event, in the shape the HTTP ServerAdapter uses.
+// Counters are totals for the connection so far.
+type ConnectionPayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for connection
+
+	// State
+	State    string `json:"state"`    // new or closed
+	Protocol string `json:"protocol"` // tcp
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Peer IP:port
+	LocalAddr  string `json:"local_addr"`  // Local IP:port
+	TLS        bool   `json:"tls"`         // Always false: the listener does not terminate TLS
+
+	// Traffic
+	BytesIn  uint64 `json:"bytes_in"`  // Payload bytes received
+	BytesOut uint64 `json:"bytes_out"` // Payload bytes written
+	Requests uint64 `json:"requests"`  // Frames received
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When the state changed
+}
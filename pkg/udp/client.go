@@ -0,0 +1,133 @@
+package udp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// ClientEmitter sends datagrams described by "net.udp.send" events.
+// Datagrams go out through the emitter's own socket, or through a listener's
+// socket when Via names it (see UDPPacketPayload.Reply).
+type ClientEmitter struct {
+	id    string
+	opts  options
+	stats metrics.Counters
+
+	mu   sync.Mutex
+	conn *packetConn // Opened on first send
+}
+
+// NewClientEmitter creates a new UDP client emitter
+func NewClientEmitter(opts ...Option) *ClientEmitter {
+	return &ClientEmitter{
+		id:   "udp-client-emitter",
+		opts: newOptions(opts),
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *ClientEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *ClientEmitter) Type() string {
+	return "udp-client"
+}
+
+// Emit sends the event's data as one datagram
+func (e *ClientEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var payload UDPSendPayload
+	if err := evt.DecodePayload(&payload, c); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", payload.Address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", payload.Address, err)
+	}
+
+	var conn *packetConn
+	if payload.Via != "" {
+		listener, ok := getListener(payload.Via)
+		if !ok {
+			return fmt.Errorf("no running listener found for ID %s", payload.Via)
+		}
+		conn = listener.conn
+	} else if conn, err = e.socket(); err != nil {
+		return err
+	}
+
+	n, err := conn.WriteToUDP(payload.Data, addr)
+	if err != nil {
+		return fmt.Errorf("failed to send datagram to %s: %w", payload.Address, err)
+	}
+	e.stats.Sent(n)
+	return nil
+}
+
+// Metrics returns the emitter's traffic counters. Its own socket counts as
+// one connection once opened; datagrams sent through a listener's socket are
+// counted here too.
+func (e *ClientEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "udp", time.Now())
+}
+
+// socket returns the emitter's own socket, opening it on first use
+func (e *ClientEmitter) socket() (*packetConn, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn != nil {
+		return e.conn, nil
+	}
+
+	udpConn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open socket: %w", err)
+	}
+	if e.opts.writeBuffer > 0 {
+		udpConn.SetWriteBuffer(e.opts.writeBuffer)
+	}
+
+	conn := newPacketConn(udpConn)
+	if err := conn.setMulticast(e.opts); err != nil {
+		udpConn.Close()
+		return nil, err
+	}
+
+	e.conn = conn
+	e.stats.Opened()
+	return conn, nil
+}
+
+// Close closes the emitter's socket
+func (e *ClientEmitter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	e.stats.Closed()
+	return err
+}
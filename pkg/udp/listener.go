@@ -0,0 +1,342 @@
+package udp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/internal/backoff"
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"golang.org/x/net/ipv4"
+)
+
+// ListenerAdapter receives UDP datagrams and publishes them as
+// "net.udp.packet" events. Reads are batched and decoupled from the bus by
+// a bounded queue; datagrams that arrive while the queue is full are dropped,
+// counted and reported in "net.udp.drops" events.
+type ListenerAdapter struct {
+	id   string
+	addr string
+	conn *packetConn
+	bus  event.Bus
+	clk  clock.Clock
+	ctx  context.Context
+	opts options
+
+	queue   chan UDPPacketPayload
+	dropped atomic.Uint64
+	stats   metrics.Counters
+	stop    chan struct{}
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewListenerAdapter creates a new UDP listener adapter
+func NewListenerAdapter(addr string, opts ...Option) *ListenerAdapter {
+	return &ListenerAdapter{
+		id:   fmt.Sprintf("udp-listener-%s", addr),
+		addr: addr,
+		clk:  clock.System(),
+		opts: newOptions(opts),
+	}
+}
+
+// ID returns the adapter's unique identifier
+func (a *ListenerAdapter) ID() string {
+	return a.id
+}
+
+// Type returns the adapter type
+func (a *ListenerAdapter) Type() string {
+	return "udp-listener"
+}
+
+// Addr returns the address the adapter listens on (useful with port 0),
+// or nil before Start
+func (a *ListenerAdapter) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.conn == nil {
+		return nil
+	}
+	return a.conn.LocalAddr()
+}
+
+// Dropped returns how many datagrams were dropped because the bus fell behind
+func (a *ListenerAdapter) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Metrics returns the adapter's traffic counters. The socket counts as one
+// connection while the adapter runs; errors are failed reads and dropped
+// datagrams.
+func (a *ListenerAdapter) Metrics() metrics.NetworkMetrics {
+	return a.stats.Snapshot(a.id, a.Type(), "udp", a.clk.Wall())
+}
+
+// Start opens the socket, joins multicast groups and begins reading.
+// Timestamps, the drop report interval and read error backoff are taken
+// from clk (see clock.From).
+func (a *ListenerAdapter) Start(ctx context.Context, bus event.Bus, clk pipeclock.Clock) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		return fmt.Errorf("adapter already running")
+	}
+
+	conn, err := a.listen()
+	if err != nil {
+		return err
+	}
+
+	a.conn = conn
+	a.bus = bus
+	a.clk = clock.From(clk)
+	a.ctx = ctx
+	a.queue = make(chan UDPPacketPayload, a.opts.queueSize)
+	a.stop = make(chan struct{})
+	globalListeners.Store(a.id, a)
+	a.stats.Opened()
+
+	a.wg.Add(3)
+	go a.readLoop()
+	go a.publishLoop()
+	go a.reportLoop()
+
+	a.running = true
+	return nil
+}
+
+// listen opens and configures the socket
+func (a *ListenerAdapter) listen() (*packetConn, error) {
+	// Multicast groups decide the address family; otherwise "udp" binds dual-stack
+	network := "udp"
+	for _, g := range a.opts.groups {
+		if g.ip.To4() != nil && network != "udp6" {
+			network = "udp4"
+		} else if g.ip.To4() == nil {
+			network = "udp6"
+		}
+	}
+
+	laddr, err := net.ResolveUDPAddr(network, a.addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", a.addr, err)
+	}
+	udpConn, err := net.ListenUDP(network, laddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", a.addr, err)
+	}
+
+	if a.opts.readBuffer > 0 {
+		udpConn.SetReadBuffer(a.opts.readBuffer)
+	}
+	if a.opts.writeBuffer > 0 {
+		udpConn.SetWriteBuffer(a.opts.writeBuffer)
+	}
+
+	conn := newPacketConn(udpConn)
+	for _, g := range a.opts.groups {
+		if err := conn.joinGroup(g); err != nil {
+			udpConn.Close()
+			return nil, fmt.Errorf("failed to join multicast group %s: %w", g.ip, err)
+		}
+	}
+	return conn, nil
+}
+
+// Stop closes the socket and waits for queued datagrams to be published
+func (a *ListenerAdapter) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.running {
+		return nil
+	}
+
+	globalListeners.Delete(a.id)
+	err := a.conn.Close()
+	close(a.stop)
+	a.wg.Wait()
+	a.stats.Closed()
+
+	a.running = false
+	return err
+}
+
+// readErrorBackoff spaces out reads after consecutive read errors
+var readErrorBackoff = backoff.Policy{Min: 5 * time.Millisecond, Max: time.Second}
+
+// readLoop reads datagrams in batches and queues them for publishing
+func (a *ListenerAdapter) readLoop() {
+	defer a.wg.Done()
+	defer close(a.queue)
+
+	oobSize := a.conn.enableDst()
+	// Buffers have a spare byte: a datagram that fills it was longer than
+	// maxDatagram and cut short by the kernel, which not every platform
+	// reports through MSG_TRUNC
+	limit := a.opts.maxDatagram
+	ms := make([]ipv4.Message, a.opts.batchSize)
+	for i := range ms {
+		ms[i].Buffers = [][]byte{make([]byte, limit+1)}
+		if oobSize > 0 {
+			ms[i].OOB = make([]byte, oobSize)
+		}
+	}
+
+	_, localPort, _ := net.SplitHostPort(a.conn.LocalAddr().String())
+	ifaceNames := make(map[int]string) // Cache of interface index → name
+
+	failures := 0
+	for {
+		n, err := a.conn.readBatch(ms)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			a.stats.Error()
+
+			// Back off while reads keep failing (e.g. ENOBUFS, or the
+			// interface went down) instead of spinning on the socket
+			failures++
+			wait := a.clk.NewTimer(readErrorBackoff.Delay(failures))
+			select {
+			case <-a.stop:
+				wait.Stop()
+				return
+			case <-wait.C():
+			}
+			continue
+		}
+		failures = 0
+
+		now := a.clk.Wall()
+		for _, m := range ms[:n] {
+			size := min(m.N, limit)
+			a.stats.Received(size)
+			packet := UDPPacketPayload{
+				ListenerID: a.id,
+				Data:       append([]byte(nil), m.Buffers[0][:size]...),
+				LocalAddr:  a.conn.LocalAddr().String(),
+				Truncated:  m.N > limit,
+				Timestamp:  now,
+			}
+			if packet.Truncated {
+				a.stats.Error()
+			}
+			if m.Addr != nil {
+				packet.RemoteAddr = m.Addr.String()
+			}
+			if dst, ifIndex := a.conn.parseDst(m.OOB[:m.NN]); dst != nil {
+				packet.LocalAddr = net.JoinHostPort(dst.String(), localPort)
+				packet.Interface = ifaceName(ifaceNames, ifIndex)
+			}
+
+			select {
+			case a.queue <- packet:
+			default:
+				a.dropped.Add(1)
+				a.stats.Error()
+			}
+		}
+	}
+}
+
+// publishLoop publishes queued datagrams until the queue is closed
+func (a *ListenerAdapter) publishLoop() {
+	defer a.wg.Done()
+
+	for packet := range a.queue {
+		evt, err := codec.NewEvent("net.udp.packet", a.id, packet, a.opts.codec)
+		if err != nil {
+			continue
+		}
+		evt.WithMetadata("adapter_id", a.id).
+			WithMetadata("remote_addr", packet.RemoteAddr)
+
+		a.bus.Publish(a.ctx, evt)
+	}
+}
+
+// reportLoop publishes "net.udp.drops" events while datagrams are being dropped
+func (a *ListenerAdapter) reportLoop() {
+	defer a.wg.Done()
+
+	ticker := a.clk.NewTicker(a.opts.dropReportInterval)
+	defer ticker.Stop()
+
+	var reported uint64
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C():
+		}
+
+		total := a.dropped.Load()
+		if total == reported {
+			continue
+		}
+
+		evt, err := codec.NewEvent("net.udp.drops", a.id, UDPDropsPayload{
+			ListenerID: a.id,
+			Dropped:    total - reported,
+			Total:      total,
+			Timestamp:  a.clk.Wall(),
+		}, a.opts.codec)
+		if err != nil {
+			continue
+		}
+		evt.WithMetadata("adapter_id", a.id)
+
+		// Never block on the bus that is already behind
+		ctx, cancel := context.WithTimeout(a.ctx, a.opts.dropReportInterval)
+		if a.bus.Publish(ctx, evt) == nil {
+			reported = total
+		}
+		cancel()
+	}
+}
+
+// ifaceName resolves an interface index through cache
+func ifaceName(cache map[int]string, index int) string {
+	if index == 0 {
+		return ""
+	}
+	name, ok := cache[index]
+	if !ok {
+		if ifi, err := net.InterfaceByIndex(index); err == nil {
+			name = ifi.Name
+		} else {
+			name = strconv.Itoa(index)
+		}
+		cache[index] = name
+	}
+	return name
+}
+
+// Global listener registry, used by emitters to reply from a listener's socket
+var globalListeners sync.Map
+
+// getListener retrieves a running listener by ID
+func getListener(id string) (*ListenerAdapter, bool) {
+	val, ok := globalListeners.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return val.(*ListenerAdapter), true
+}
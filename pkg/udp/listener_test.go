@@ -0,0 +1,281 @@
+package udp
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+)
+
+// startListener runs a ListenerAdapter and subscribes to eventTypes
+func startListener(t *testing.T, addr string, eventTypes []string, opts ...Option) (*ListenerAdapter, *engine.Engine, event.Subscription) {
+	t.Helper()
+
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: eventTypes})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+
+	adapter := NewListenerAdapter(addr, opts...)
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		if strings.Contains(err.Error(), "multicast") {
+			t.Skipf("Multicast not available: %v", err)
+		}
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	t.Cleanup(func() { adapterMgr.Stop() })
+	return adapter, eng, sub
+}
+
+// nextPacket waits for the next datagram event
+func nextPacket(t *testing.T, sub event.Subscription) UDPPacketPayload {
+	t.Helper()
+
+	select {
+	case evt := <-sub.Events():
+		var packet UDPPacketPayload
+		if err := codec.Decode(evt, &packet); err != nil {
+			t.Fatalf("Failed to decode packet: %v", err)
+		}
+		return packet
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for datagram")
+		return UDPPacketPayload{}
+	}
+}
+
+func sendEvent(t *testing.T, payload UDPSendPayload) *event.Event {
+	t.Helper()
+
+	evt, err := codec.NewEvent("net.udp.send", "test", payload, codec.JSON{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	return evt
+}
+
+func TestListenerAdapter_PacketAndReply(t *testing.T) {
+	adapter, _, sub := startListener(t, "127.0.0.1:0", []string{"net.udp.packet"}, WithBatchSize(4))
+
+	client, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to open client socket: %v", err)
+	}
+	defer client.Close()
+
+	server := adapter.Addr().(*net.UDPAddr)
+	for _, msg := range []string{"one", "two", "three"} {
+		client.WriteToUDP([]byte(msg), server)
+	}
+
+	var packet UDPPacketPayload
+	for _, want := range []string{"one", "two", "three"} {
+		packet = nextPacket(t, sub)
+		if string(packet.Data) != want {
+			t.Errorf("Expected %q, got %q", want, packet.Data)
+		}
+	}
+	if packet.RemoteAddr != client.LocalAddr().String() || packet.LocalAddr != server.String() {
+		t.Errorf("Unexpected addresses: remote %s local %s", packet.RemoteAddr, packet.LocalAddr)
+	}
+
+	// The reply leaves from the listener's port
+	emitter := NewClientEmitter()
+	defer emitter.Close()
+	if err := emitter.Emit(context.Background(), sendEvent(t, packet.Reply([]byte("pong")))); err != nil {
+		t.Fatalf("Emit failed: %v", err)
+	}
+
+	buf := make([]byte, 64)
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	n, from, err := client.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("Failed to read reply: %v", err)
+	}
+	if string(buf[:n]) != "pong" || from.Port != server.Port {
+		t.Errorf("Expected pong from port %d, got %q from %v", server.Port, buf[:n], from)
+	}
+}
+
+func TestListenerAdapter_MarksTruncatedDatagrams(t *testing.T) {
+	adapter, _, sub := startListener(t, "127.0.0.1:0", []string{"net.udp.packet"}, WithMaxDatagramSize(8))
+
+	client, err := net.DialUDP("udp4", nil, adapter.Addr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	client.Write([]byte("exactly8"))
+	client.Write([]byte("longer than eight bytes"))
+
+	if packet := nextPacket(t, sub); string(packet.Data) != "exactly8" || packet.Truncated {
+		t.Errorf("Expected a full 8-byte datagram, got %q (truncated %v)", packet.Data, packet.Truncated)
+	}
+	if packet := nextPacket(t, sub); string(packet.Data) != "longer t" || !packet.Truncated {
+		t.Errorf("Expected a truncated datagram, got %q (truncated %v)", packet.Data, packet.Truncated)
+	}
+	if m := adapter.Metrics(); m.ErrorCount != 1 || m.BytesReceived != 16 {
+		t.Errorf("Expected 1 error and 16 bytes received, got %d and %d", m.ErrorCount, m.BytesReceived)
+	}
+}
+
+func TestListenerAdapter_ReportsDrops(t *testing.T) {
+	// The packet subscriber never reads, so the bus backs up once its buffer fills
+	adapter, eng, _ := startListener(t, "127.0.0.1:0", []string{"net.udp.packet"},
+		WithQueueSize(1),
+		WithDropReportInterval(50*time.Millisecond),
+	)
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.udp.drops"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	client, err := net.DialUDP("udp4", nil, adapter.Addr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 500; i++ {
+		client.Write([]byte("flood"))
+	}
+
+	select {
+	case evt := <-sub.Events():
+		var drops UDPDropsPayload
+		if err := codec.Decode(evt, &drops); err != nil {
+			t.Fatalf("Failed to decode drops: %v", err)
+		}
+		if drops.Dropped == 0 || drops.Total < drops.Dropped || drops.ListenerID != adapter.ID() {
+			t.Errorf("Unexpected drops payload: %+v", drops)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for drops event")
+	}
+}
+
+func TestListenerAdapter_Multicast(t *testing.T) {
+	tests := []struct {
+		name  string
+		group string
+		addr  string
+	}{
+		{name: "ipv4", group: "239.1.2.3", addr: "0.0.0.0:0"},
+		{name: "ipv6", group: "ff12::1234", addr: "[::]:0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, _, sub := startListener(t, tt.addr, []string{"net.udp.packet"}, WithMulticastGroup(tt.group, "lo"))
+
+			emitter := NewClientEmitter(WithMulticastInterface("lo"), WithMulticastLoopback(true))
+			defer emitter.Close()
+
+			port := adapter.Addr().(*net.UDPAddr).Port
+			target := net.JoinHostPort(tt.group, strconv.Itoa(port))
+			if err := emitter.Emit(context.Background(), sendEvent(t, UDPSendPayload{Address: target, Data: []byte("hello group")})); err != nil {
+				t.Skipf("Multicast send not available: %v", err)
+			}
+
+			select {
+			case evt := <-sub.Events():
+				var packet UDPPacketPayload
+				codec.Decode(evt, &packet)
+				if string(packet.Data) != "hello group" {
+					t.Errorf("Expected group datagram, got %q", packet.Data)
+				}
+				if host, _, _ := net.SplitHostPort(packet.LocalAddr); !net.ParseIP(host).Equal(net.ParseIP(tt.group)) {
+					t.Errorf("Expected destination %s, got %s", tt.group, packet.LocalAddr)
+				}
+			case <-time.After(time.Second):
+				t.Skip("Multicast datagram not delivered on loopback in this environment")
+			}
+		})
+	}
+}
+
+func TestListenerAdapter_BacksOffOnReadErrors(t *testing.T) {
+	adapter, _, sub := startListener(t, "127.0.0.1:0", []string{"net.udp.packet"})
+
+	// A deadline in the past makes every read fail at once
+	adapter.conn.SetReadDeadline(time.Unix(1, 0))
+	time.Sleep(300 * time.Millisecond)
+
+	// Delays of 5, 10, 20, 40ms... allow a handful of failed reads
+	if errs := adapter.Metrics().ErrorCount; errs == 0 || errs > 10 {
+		t.Errorf("Expected a few backed-off read errors, got %d", errs)
+	}
+
+	// Reads resume once the error clears
+	adapter.conn.SetReadDeadline(time.Time{})
+	client, err := net.DialUDP("udp4", nil, adapter.Addr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+	client.Write([]byte("back"))
+
+	if packet := nextPacket(t, sub); string(packet.Data) != "back" {
+		t.Errorf("Expected the datagram after recovery, got %q", packet.Data)
+	}
+}
+
+func TestListenerAdapter_ReadBackoffOnClock(t *testing.T) {
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.udp.packet"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	adapter := NewListenerAdapter("127.0.0.1:0")
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), clk); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	// The first failed read waits on the fake clock, so no more are attempted
+	adapter.conn.SetReadDeadline(time.Unix(1, 0))
+	clk.BlockUntil(2) // The drop report ticker and the backoff timer
+	time.Sleep(50 * time.Millisecond)
+	if errs := adapter.Metrics().ErrorCount; errs != 1 {
+		t.Errorf("Expected one read error while the clock stands still, got %d", errs)
+	}
+
+	adapter.conn.SetReadDeadline(time.Time{})
+	clk.Advance(time.Second)
+
+	client, err := net.DialUDP("udp4", nil, adapter.Addr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+	client.Write([]byte("back"))
+
+	packet := nextPacket(t, sub)
+	if want := start.Add(time.Second); !packet.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v from the clock, got %v", want, packet.Timestamp)
+	}
+}
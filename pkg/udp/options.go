@@ -0,0 +1,149 @@
+package udp
+
+import (
+	"net"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+)
+
+// Option configures a ListenerAdapter or an emitter in this package
+type Option func(*options)
+
+// group is a multicast group to join
+type group struct {
+	ip    net.IP
+	iface string
+}
+
+// options holds the settings shared by adapters and emitters
+type options struct {
+	codec codec.Codec
+
+	// Socket and read loop
+	readBuffer  int
+	writeBuffer int
+	batchSize   int
+	maxDatagram int
+
+	// Backpressure
+	queueSize          int
+	dropReportInterval time.Duration
+
+	// Multicast
+	groups            []group
+	multicastIface    string
+	multicastTTL      int
+	multicastLoopback bool
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		codec:              codec.JSON{},
+		batchSize:          16,
+		maxDatagram:        64 * 1024,
+		queueSize:          1024,
+		dropReportInterval: time.Second,
+		multicastTTL:       1,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCodec sets the payload codec.
+// Adapters encode events with it; emitters use it to decode events that
+// carry no "codec" metadata.
+func WithCodec(c codec.Codec) Option {
+	return func(o *options) {
+		if c != nil {
+			o.codec = c
+		}
+	}
+}
+
+// WithReadBuffer sets the socket receive buffer size (SO_RCVBUF) in bytes
+func WithReadBuffer(bytes int) Option {
+	return func(o *options) {
+		o.readBuffer = bytes
+	}
+}
+
+// WithWriteBuffer sets the socket send buffer size (SO_SNDBUF) in bytes
+func WithWriteBuffer(bytes int) Option {
+	return func(o *options) {
+		o.writeBuffer = bytes
+	}
+}
+
+// WithBatchSize sets how many datagrams one read may return (default 16).
+// On Linux a batch is read with a single recvmmsg call.
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	}
+}
+
+// WithMaxDatagramSize sets the largest datagram read in full (default 64KB);
+// longer datagrams are truncated, marked Truncated and counted as errors
+func WithMaxDatagramSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxDatagram = n
+		}
+	}
+}
+
+// WithQueueSize sets how many datagrams may wait for the bus (default 1024).
+// Datagrams arriving while the queue is full are dropped and counted.
+func WithQueueSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.queueSize = n
+		}
+	}
+}
+
+// WithDropReportInterval sets how often a "net.udp.drops" event is published
+// while datagrams are being dropped (default 1s)
+func WithDropReportInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.dropReportInterval = d
+		}
+	}
+}
+
+// WithMulticastGroup makes the listener join an IPv4 or IPv6 multicast group
+// on the named interface ("" lets the system choose). It can be repeated.
+func WithMulticastGroup(groupIP, iface string) Option {
+	return func(o *options) {
+		o.groups = append(o.groups, group{ip: net.ParseIP(groupIP), iface: iface})
+	}
+}
+
+// WithMulticastInterface sets the interface the emitter sends multicast datagrams on
+func WithMulticastInterface(iface string) Option {
+	return func(o *options) {
+		o.multicastIface = iface
+	}
+}
+
+// WithMulticastTTL sets the hop limit of multicast datagrams sent by the emitter (default 1)
+func WithMulticastTTL(ttl int) Option {
+	return func(o *options) {
+		o.multicastTTL = ttl
+	}
+}
+
+// WithMulticastLoopback makes multicast datagrams sent by the emitter
+// visible to listeners on the same host
+func WithMulticastLoopback(enabled bool) Option {
+	return func(o *options) {
+		o.multicastLoopback = enabled
+	}
+}
@@ -0,0 +1,125 @@
+package udp
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// packetConn wraps a UDP socket with the x/net helpers for its address family
+type packetConn struct {
+	*net.UDPConn
+	v4 *ipv4.PacketConn // Set for IPv4 sockets
+	v6 *ipv6.PacketConn // Set for IPv6 (including dual-stack) sockets
+}
+
+// newPacketConn picks the IPv4 or IPv6 helpers by the socket's local address
+func newPacketConn(c *net.UDPConn) *packetConn {
+	pc := &packetConn{UDPConn: c}
+	if addr, ok := c.LocalAddr().(*net.UDPAddr); ok && addr.IP.To4() != nil {
+		pc.v4 = ipv4.NewPacketConn(c)
+	} else {
+		pc.v6 = ipv6.NewPacketConn(c)
+	}
+	return pc
+}
+
+// enableDst asks the kernel for each datagram's destination address and
+// interface. It returns the control message buffer size to use (0 if unsupported).
+func (pc *packetConn) enableDst() int {
+	if pc.v4 != nil {
+		flags := ipv4.FlagDst | ipv4.FlagInterface
+		if pc.v4.SetControlMessage(flags, true) != nil {
+			return 0
+		}
+		return len(ipv4.NewControlMessage(flags))
+	}
+	flags := ipv6.FlagDst | ipv6.FlagInterface
+	if pc.v6.SetControlMessage(flags, true) != nil {
+		return 0
+	}
+	return len(ipv6.NewControlMessage(flags))
+}
+
+// readBatch reads up to len(ms) datagrams
+func (pc *packetConn) readBatch(ms []ipv4.Message) (int, error) {
+	if pc.v4 != nil {
+		return pc.v4.ReadBatch(ms, 0)
+	}
+	return pc.v6.ReadBatch(ms, 0)
+}
+
+// parseDst extracts the destination IP and interface index from control data
+func (pc *packetConn) parseDst(oob []byte) (net.IP, int) {
+	if len(oob) == 0 {
+		return nil, 0
+	}
+	if pc.v4 != nil {
+		var cm ipv4.ControlMessage
+		if cm.Parse(oob) != nil {
+			return nil, 0
+		}
+		return cm.Dst, cm.IfIndex
+	}
+	var cm ipv6.ControlMessage
+	if cm.Parse(oob) != nil {
+		return nil, 0
+	}
+	return cm.Dst, cm.IfIndex
+}
+
+// joinGroup joins a multicast group on the named interface ("" for the system default)
+func (pc *packetConn) joinGroup(g group) error {
+	if g.ip == nil || !g.ip.IsMulticast() {
+		return fmt.Errorf("invalid multicast group %v", g.ip)
+	}
+	ifi, err := lookupInterface(g.iface)
+	if err != nil {
+		return err
+	}
+
+	addr := &net.UDPAddr{IP: g.ip}
+	if pc.v4 != nil {
+		if g.ip.To4() == nil {
+			return fmt.Errorf("cannot join IPv6 group %s on an IPv4 socket", g.ip)
+		}
+		return pc.v4.JoinGroup(ifi, addr)
+	}
+	return pc.v6.JoinGroup(ifi, addr)
+}
+
+// setMulticast applies the emitter's multicast send options to both families;
+// errors for the family the socket does not carry are ignored
+func (pc *packetConn) setMulticast(opts options) error {
+	ifi, err := lookupInterface(opts.multicastIface)
+	if err != nil {
+		return err
+	}
+
+	v4, v6 := ipv4.NewPacketConn(pc.UDPConn), ipv6.NewPacketConn(pc.UDPConn)
+	if ifi != nil {
+		err4, err6 := v4.SetMulticastInterface(ifi), v6.SetMulticastInterface(ifi)
+		if err4 != nil && err6 != nil {
+			return fmt.Errorf("failed to set multicast interface %s: %w", ifi.Name, err4)
+		}
+	}
+	v4.SetMulticastTTL(opts.multicastTTL)
+	v6.SetMulticastHopLimit(opts.multicastTTL)
+	v4.SetMulticastLoopback(opts.multicastLoopback)
+	v6.SetMulticastLoopback(opts.multicastLoopback)
+	return nil
+}
+
+// lookupInterface resolves an interface name; "" means the system default (nil)
+func lookupInterface(name string) (*net.Interface, error) {
+	if name == "" {
+		return nil, nil
+	}
+	ifi, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown interface %q: %w", name, err)
+	}
+	return ifi, nil
+}
@@ -0,0 +1,50 @@
+package udp
+
+import "time"
+
+// UDPPacketPayload represents a "net.udp.packet" event (one datagram)
+type UDPPacketPayload struct {
+	// Identity
+	ListenerID string `json:"listener_id"` // ID of the ListenerAdapter that received it
+
+	// Data
+	Data      []byte `json:"data"`                // Datagram contents
+	Truncated bool   `json:"truncated,omitempty"` // Datagram was longer than the maximum size and Data holds only its start
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"`         // Sender IP:port
+	LocalAddr  string `json:"local_addr"`          // Destination IP:port (the group for multicast)
+	Interface  string `json:"interface,omitempty"` // Receiving interface, when known
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When received
+}
+
+// Reply returns a send payload that answers the sender from the socket the
+// datagram arrived on, so the reply comes from the port the peer sent to
+func (p UDPPacketPayload) Reply(data []byte) UDPSendPayload {
+	return UDPSendPayload{
+		Address: p.RemoteAddr,
+		Via:     p.ListenerID,
+		Data:    data,
+	}
+}
+
+// UDPSendPayload represents a "net.udp.send" event
+type UDPSendPayload struct {
+	// Target
+	Address string `json:"address"`       // Destination IP:port (unicast or multicast group)
+	Via     string `json:"via,omitempty"` // ListenerAdapter ID whose socket sends it; the emitter's own socket when empty
+
+	// Data
+	Data []byte `json:"data"` // Datagram contents
+}
+
+// UDPDropsPayload represents a "net.udp.drops" event, published when
+// datagrams were dropped because the bus could not keep up
+type UDPDropsPayload struct {
+	ListenerID string    `json:"listener_id"` // Listener that dropped them
+	Dropped    uint64    `json:"dropped"`     // Drops since the previous report
+	Total      uint64    `json:"total"`       // Drops since the listener started
+	Timestamp  time.Time `json:"timestamp"`   // When reported
+}
@@ -0,0 +1,328 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/gorilla/websocket"
+)
+
+// ErrQueueFull is returned when a message is dropped because a connection's queue is full
+var ErrQueueFull = errors.New("connection queue full")
+
+// message is a queued outbound frame
+type message struct {
+	messageType int
+	data        []byte
+}
+
+// outbox is a connection's bounded outbound queue, drained by its own goroutine
+type outbox struct {
+	conn  *Conn
+	queue chan message
+}
+
+// ClientEmitter sends messages to open WebSocket connections.
+// It consumes "net.websocket.send" events addressed to a connection, a room or
+// all connections, and "net.websocket.join" / "net.websocket.leave" events that
+// manage room membership. Writes go through a bounded queue per connection so a
+// slow client never blocks Emit.
+type ClientEmitter struct {
+	id    string
+	opts  options
+	stats metrics.Counters // Connections are those with an outbox
+
+	mu       sync.Mutex
+	outboxes map[string]*outbox             // connID → outbox
+	rooms    map[string]map[string]struct{} // room → connIDs
+	closed   bool
+
+	dropped atomic.Uint64
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewClientEmitter creates a new WebSocket client emitter
+func NewClientEmitter(opts ...Option) *ClientEmitter {
+	return &ClientEmitter{
+		id:       "websocket-client-emitter",
+		opts:     newOptions(opts),
+		outboxes: make(map[string]*outbox),
+		rooms:    make(map[string]map[string]struct{}),
+		stop:     make(chan struct{}),
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *ClientEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *ClientEmitter) Type() string {
+	return "websocket-client"
+}
+
+// Emit handles send, join and leave events
+func (e *ClientEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	switch evt.Type {
+	case "net.websocket.join", "net.websocket.leave":
+		var payload WebSocketRoomPayload
+		if err := evt.DecodePayload(&payload, c); err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		if evt.Type == "net.websocket.join" {
+			return e.Join(payload.ConnectionID, payload.Room)
+		}
+		e.Leave(payload.ConnectionID, payload.Room)
+		return nil
+
+	default:
+		var payload WebSocketSendPayload
+		if err := evt.DecodePayload(&payload, c); err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+		return e.send(payload)
+	}
+}
+
+// Join adds an open connection to a room
+func (e *ClientEmitter) Join(connectionID, room string) error {
+	conn, ok := GetConnection(connectionID)
+	if !ok {
+		return fmt.Errorf("no connection found for ID %s", connectionID)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := e.outboxLocked(conn); err != nil {
+		return err
+	}
+	members, ok := e.rooms[room]
+	if !ok {
+		members = make(map[string]struct{})
+		e.rooms[room] = members
+	}
+	members[connectionID] = struct{}{}
+	return nil
+}
+
+// Leave removes a connection from a room
+func (e *ClientEmitter) Leave(connectionID, room string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.leaveLocked(connectionID, room)
+}
+
+// Members returns the IDs of the connections in a room
+func (e *ClientEmitter) Members(room string) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ids := make([]string, 0, len(e.rooms[room]))
+	for id := range e.rooms[room] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Dropped returns how many messages were dropped by the slow-consumer policy
+func (e *ClientEmitter) Dropped() uint64 {
+	return e.dropped.Load()
+}
+
+// Metrics returns the emitter's traffic counters. Errors include failed
+// sends and writes; dropped messages are counted by Dropped.
+func (e *ClientEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "websocket", time.Now())
+}
+
+// send queues a message for every targeted connection.
+// Only a unicast send reports a dropped message as an error; room and
+// broadcast drops are counted in Dropped.
+func (e *ClientEmitter) send(payload WebSocketSendPayload) error {
+	msg := message{messageType: payload.MessageType, data: payload.Data}
+	if msg.messageType == 0 {
+		msg.messageType = TextMessage
+	}
+
+	var targets []*Conn
+	switch {
+	case payload.Broadcast:
+		globalConnections.Range(func(_, val any) bool {
+			targets = append(targets, val.(*Conn))
+			return true
+		})
+	case payload.Room != "":
+		e.mu.Lock()
+		for id := range e.rooms[payload.Room] {
+			if conn, ok := GetConnection(id); ok {
+				targets = append(targets, conn)
+			}
+		}
+		e.mu.Unlock()
+	default:
+		conn, ok := GetConnection(payload.ConnectionID)
+		if !ok {
+			return fmt.Errorf("no connection found for ID %s", payload.ConnectionID)
+		}
+		if slices.Contains(payload.Exclude, conn.id) {
+			return nil
+		}
+		return e.enqueue(conn, msg)
+	}
+
+	for _, conn := range targets {
+		if slices.Contains(payload.Exclude, conn.id) {
+			continue
+		}
+		e.enqueue(conn, msg)
+	}
+	return nil
+}
+
+// enqueue puts msg on the connection's queue, applying the slow-consumer policy when full
+func (e *ClientEmitter) enqueue(conn *Conn, msg message) error {
+	e.mu.Lock()
+	box, err := e.outboxLocked(conn)
+	e.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case box.queue <- msg:
+		return nil
+	default:
+	}
+
+	switch e.opts.slowPolicy {
+	case DropNewest:
+		e.dropped.Add(1)
+		return fmt.Errorf("%w: dropped message for %s", ErrQueueFull, conn.id)
+
+	case Disconnect:
+		e.dropped.Add(1)
+		conn.Close(websocket.ClosePolicyViolation, "slow consumer")
+		return fmt.Errorf("%w: disconnected %s", ErrQueueFull, conn.id)
+
+	default: // DropOldest
+		select {
+		case <-box.queue:
+		default:
+		}
+		e.dropped.Add(1)
+		select {
+		case box.queue <- msg:
+			return nil
+		default:
+			return fmt.Errorf("%w: dropped message for %s", ErrQueueFull, conn.id)
+		}
+	}
+}
+
+// outboxLocked returns the connection's outbox, starting its writer on first use.
+// e.mu must be held.
+func (e *ClientEmitter) outboxLocked(conn *Conn) (*outbox, error) {
+	if e.closed {
+		return nil, fmt.Errorf("emitter closed")
+	}
+	if box, ok := e.outboxes[conn.id]; ok {
+		return box, nil
+	}
+
+	box := &outbox{
+		conn:  conn,
+		queue: make(chan message, e.opts.queueSize),
+	}
+	e.outboxes[conn.id] = box
+	e.stats.Opened()
+
+	e.wg.Add(1)
+	go e.writeLoop(box)
+	return box, nil
+}
+
+// writeLoop drains an outbox until its connection closes or the emitter is closed
+func (e *ClientEmitter) writeLoop(box *outbox) {
+	defer e.wg.Done()
+	defer e.forget(box.conn.id)
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-box.conn.Done():
+			return
+		case msg := <-box.queue:
+			if err := box.conn.WriteMessage(msg.messageType, msg.data); err != nil {
+				e.stats.Error()
+				box.conn.Close(websocket.CloseInternalServerErr, "write failed")
+				return
+			}
+			e.stats.Sent(len(msg.data))
+		}
+	}
+}
+
+// forget drops a connection's outbox and room memberships
+func (e *ClientEmitter) forget(connectionID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.outboxes[connectionID]; ok {
+		delete(e.outboxes, connectionID)
+		e.stats.Closed()
+	}
+	for room := range e.rooms {
+		e.leaveLocked(connectionID, room)
+	}
+}
+
+// leaveLocked removes a connection from a room, deleting empty rooms. e.mu must be held.
+func (e *ClientEmitter) leaveLocked(connectionID, room string) {
+	members, ok := e.rooms[room]
+	if !ok {
+		return
+	}
+	delete(members, connectionID)
+	if len(members) == 0 {
+		delete(e.rooms, room)
+	}
+}
+
+// Close stops the writers; queued messages are discarded and connections stay open
+func (e *ClientEmitter) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.stop)
+	e.mu.Unlock()
+
+	e.wg.Wait()
+	return nil
+}
@@ -0,0 +1,203 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/gorilla/websocket"
+)
+
+// dialClient connects to the test server and returns the client and its ConnectionID
+func dialClient(t *testing.T, sub event.Subscription, url string) (*websocket.Conn, string) {
+	t.Helper()
+
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+
+	var open WebSocketOpenPayload
+	nextEvent(t, sub, "net.websocket.open", &open)
+	return ws, open.ConnectionID
+}
+
+// readText reads the next message or returns "" on error.
+// A read timeout breaks the connection, so tests check that nothing else was
+// delivered by sending a marker and reading it back instead.
+func readText(ws *websocket.Conn) string {
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func emitEvent(t *testing.T, emitter *ClientEmitter, eventType string, payload any) error {
+	t.Helper()
+
+	evt, err := codec.NewEvent(eventType, "test", payload, codec.JSON{})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	return emitter.Emit(context.Background(), evt)
+}
+
+func TestClientEmitter_Delivery(t *testing.T) {
+	url, sub := startServer(t)
+
+	alice, aliceID := dialClient(t, sub, url+"/ws")
+	bob, bobID := dialClient(t, sub, url+"/ws")
+	carol, carolID := dialClient(t, sub, url+"/ws")
+
+	emitter := NewClientEmitter()
+	defer emitter.Close()
+
+	marker := func(t *testing.T, connID string) string {
+		t.Helper()
+		emitEvent(t, emitter, "net.websocket.send", WebSocketSendPayload{ConnectionID: connID, Data: []byte("marker")})
+		return "marker"
+	}
+
+	t.Run("unicast", func(t *testing.T) {
+		if err := emitEvent(t, emitter, "net.websocket.send", WebSocketSendPayload{
+			ConnectionID: bobID,
+			Data:         []byte("hi bob"),
+		}); err != nil {
+			t.Fatalf("Emit failed: %v", err)
+		}
+		if got := readText(bob); got != "hi bob" {
+			t.Errorf("Expected bob to receive message, got %q", got)
+		}
+		if want, got := marker(t, aliceID), readText(alice); got != want {
+			t.Errorf("Expected alice to receive nothing, got %q", got)
+		}
+	})
+
+	t.Run("room", func(t *testing.T) {
+		for _, id := range []string{aliceID, bobID} {
+			if err := emitEvent(t, emitter, "net.websocket.join", WebSocketRoomPayload{ConnectionID: id, Room: "lobby"}); err != nil {
+				t.Fatalf("Join failed: %v", err)
+			}
+		}
+		members := emitter.Members("lobby")
+		sort.Strings(members)
+		want := []string{aliceID, bobID}
+		sort.Strings(want)
+		if len(members) != 2 || members[0] != want[0] || members[1] != want[1] {
+			t.Errorf("Expected alice and bob in lobby, got %v", members)
+		}
+
+		emitEvent(t, emitter, "net.websocket.send", WebSocketSendPayload{
+			Room:    "lobby",
+			Exclude: []string{aliceID},
+			Data:    []byte("lobby news"),
+		})
+		if got := readText(bob); got != "lobby news" {
+			t.Errorf("Expected bob to receive room message, got %q", got)
+		}
+		if want, got := marker(t, aliceID), readText(alice); got != want {
+			t.Errorf("Expected excluded alice to receive nothing, got %q", got)
+		}
+		if want, got := marker(t, carolID), readText(carol); got != want {
+			t.Errorf("Expected carol outside the room to receive nothing, got %q", got)
+		}
+
+		emitEvent(t, emitter, "net.websocket.leave", WebSocketRoomPayload{ConnectionID: bobID, Room: "lobby"})
+		if members := emitter.Members("lobby"); len(members) != 1 {
+			t.Errorf("Expected one member after leave, got %v", members)
+		}
+	})
+
+	t.Run("broadcast", func(t *testing.T) {
+		emitEvent(t, emitter, "net.websocket.send", WebSocketSendPayload{
+			Broadcast:   true,
+			MessageType: BinaryMessage,
+			Data:        []byte("all"),
+		})
+		for name, ws := range map[string]*websocket.Conn{"alice": alice, "bob": bob, "carol": carol} {
+			if got := readText(ws); got != "all" {
+				t.Errorf("Expected %s to receive broadcast, got %q", name, got)
+			}
+		}
+	})
+
+	t.Run("closed connection leaves rooms", func(t *testing.T) {
+		carol.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		nextEvent(t, sub, "net.websocket.close", &WebSocketClosePayload{})
+		time.Sleep(50 * time.Millisecond)
+
+		if err := emitter.Join(carolID, "lobby"); err == nil {
+			t.Error("Expected error joining with a closed connection")
+		}
+		if err := emitEvent(t, emitter, "net.websocket.send", WebSocketSendPayload{ConnectionID: carolID}); err == nil {
+			t.Error("Expected error sending to a closed connection")
+		}
+	})
+}
+
+func TestClientEmitter_SlowConsumerPolicy(t *testing.T) {
+	url, sub := startServer(t)
+
+	tests := []struct {
+		name      string
+		policy    SlowConsumerPolicy
+		wantQueue []string
+	}{
+		{name: "drop oldest", policy: DropOldest, wantQueue: []string{"2", "3"}},
+		{name: "drop newest", policy: DropNewest, wantQueue: []string{"1", "2"}},
+		{name: "disconnect", policy: Disconnect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, connID := dialClient(t, sub, url+"/ws")
+			conn, _ := GetConnection(connID)
+
+			emitter := NewClientEmitter(WithQueueSize(2), WithSlowConsumerPolicy(tt.policy))
+			defer emitter.Close()
+
+			// An outbox without a writer behaves like a client that stopped reading
+			box := &outbox{conn: conn, queue: make(chan message, 2)}
+			emitter.outboxes[connID] = box
+
+			var lastErr error
+			for _, data := range []string{"1", "2", "3"} {
+				lastErr = emitter.send(WebSocketSendPayload{ConnectionID: connID, Data: []byte(data)})
+			}
+
+			if tt.policy == DropOldest && lastErr != nil {
+				t.Errorf("Expected no error when dropping oldest, got %v", lastErr)
+			}
+			if tt.policy != DropOldest && !errors.Is(lastErr, ErrQueueFull) {
+				t.Errorf("Expected ErrQueueFull, got %v", lastErr)
+			}
+			if emitter.Dropped() != 1 {
+				t.Errorf("Expected 1 dropped message, got %d", emitter.Dropped())
+			}
+
+			if tt.policy == Disconnect {
+				ws.SetReadDeadline(time.Now().Add(time.Second))
+				if _, _, err := ws.ReadMessage(); !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+					t.Errorf("Expected close 1008, got %v", err)
+				}
+				return
+			}
+
+			close(box.queue)
+			var got []string
+			for msg := range box.queue {
+				got = append(got, string(msg.data))
+			}
+			if len(got) != len(tt.wantQueue) || got[0] != tt.wantQueue[0] || got[1] != tt.wantQueue[1] {
+				t.Errorf("Expected queue %v, got %v", tt.wantQueue, got)
+			}
+		})
+	}
+}
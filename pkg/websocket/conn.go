@@ -0,0 +1,171 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	"github.com/gorilla/websocket"
+)
+
+// Conn is an open WebSocket connection tracked in the connection registry
+type Conn struct {
+	id         string
+	ws         *websocket.Conn
+	remoteAddr string
+	metadata   map[string]string
+	stats      *metrics.Counters // Owning adapter's counters
+
+	// Traffic for connection events
+	bytesIn    atomic.Uint64
+	bytesOut   atomic.Uint64
+	messagesIn atomic.Uint64
+
+	writeTimeout time.Duration
+	writeMu      sync.Mutex
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// newConn wraps an upgraded connection, counting it in stats until closed
+func newConn(id string, ws *websocket.Conn, metadata map[string]string, writeTimeout time.Duration, stats *metrics.Counters) *Conn {
+	stats.Opened()
+	return &Conn{
+		id:           id,
+		ws:           ws,
+		remoteAddr:   ws.RemoteAddr().String(),
+		metadata:     metadata,
+		stats:        stats,
+		writeTimeout: writeTimeout,
+		closed:       make(chan struct{}),
+	}
+}
+
+// ID returns the connection ID
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// RemoteAddr returns the peer's address
+func (c *Conn) RemoteAddr() string {
+	return c.remoteAddr
+}
+
+// Metadata returns a copy of the metadata recorded when the connection opened
+func (c *Conn) Metadata() map[string]string {
+	return maps.Clone(c.metadata)
+}
+
+// Done is closed once the connection is closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.closed
+}
+
+// WriteMessage writes a text or binary message to the peer
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.writeTimeout > 0 {
+		c.ws.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+	if err := c.ws.WriteMessage(messageType, data); err != nil {
+		c.stats.Error()
+		return fmt.Errorf("failed to write to connection %s: %w", c.id, err)
+	}
+	c.stats.Sent(len(data))
+	c.bytesOut.Add(uint64(len(data)))
+	return nil
+}
+
+// Close sends a close frame with code and reason, then closes the connection
+func (c *Conn) Close(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		msg := websocket.FormatCloseMessage(code, reason)
+		c.ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		err = c.ws.Close()
+		close(c.closed)
+		c.stats.Closed()
+	})
+	return err
+}
+
+// readLoop passes incoming messages to onMessage until the connection fails
+// and returns the close code and reason. It enforces the read limit and, when
+// keepalive is enabled, pings the peer and fails after the pong timeout.
+func (c *Conn) readLoop(opts options, onMessage func(messageType int, data []byte)) (int, string) {
+	ws := c.ws
+	ws.SetReadLimit(opts.readLimit)
+
+	if opts.pingInterval > 0 {
+		ws.SetReadDeadline(time.Now().Add(opts.pongTimeout))
+		ws.SetPongHandler(func(string) error {
+			return ws.SetReadDeadline(time.Now().Add(opts.pongTimeout))
+		})
+
+		stop := make(chan struct{})
+		defer close(stop)
+		go c.pingLoop(opts.pingInterval, opts.writeTimeout, stop)
+	}
+
+	for {
+		messageType, data, err := ws.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			switch {
+			case errors.As(err, &closeErr):
+				return closeErr.Code, closeErr.Text
+			case errors.Is(err, websocket.ErrReadLimit):
+				c.Close(websocket.CloseMessageTooBig, "message too big")
+				return websocket.CloseMessageTooBig, "message too big"
+			default:
+				return websocket.CloseAbnormalClosure, err.Error()
+			}
+		}
+
+		if opts.pingInterval > 0 {
+			ws.SetReadDeadline(time.Now().Add(opts.pongTimeout))
+		}
+		c.stats.Received(len(data))
+		c.bytesIn.Add(uint64(len(data)))
+		c.messagesIn.Add(1)
+		onMessage(messageType, data)
+	}
+}
+
+// pingLoop sends keepalive pings until stop is closed. Pings run on real
+// time, like the read deadline they keep from expiring.
+func (c *Conn) pingLoop(interval, writeTimeout time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			deadline := time.Now().Add(writeTimeout)
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Global connection registry (shared by adapters and emitters)
+var globalConnections sync.Map
+
+// GetConnection retrieves an open connection by ID
+func GetConnection(connectionID string) (*Conn, bool) {
+	val, ok := globalConnections.Load(connectionID)
+	if !ok {
+		return nil, false
+	}
+	return val.(*Conn), true
+}
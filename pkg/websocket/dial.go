@@ -0,0 +1,317 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/internal/backoff"
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// ErrNotConnected is returned when sending while a DialAdapter is reconnecting
+var ErrNotConnected = errors.New("websocket not connected")
+
+// DialAdapter connects to a WebSocket server and publishes incoming frames as
+// "net.websocket.message" events. It reconnects with backoff whenever the
+// connection drops, replays subscription messages after each connect, and
+// publishes "net.websocket.connected" / "net.websocket.disconnected" events.
+type DialAdapter struct {
+	id     string
+	url    string
+	dialer websocket.Dialer
+	bus    event.Bus
+	clk    clock.Clock
+	opts   options
+	stats  metrics.Counters
+
+	conn   *Conn // Current connection, nil while reconnecting
+	connMu sync.RWMutex
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewDialAdapter creates an adapter that dials url (ws:// or wss://)
+func NewDialAdapter(url string, opts ...Option) *DialAdapter {
+	o := newOptions(opts)
+	return &DialAdapter{
+		id:   fmt.Sprintf("websocket-dial-%s", url),
+		url:  url,
+		opts: o,
+		clk:  clock.System(),
+		dialer: websocket.Dialer{
+			HandshakeTimeout:  10 * time.Second,
+			EnableCompression: o.compression,
+			Subprotocols:      o.subprotocols,
+			TLSClientConfig:   o.tlsConfig,
+		},
+	}
+}
+
+// ID returns the adapter's unique identifier
+func (a *DialAdapter) ID() string {
+	return a.id
+}
+
+// Type returns the adapter type
+func (a *DialAdapter) Type() string {
+	return "websocket-dial"
+}
+
+// Start begins dialing; connection failures are retried in the background.
+// Event timestamps and redial delays are taken from clk (see clock.From);
+// keepalive pings and socket deadlines use real time.
+func (a *DialAdapter) Start(ctx context.Context, bus event.Bus, clk pipeclock.Clock) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		return fmt.Errorf("adapter already running")
+	}
+
+	a.bus = bus
+	a.clk = clock.From(clk)
+
+	ctx, a.cancel = context.WithCancel(ctx)
+	a.done = make(chan struct{})
+	go a.run(ctx)
+
+	a.running = true
+	return nil
+}
+
+// Stop closes the connection with 1000 (normal closure) and stops reconnecting
+func (a *DialAdapter) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.running {
+		return nil
+	}
+
+	a.cancel()
+	<-a.done
+
+	a.running = false
+	return nil
+}
+
+// Send writes a message on the current connection
+func (a *DialAdapter) Send(messageType int, data []byte) error {
+	conn := a.current()
+	if conn == nil {
+		return fmt.Errorf("%w: %s", ErrNotConnected, a.url)
+	}
+	return conn.WriteMessage(messageType, data)
+}
+
+// current returns the open connection, or nil while reconnecting
+func (a *DialAdapter) current() *Conn {
+	a.connMu.RLock()
+	defer a.connMu.RUnlock()
+	return a.conn
+}
+
+// run dials and serves connections until ctx is cancelled
+func (a *DialAdapter) run(ctx context.Context) {
+	defer close(a.done)
+
+	attempt := 0
+	for {
+		attempt++
+		ws, _, err := a.dialer.DialContext(ctx, a.url, a.opts.headers)
+		if err == nil {
+			connected := a.clk.Now()
+			a.serve(ctx, ws, attempt)
+			if a.clk.Since(connected) >= backoff.StableAfter {
+				attempt = 0
+			}
+		} else if ctx.Err() == nil {
+			a.stats.Error()
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := a.clk.NewTimer(a.opts.backoff.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return
+		case <-wait.C():
+		}
+	}
+}
+
+// serve publishes a connection's events until it drops
+func (a *DialAdapter) serve(ctx context.Context, ws *websocket.Conn, attempt int) {
+	connID := uuid.New().String()
+	conn := newConn(connID, ws, map[string]string{
+		"adapter_id": a.id,
+		"url":        a.url,
+	}, a.opts.writeTimeout, &a.stats)
+	defer conn.Close(websocket.CloseNormalClosure, "")
+
+	// Stop closes the connection, which ends the read loop
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close(websocket.CloseNormalClosure, "")
+	})
+	defer stop()
+
+	if a.opts.compression {
+		ws.EnableWriteCompression(true)
+	}
+
+	// Replay subscriptions before the connection is visible to senders
+	for _, msg := range a.opts.subscriptions {
+		if err := conn.WriteMessage(TextMessage, []byte(msg)); err != nil {
+			a.publish(ctx, "net.websocket.disconnected", connID, WebSocketDialPayload{
+				ConnectionID: connID,
+				URL:          a.url,
+				Attempt:      attempt,
+				Code:         websocket.CloseAbnormalClosure,
+				Reason:       err.Error(),
+				Timestamp:    a.clk.Wall(),
+			})
+			return
+		}
+	}
+
+	a.connMu.Lock()
+	a.conn = conn
+	a.connMu.Unlock()
+
+	a.publish(ctx, "net.websocket.connected", connID, WebSocketDialPayload{
+		ConnectionID: connID,
+		URL:          a.url,
+		Attempt:      attempt,
+		Timestamp:    a.clk.Wall(),
+	})
+
+	code, reason := conn.readLoop(a.opts, func(messageType int, data []byte) {
+		a.publish(ctx, "net.websocket.message", connID, WebSocketMessagePayload{
+			ConnectionID: connID,
+			MessageID:    uuid.New().String(),
+			MessageType:  messageType,
+			Data:         data,
+			RemoteAddr:   conn.remoteAddr,
+			Timestamp:    a.clk.Wall(),
+		})
+	})
+
+	a.connMu.Lock()
+	a.conn = nil
+	a.connMu.Unlock()
+
+	a.publish(ctx, "net.websocket.disconnected", connID, WebSocketDialPayload{
+		ConnectionID: connID,
+		URL:          a.url,
+		Attempt:      attempt,
+		Code:         code,
+		Reason:       reason,
+		Timestamp:    a.clk.Wall(),
+	})
+}
+
+// Metrics returns the adapter's traffic counters. Bytes are message payloads;
+// errors include failed dials.
+func (a *DialAdapter) Metrics() metrics.NetworkMetrics {
+	return a.stats.Snapshot(a.id, a.Type(), "websocket", a.clk.Wall())
+}
+
+// publish encodes payload with the adapter codec and publishes it.
+// Events are published even after ctx is cancelled so the final
+// disconnect is not lost.
+func (a *DialAdapter) publish(ctx context.Context, eventType, connID string, payload any) {
+	evt, err := codec.NewEvent(eventType, a.id, payload, a.opts.codec)
+	if err != nil {
+		return
+	}
+	evt.WithMetadata("adapter_id", a.id).
+		WithMetadata("connection_id", connID).
+		WithMetadata("url", a.url)
+
+	a.bus.Publish(context.WithoutCancel(ctx), evt)
+}
+
+// DialEmitter sends frames on a DialAdapter's connection.
+// It consumes events carrying a WebSocketSendPayload (targets are ignored);
+// register it with a filter for the event type your application uses, such
+// as "net.websocket.dial.send".
+type DialEmitter struct {
+	id      string
+	adapter *DialAdapter
+	opts    options
+	stats   metrics.Counters
+}
+
+// NewDialEmitter creates an emitter bound to adapter's connection
+func NewDialEmitter(adapter *DialAdapter, opts ...Option) *DialEmitter {
+	return &DialEmitter{
+		id:      fmt.Sprintf("websocket-dial-emitter-%s", adapter.url),
+		adapter: adapter,
+		opts:    newOptions(opts),
+	}
+}
+
+// ID returns the emitter's unique identifier
+func (e *DialEmitter) ID() string {
+	return e.id
+}
+
+// Type returns the emitter type
+func (e *DialEmitter) Type() string {
+	return "websocket-dial"
+}
+
+// Emit writes the event's message on the current connection.
+// It returns ErrNotConnected while the adapter is reconnecting.
+func (e *DialEmitter) Emit(ctx context.Context, evt *event.Event) (err error) {
+	defer func() {
+		if err != nil {
+			e.stats.Error()
+		}
+	}()
+
+	c, err := codec.ForEvent(evt, e.opts.codec)
+	if err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var payload WebSocketSendPayload
+	if err := evt.DecodePayload(&payload, c); err != nil {
+		return fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	messageType := payload.MessageType
+	if messageType == 0 {
+		messageType = TextMessage
+	}
+	if err := e.adapter.Send(messageType, payload.Data); err != nil {
+		return err
+	}
+	e.stats.Sent(len(payload.Data))
+	return nil
+}
+
+// Metrics returns the emitter's traffic counters
+func (e *DialEmitter) Metrics() metrics.NetworkMetrics {
+	return e.stats.Snapshot(e.id, e.Type(), "websocket", time.Now())
+}
+
+// Close is a no-op; the connection belongs to the adapter
+func (e *DialEmitter) Close() error {
+	return nil
+}
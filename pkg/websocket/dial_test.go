@@ -0,0 +1,282 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/gorilla/websocket"
+)
+
+// feedServer is an in-process WebSocket server standing in for a third-party feed
+type feedServer struct {
+	*httptest.Server
+	conns   chan *websocket.Conn
+	headers chan http.Header
+}
+
+func newFeedServer(t *testing.T) *feedServer {
+	t.Helper()
+
+	fs := &feedServer{
+		conns:   make(chan *websocket.Conn, 4),
+		headers: make(chan http.Header, 4),
+	}
+	upgrader := websocket.Upgrader{}
+	fs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		fs.headers <- r.Header
+		fs.conns <- ws
+	}))
+	t.Cleanup(fs.Close)
+	return fs
+}
+
+// accept waits for the adapter to connect
+func (fs *feedServer) accept(t *testing.T) *websocket.Conn {
+	t.Helper()
+	select {
+	case ws := <-fs.conns:
+		t.Cleanup(func() { ws.Close() })
+		return ws
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for adapter to connect")
+		return nil
+	}
+}
+
+// startDialAdapter runs adapter and subscribes to its events
+func startDialAdapter(t *testing.T, adapter *DialAdapter) event.Subscription {
+	t.Helper()
+
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	t.Cleanup(func() { adapterMgr.Stop() })
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.websocket.connected", "net.websocket.disconnected", "net.websocket.message"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+	return sub
+}
+
+func TestDialAdapter_ReconnectAndReplay(t *testing.T) {
+	feed := newFeedServer(t)
+	url := "ws" + strings.TrimPrefix(feed.URL, "http")
+
+	adapter := NewDialAdapter(url,
+		WithHeaders(http.Header{"Authorization": []string{"Bearer token"}}),
+		WithSubscriptions(`{"op":"subscribe","channel":"trades"}`),
+		WithBackoff(10*time.Millisecond, 50*time.Millisecond),
+	)
+	sub := startDialAdapter(t, adapter)
+	emitter := NewDialEmitter(adapter)
+
+	server := feed.accept(t)
+	if got := (<-feed.headers).Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Expected Authorization header, got %q", got)
+	}
+	if _, data, _ := server.ReadMessage(); string(data) != `{"op":"subscribe","channel":"trades"}` {
+		t.Errorf("Expected subscription message, got %q", data)
+	}
+
+	var connected WebSocketDialPayload
+	nextEvent(t, sub, "net.websocket.connected", &connected)
+	if connected.URL != url || connected.Attempt != 1 {
+		t.Errorf("Unexpected connected payload: %+v", connected)
+	}
+
+	// Incoming frames become events
+	server.WriteMessage(websocket.TextMessage, []byte("tick"))
+	var msg WebSocketMessagePayload
+	nextEvent(t, sub, "net.websocket.message", &msg)
+	if string(msg.Data) != "tick" || msg.ConnectionID != connected.ConnectionID {
+		t.Errorf("Unexpected message payload: %+v", msg)
+	}
+
+	// The emitter writes on the same connection
+	evt, _ := codec.NewEvent("net.websocket.dial.send", "test", WebSocketSendPayload{Data: []byte("ping")}, codec.JSON{})
+	if err := emitter.Emit(context.Background(), evt); err != nil {
+		t.Fatalf("Emit failed: %v", err)
+	}
+	if _, data, _ := server.ReadMessage(); string(data) != "ping" {
+		t.Errorf("Expected emitted frame, got %q", data)
+	}
+
+	// Dropping the connection triggers a reconnect and a subscription replay
+	server.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4001, "restart"))
+	server.Close()
+
+	var disconnected WebSocketDialPayload
+	nextEvent(t, sub, "net.websocket.disconnected", &disconnected)
+	if disconnected.Code != 4001 || disconnected.Reason != "restart" || disconnected.Attempt != 1 {
+		t.Errorf("Expected close 4001 restart on attempt 1, got %+v", disconnected)
+	}
+
+	server = feed.accept(t)
+	if _, data, _ := server.ReadMessage(); !strings.Contains(string(data), "subscribe") {
+		t.Errorf("Expected subscription replay after reconnect, got %q", data)
+	}
+
+	var reconnected WebSocketDialPayload
+	nextEvent(t, sub, "net.websocket.connected", &reconnected)
+	if reconnected.ConnectionID == connected.ConnectionID {
+		t.Error("Expected a new ConnectionID after reconnect")
+	}
+}
+
+func TestDialAdapter_NotConnected(t *testing.T) {
+	adapter := NewDialAdapter("ws://127.0.0.1:1/feed", WithBackoff(time.Hour, time.Hour))
+	startDialAdapter(t, adapter)
+
+	evt, _ := codec.NewEvent("net.websocket.dial.send", "test", WebSocketSendPayload{Data: []byte("x")}, codec.JSON{})
+	err := NewDialEmitter(adapter).Emit(context.Background(), evt)
+	if !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Expected ErrNotConnected, got %v", err)
+	}
+}
+
+func TestDialAdapter_BackoffEscalatesOnFlappingServer(t *testing.T) {
+	// A server that accepts and immediately drops every connection
+	var accepted atomic.Int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		accepted.Add(1)
+		ws.Close()
+	}))
+	defer server.Close()
+
+	adapter := NewDialAdapter("ws"+strings.TrimPrefix(server.URL, "http"), WithBackoff(20*time.Millisecond, time.Second))
+	startDialAdapter(t, adapter)
+	time.Sleep(500 * time.Millisecond)
+
+	// Delays of 20, 40, 80, 160ms... allow about five connections; resetting
+	// after every connect would allow around twenty-five
+	if got := accepted.Load(); got < 2 || got > 8 {
+		t.Errorf("Expected the redial delay to escalate, got %d connections in 500ms", got)
+	}
+}
+
+func TestDialAdapter_RedialOnClock(t *testing.T) {
+	// A server that accepts and immediately drops every connection
+	var accepted atomic.Int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		accepted.Add(1)
+		ws.Close()
+	}))
+	defer server.Close()
+
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{Types: []string{"net.websocket.connected"}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	start := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	adapter := NewDialAdapter("ws"+strings.TrimPrefix(server.URL, "http"),
+		WithBackoff(time.Second, time.Second),
+		WithKeepalive(0, 0),
+	)
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), clk); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	var connected WebSocketDialPayload
+	nextEvent(t, sub, "net.websocket.connected", &connected)
+	if !connected.Timestamp.Equal(start) {
+		t.Errorf("Expected timestamp %v from the clock, got %v", start, connected.Timestamp)
+	}
+
+	// The redial waits on the fake clock
+	clk.BlockUntil(1)
+	time.Sleep(50 * time.Millisecond)
+	if got := accepted.Load(); got != 1 {
+		t.Errorf("Expected one connection while the clock stands still, got %d", got)
+	}
+
+	clk.Advance(2 * time.Second)
+	nextEvent(t, sub, "net.websocket.connected", &connected)
+	if connected.Attempt != 2 || !connected.Timestamp.Equal(start.Add(2*time.Second)) {
+		t.Errorf("Unexpected redial payload: %+v", connected)
+	}
+}
+
+func TestDialAdapter_KeepaliveOnRealTime(t *testing.T) {
+	feed := newFeedServer(t)
+
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.websocket.connected", "net.websocket.disconnected"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	// The fake clock never moves, yet pings must still hold off the pong timeout
+	adapter := NewDialAdapter("ws"+strings.TrimPrefix(feed.URL, "http"),
+		WithKeepalive(50*time.Millisecond, 150*time.Millisecond),
+	)
+	if err := adapter.Start(context.Background(), eng.ExternalBus(), clock.NewFake(time.Now())); err != nil {
+		t.Fatalf("Failed to start adapter: %v", err)
+	}
+	defer adapter.Stop()
+
+	// The server answers pings (default handler) while reading
+	server := feed.accept(t)
+	go func() {
+		for {
+			if _, _, err := server.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	var connected WebSocketDialPayload
+	nextEvent(t, sub, "net.websocket.connected", &connected)
+
+	select {
+	case evt := <-sub.Events():
+		t.Fatalf("Expected the idle connection to stay open, got %s", evt.Type)
+	case <-time.After(500 * time.Millisecond):
+	}
+	if adapter.current() == nil {
+		t.Error("Expected the connection to survive past the pong timeout")
+	}
+}
@@ -0,0 +1,214 @@
+package websocket
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/internal/backoff"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+)
+
+// Option configures a ServerAdapter or an emitter in this package
+type Option func(*options)
+
+// SlowConsumerPolicy decides what happens when a connection's outbound queue is full
+type SlowConsumerPolicy int
+
+const (
+	// DropOldest discards the oldest queued message to make room
+	DropOldest SlowConsumerPolicy = iota
+	// DropNewest discards the message being sent
+	DropNewest
+	// Disconnect closes the connection with 1008 (policy violation)
+	Disconnect
+)
+
+// options holds the settings shared by adapters and emitters
+type options struct {
+	codec codec.Codec
+	paths []string
+
+	// Limits and keepalive
+	readLimit    int64
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	writeTimeout time.Duration
+
+	// Handshake
+	compression  bool
+	subprotocols []string
+	checkOrigin  func(r *http.Request) bool
+
+	// Dialing
+	headers       http.Header
+	subscriptions []string
+	backoff       backoff.Policy
+	tlsConfig     *tls.Config
+
+	// Outbound queues
+	queueSize  int
+	slowPolicy SlowConsumerPolicy
+
+	connectionEvents bool
+	logger           *slog.Logger
+}
+
+// newOptions applies opts over the defaults
+func newOptions(opts []Option) options {
+	o := options{
+		codec:        codec.JSON{},
+		paths:        []string{"/ws"},
+		readLimit:    1 << 20, // 1MB
+		pingInterval: 30 * time.Second,
+		pongTimeout:  60 * time.Second,
+		writeTimeout: 10 * time.Second,
+		backoff:      backoff.Policy{Min: 500 * time.Millisecond, Max: 30 * time.Second},
+		queueSize:    64,
+		slowPolicy:   DropOldest,
+		logger:       slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCodec sets the payload codec.
+// The ServerAdapter encodes events with it; emitters use it to decode
+// events that carry no "codec" metadata.
+func WithCodec(c codec.Codec) Option {
+	return func(o *options) {
+		if c != nil {
+			o.codec = c
+		}
+	}
+}
+
+// WithPaths sets the paths on which connections are upgraded (default "/ws")
+func WithPaths(paths ...string) Option {
+	return func(o *options) {
+		if len(paths) > 0 {
+			o.paths = paths
+		}
+	}
+}
+
+// WithReadLimit sets the maximum size of an incoming message in bytes.
+// Larger messages close the connection with code 1009 (message too big).
+func WithReadLimit(n int64) Option {
+	return func(o *options) {
+		o.readLimit = n
+	}
+}
+
+// WithKeepalive sends a ping every interval and closes connections that
+// have not answered with a pong (or sent anything else) within timeout.
+// A zero interval disables pings.
+func WithKeepalive(interval, timeout time.Duration) Option {
+	return func(o *options) {
+		o.pingInterval = interval
+		o.pongTimeout = timeout
+	}
+}
+
+// WithWriteTimeout sets the deadline for writing a single message
+func WithWriteTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.writeTimeout = d
+	}
+}
+
+// WithCompression enables permessage-deflate negotiation (RFC 7692)
+func WithCompression() Option {
+	return func(o *options) {
+		o.compression = true
+	}
+}
+
+// WithSubprotocols sets the subprotocols offered during the handshake, in order of preference
+func WithSubprotocols(protocols ...string) Option {
+	return func(o *options) {
+		o.subprotocols = protocols
+	}
+}
+
+// WithCheckOrigin sets the function that accepts or rejects a handshake by
+// its Origin header. By default cross-origin handshakes are rejected.
+func WithCheckOrigin(fn func(r *http.Request) bool) Option {
+	return func(o *options) {
+		o.checkOrigin = fn
+	}
+}
+
+// WithHeaders sets extra headers sent with the DialAdapter's handshake
+// (e.g. Authorization)
+func WithHeaders(headers http.Header) Option {
+	return func(o *options) {
+		o.headers = headers
+	}
+}
+
+// WithSubscriptions sets text messages the DialAdapter sends after every
+// successful (re)connect, in order, before publishing any incoming frames
+func WithSubscriptions(messages ...string) Option {
+	return func(o *options) {
+		o.subscriptions = messages
+	}
+}
+
+// WithBackoff sets the DialAdapter's reconnect delay. The delay starts at min,
+// doubles after each failed attempt (or connection that drops within 10
+// seconds) up to max, and has ±20% jitter. A min of zero or less, or a max
+// below min, is ignored.
+func WithBackoff(min, max time.Duration) Option {
+	return func(o *options) {
+		if p := (backoff.Policy{Min: min, Max: max}); p.Valid() {
+			o.backoff = p
+		}
+	}
+}
+
+// WithTLSConfig sets the TLS configuration for wss:// URLs
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithQueueSize sets how many messages the emitter queues per connection
+func WithQueueSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.queueSize = n
+		}
+	}
+}
+
+// WithSlowConsumerPolicy sets what the emitter does when a connection's queue is full
+func WithSlowConsumerPolicy(p SlowConsumerPolicy) Option {
+	return func(o *options) {
+		o.slowPolicy = p
+	}
+}
+
+// WithConnectionEvents makes the ServerAdapter also publish
+// "net.connection.open" and "net.connection.close" events with a
+// ConnectionPayload, alongside its "net.websocket.open" and
+// "net.websocket.close" events
+func WithConnectionEvents() Option {
+	return func(o *options) {
+		o.connectionEvents = true
+	}
+}
+
+// WithLogger sets the logger the ServerAdapter writes server errors to
+// (defaults to slog.Default())
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
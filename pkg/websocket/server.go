@@ -0,0 +1,318 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/clock"
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	"github.com/BYTE-6D65/netadapters/pkg/metrics"
+	pipeclock "github.com/BYTE-6D65/pipeline/pkg/clock"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// ServerAdapter accepts WebSocket connections and publishes their lifecycle
+// and messages as "net.websocket.open", "net.websocket.message" and
+// "net.websocket.close" events
+type ServerAdapter struct {
+	id       string
+	addr     string
+	server   *http.Server
+	listener net.Listener // nil without an address
+	upgrader websocket.Upgrader
+	bus      event.Bus
+	clk      clock.Clock
+	ctx      context.Context
+	opts     options
+	stats    metrics.Counters
+
+	conns sync.Map // connID → *Conn, connections opened by this adapter
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewServerAdapter creates a new WebSocket server adapter.
+// With an empty addr the adapter opens no port of its own and serves only the
+// connections handed to ServeUpgrade (see the HTTP adapter's WithUpgrade).
+func NewServerAdapter(addr string, opts ...Option) *ServerAdapter {
+	o := newOptions(opts)
+	return &ServerAdapter{
+		id:   fmt.Sprintf("websocket-server-%s", addr),
+		addr: addr,
+		opts: o,
+		clk:  clock.System(),
+		upgrader: websocket.Upgrader{
+			EnableCompression: o.compression,
+			Subprotocols:      o.subprotocols,
+			CheckOrigin:       o.checkOrigin,
+		},
+	}
+}
+
+// ID returns the adapter's unique identifier
+func (a *ServerAdapter) ID() string {
+	return a.id
+}
+
+// Type returns the adapter type
+func (a *ServerAdapter) Type() string {
+	return "websocket-server"
+}
+
+// Addr returns the address the adapter listens on (useful with port 0),
+// or nil before Start and without an address
+func (a *ServerAdapter) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.listener == nil {
+		return nil
+	}
+	return a.listener.Addr()
+}
+
+// Start begins accepting WebSocket connections. Event timestamps are taken
+// from clk (see clock.From); keepalive pings and socket deadlines use real
+// time.
+func (a *ServerAdapter) Start(ctx context.Context, bus event.Bus, clk pipeclock.Clock) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		return fmt.Errorf("adapter already running")
+	}
+
+	// Without an address, connections arrive through ServeUpgrade only
+	var ln net.Listener
+	if a.addr != "" {
+		var err error
+		if ln, err = net.Listen("tcp", a.addr); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", a.addr, err)
+		}
+	}
+
+	a.bus = bus
+	a.clk = clock.From(clk)
+	a.ctx = ctx
+	a.listener = ln
+	a.running = true
+
+	if ln == nil {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	for _, path := range a.opts.paths {
+		mux.Handle(path, a)
+	}
+	a.server = &http.Server{
+		Addr:     a.addr,
+		Handler:  mux,
+		ErrorLog: slog.NewLogLogger(a.opts.logger.Handler(), slog.LevelError),
+	}
+
+	// Start server in goroutine
+	server := a.server
+	go func() {
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			a.opts.logger.Error("WebSocket server failed", "adapter_id", a.id, "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop shuts down the server and closes open connections with 1001 (going away)
+func (a *ServerAdapter) Stop() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.running {
+		return nil
+	}
+
+	var err error
+	if a.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = a.server.Shutdown(ctx)
+	}
+
+	// Hijacked connections are not tracked by Shutdown
+	a.conns.Range(func(_, val any) bool {
+		val.(*Conn).Close(websocket.CloseGoingAway, "server shutting down")
+		return true
+	})
+	a.wg.Wait()
+
+	a.running = false
+	return err
+}
+
+// ServeHTTP upgrades the request and serves the connection until it closes
+func (a *ServerAdapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.ServeUpgrade(w, r, nil)
+}
+
+// ServeUpgrade upgrades a request handed over by another server (such as the
+// HTTP ServerAdapter's WithUpgrade) and serves the connection until it closes.
+// metadata is recorded on the connection; its "request_id" and "principal"
+// are also added to every event the connection publishes.
+func (a *ServerAdapter) ServeUpgrade(w http.ResponseWriter, r *http.Request, metadata map[string]string) {
+	a.mu.Lock()
+	running := a.running
+	if running {
+		a.wg.Add(1)
+	}
+	a.mu.Unlock()
+
+	if !running {
+		http.Error(w, "WebSocket adapter not running", http.StatusServiceUnavailable)
+		return
+	}
+	defer a.wg.Done()
+
+	ws, err := a.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade already wrote an error response
+	}
+	a.serve(ws, r, metadata)
+}
+
+// serve registers the connection, publishes its events and unregisters it when it closes
+func (a *ServerAdapter) serve(ws *websocket.Conn, r *http.Request, metadata map[string]string) {
+	connID := uuid.New().String()
+
+	headers := make(map[string]string)
+	for key, values := range r.Header {
+		if len(values) > 0 {
+			headers[key] = values[0] // Take first value
+		}
+	}
+
+	localAddr := ""
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		localAddr = addr.String()
+	}
+
+	connMetadata := maps.Clone(metadata)
+	if connMetadata == nil {
+		connMetadata = make(map[string]string)
+	}
+	connMetadata["adapter_id"] = a.id
+	connMetadata["path"] = r.URL.Path
+
+	conn := newConn(connID, ws, connMetadata, a.opts.writeTimeout, &a.stats)
+	globalConnections.Store(connID, conn)
+	a.conns.Store(connID, conn)
+	defer func() {
+		globalConnections.Delete(connID)
+		a.conns.Delete(connID)
+		conn.Close(websocket.CloseNormalClosure, "")
+	}()
+
+	if a.opts.compression {
+		ws.EnableWriteCompression(true)
+	}
+
+	a.publish("net.websocket.open", conn, WebSocketOpenPayload{
+		ConnectionID: connID,
+		Path:         r.URL.Path,
+		Headers:      headers,
+		Subprotocol:  ws.Subprotocol(),
+		Compressed:   a.opts.compression && offersCompression(r),
+		RequestID:    connMetadata["request_id"],
+		Principal:    connMetadata["principal"],
+		RemoteAddr:   conn.remoteAddr,
+		LocalAddr:    localAddr,
+		Timestamp:    a.clk.Wall(),
+	})
+	a.connectionEvent("net.connection.open", "new", conn, localAddr, r.TLS != nil)
+
+	code, reason := conn.readLoop(a.opts, func(messageType int, data []byte) {
+		a.publish("net.websocket.message", conn, WebSocketMessagePayload{
+			ConnectionID: connID,
+			MessageID:    uuid.New().String(),
+			MessageType:  messageType,
+			Data:         data,
+			RemoteAddr:   conn.remoteAddr,
+			Timestamp:    a.clk.Wall(),
+		})
+	})
+
+	a.publish("net.websocket.close", conn, WebSocketClosePayload{
+		ConnectionID: connID,
+		Code:         code,
+		Reason:       reason,
+		RemoteAddr:   conn.remoteAddr,
+		Timestamp:    a.clk.Wall(),
+	})
+	a.connectionEvent("net.connection.close", "closed", conn, localAddr, r.TLS != nil)
+}
+
+// connectionEvent publishes a connection lifecycle event when enabled
+func (a *ServerAdapter) connectionEvent(eventType, state string, conn *Conn, localAddr string, isTLS bool) {
+	if !a.opts.connectionEvents {
+		return
+	}
+	a.publish(eventType, conn, ConnectionPayload{
+		ConnectionID: conn.id,
+		State:        state,
+		Protocol:     "websocket",
+		RemoteAddr:   conn.remoteAddr,
+		LocalAddr:    localAddr,
+		TLS:          isTLS,
+		BytesIn:      conn.bytesIn.Load(),
+		BytesOut:     conn.bytesOut.Load(),
+		Requests:     conn.messagesIn.Load(),
+		Timestamp:    a.clk.Wall(),
+	})
+}
+
+// Metrics returns the adapter's traffic counters. Bytes are message payloads,
+// including messages written by emitters to this adapter's connections.
+func (a *ServerAdapter) Metrics() metrics.NetworkMetrics {
+	return a.stats.Snapshot(a.id, a.Type(), "websocket", a.clk.Wall())
+}
+
+// publish encodes payload with the adapter codec and publishes it
+func (a *ServerAdapter) publish(eventType string, conn *Conn, payload any) {
+	evt, err := codec.NewEvent(eventType, a.id, payload, a.opts.codec)
+	if err != nil {
+		return
+	}
+	evt.WithMetadata("adapter_id", a.id).
+		WithMetadata("connection_id", conn.id)
+	for _, key := range []string{"request_id", "principal"} {
+		if value := conn.metadata[key]; value != "" {
+			evt.WithMetadata(key, value)
+		}
+	}
+
+	a.bus.Publish(a.ctx, evt)
+}
+
+// offersCompression reports whether the client offered permessage-deflate,
+// which the upgrader accepts whenever compression is enabled
+func offersCompression(r *http.Request) bool {
+	for _, header := range r.Header.Values("Sec-WebSocket-Extensions") {
+		for _, ext := range strings.Split(header, ",") {
+			name, _, _ := strings.Cut(ext, ";")
+			if strings.EqualFold(strings.TrimSpace(name), "permessage-deflate") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,321 @@
+package websocket
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BYTE-6D65/netadapters/pkg/codec"
+	nethttp "github.com/BYTE-6D65/netadapters/pkg/http"
+	"github.com/BYTE-6D65/pipeline/pkg/engine"
+	"github.com/BYTE-6D65/pipeline/pkg/event"
+	"github.com/gorilla/websocket"
+)
+
+// startServer runs a ServerAdapter on a free port, subscribes to all
+// WebSocket events and returns the server's ws:// base URL
+func startServer(t *testing.T, opts ...Option) (string, event.Subscription) {
+	t.Helper()
+
+	eng := engine.New()
+	t.Cleanup(func() { eng.Shutdown(context.Background()) })
+
+	adapter := NewServerAdapter("127.0.0.1:0", opts...)
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(adapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	t.Cleanup(func() { adapterMgr.Stop() })
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.websocket.open", "net.websocket.message", "net.websocket.close", "net.connection.open", "net.connection.close"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+
+	return "ws://" + adapter.Addr().String(), sub
+}
+
+// nextEvent waits for the next event of the given type and decodes its payload
+func nextEvent(t *testing.T, sub event.Subscription, eventType string, payload any) *event.Event {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case evt := <-sub.Events():
+			if evt.Type != eventType {
+				continue
+			}
+			if err := codec.Decode(evt, payload); err != nil {
+				t.Fatalf("Failed to decode %s: %v", eventType, err)
+			}
+			return evt
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %s", eventType)
+			return nil
+		}
+	}
+}
+
+func TestServerAdapter_Lifecycle(t *testing.T) {
+	url, sub := startServer(t)
+
+	header := http.Header{"X-Client": []string{"test"}}
+	ws, _, err := websocket.DefaultDialer.Dial(url+"/ws", header)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer ws.Close()
+
+	var open WebSocketOpenPayload
+	nextEvent(t, sub, "net.websocket.open", &open)
+	if open.ConnectionID == "" || open.Path != "/ws" {
+		t.Errorf("Unexpected open payload: %+v", open)
+	}
+	if open.Headers["X-Client"] != "test" {
+		t.Errorf("Expected handshake headers, got %v", open.Headers)
+	}
+	if _, ok := GetConnection(open.ConnectionID); !ok {
+		t.Error("Expected connection in registry")
+	}
+
+	ws.WriteMessage(websocket.TextMessage, []byte("hello"))
+	ws.WriteMessage(websocket.BinaryMessage, []byte{0x01, 0x02})
+
+	var text, binary WebSocketMessagePayload
+	evt := nextEvent(t, sub, "net.websocket.message", &text)
+	nextEvent(t, sub, "net.websocket.message", &binary)
+	if text.MessageType != TextMessage || string(text.Data) != "hello" {
+		t.Errorf("Unexpected text message: %+v", text)
+	}
+	if binary.MessageType != BinaryMessage || len(binary.Data) != 2 {
+		t.Errorf("Unexpected binary message: %+v", binary)
+	}
+	if evt.Metadata["connection_id"] != open.ConnectionID {
+		t.Errorf("Expected connection_id metadata, got %q", evt.Metadata["connection_id"])
+	}
+
+	ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4000, "bye"))
+
+	var closed WebSocketClosePayload
+	nextEvent(t, sub, "net.websocket.close", &closed)
+	if closed.Code != 4000 || closed.Reason != "bye" {
+		t.Errorf("Expected close 4000 bye, got %d %q", closed.Code, closed.Reason)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := GetConnection(open.ConnectionID); ok {
+		t.Error("Expected connection to be removed from registry")
+	}
+}
+
+func TestServerAdapter_ConnectionEvents(t *testing.T) {
+	url, sub := startServer(t, WithConnectionEvents())
+
+	ws, _, err := websocket.DefaultDialer.Dial(url+"/ws", nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer ws.Close()
+
+	var open ConnectionPayload
+	nextEvent(t, sub, "net.connection.open", &open)
+	if open.ConnectionID == "" || open.State != "new" || open.Protocol != "websocket" || open.LocalAddr == "" {
+		t.Errorf("Unexpected open payload: %+v", open)
+	}
+
+	ws.WriteMessage(websocket.TextMessage, []byte("hello"))
+	nextEvent(t, sub, "net.websocket.message", &WebSocketMessagePayload{})
+	conn, _ := GetConnection(open.ConnectionID)
+	if err := conn.WriteMessage(TextMessage, []byte("hi")); err != nil {
+		t.Fatalf("Failed to write message: %v", err)
+	}
+
+	ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	var closed ConnectionPayload
+	nextEvent(t, sub, "net.connection.close", &closed)
+	if closed.ConnectionID != open.ConnectionID || closed.State != "closed" || closed.TLS {
+		t.Errorf("Unexpected close payload: %+v", closed)
+	}
+	if closed.BytesIn != 5 || closed.BytesOut != 2 || closed.Requests != 1 {
+		t.Errorf("Expected 5 bytes in, 2 out and 1 message, got %+v", closed)
+	}
+}
+
+func TestServerAdapter_ReadLimit(t *testing.T) {
+	url, sub := startServer(t, WithReadLimit(16))
+
+	ws, _, err := websocket.DefaultDialer.Dial(url+"/ws", nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer ws.Close()
+
+	ws.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 64)))
+
+	var closed WebSocketClosePayload
+	nextEvent(t, sub, "net.websocket.close", &closed)
+	if closed.Code != websocket.CloseMessageTooBig {
+		t.Errorf("Expected close code 1009, got %d", closed.Code)
+	}
+
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err = ws.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Errorf("Expected client to receive 1009, got %v", err)
+	}
+}
+
+func TestServerAdapter_KeepaliveAndCompression(t *testing.T) {
+	url, sub := startServer(t,
+		WithPaths("/feed"),
+		WithKeepalive(50*time.Millisecond, 200*time.Millisecond),
+		WithCompression(),
+	)
+
+	dialer := websocket.Dialer{EnableCompression: true}
+	ws, resp, err := dialer.Dial(url+"/feed", nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer ws.Close()
+
+	if !strings.Contains(resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate") {
+		t.Errorf("Expected permessage-deflate to be negotiated, got %q", resp.Header.Get("Sec-WebSocket-Extensions"))
+	}
+	var open WebSocketOpenPayload
+	nextEvent(t, sub, "net.websocket.open", &open)
+	if !open.Compressed {
+		t.Error("Expected open event to report compression")
+	}
+
+	// The client answers pings (default handler) only while reading
+	pings := make(chan struct{}, 10)
+	ws.SetPingHandler(func(data string) error {
+		pings <- struct{}{}
+		return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	go func() {
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	time.Sleep(400 * time.Millisecond)
+	if len(pings) < 2 {
+		t.Errorf("Expected keepalive pings, got %d", len(pings))
+	}
+
+	// Answered pings keep the connection open past the pong timeout
+	if _, ok := GetConnection(open.ConnectionID); !ok {
+		t.Error("Expected connection to stay open while answering pings")
+	}
+}
+
+func TestServerAdapter_PongTimeout(t *testing.T) {
+	url, sub := startServer(t, WithKeepalive(50*time.Millisecond, 150*time.Millisecond))
+
+	ws, _, err := websocket.DefaultDialer.Dial(url+"/ws", nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer ws.Close()
+
+	// Never read, so pings are never answered
+	var closed WebSocketClosePayload
+	nextEvent(t, sub, "net.websocket.close", &closed)
+	if closed.Code != websocket.CloseAbnormalClosure {
+		t.Errorf("Expected close code 1006 after pong timeout, got %d", closed.Code)
+	}
+}
+
+func TestServerAdapter_SharedPort(t *testing.T) {
+	wsAdapter := NewServerAdapter("")
+	httpAdapter := nethttp.NewServerAdapter(":18096",
+		nethttp.WithAuthenticator(nethttp.AuthenticatorFunc(func(r *http.Request) (string, error) {
+			return r.URL.Query().Get("user"), nil
+		})),
+		nethttp.WithUpgrade("/ws", wsAdapter),
+	)
+
+	eng := engine.New()
+	defer eng.Shutdown(context.Background())
+
+	adapterMgr := engine.NewAdapterManager(eng)
+	if err := adapterMgr.Register(wsAdapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Register(httpAdapter); err != nil {
+		t.Fatalf("Failed to register adapter: %v", err)
+	}
+	if err := adapterMgr.Start(); err != nil {
+		t.Fatalf("Failed to start adapters: %v", err)
+	}
+	defer adapterMgr.Stop()
+
+	sub, err := eng.ExternalBus().Subscribe(context.Background(), event.Filter{
+		Types: []string{"net.websocket.open", "net.websocket.message"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws://localhost:18096/ws?user=alice", http.Header{"X-Client": {"test"}})
+	if err != nil {
+		t.Fatalf("Failed to dial through the HTTP adapter: %v", err)
+	}
+	defer ws.Close()
+
+	var open WebSocketOpenPayload
+	nextEvent(t, sub, "net.websocket.open", &open)
+	if open.Principal != "alice" || open.RequestID == "" {
+		t.Errorf("Expected principal and request ID from the handshake, got %+v", open)
+	}
+
+	conn, ok := GetConnection(open.ConnectionID)
+	if !ok {
+		t.Fatal("Expected connection in registry")
+	}
+	metadata := conn.Metadata()
+	if metadata["header.X-Client"] != "test" || metadata["request_id"] != open.RequestID {
+		t.Errorf("Expected handshake data in connection metadata, got %v", metadata)
+	}
+
+	ws.WriteMessage(websocket.TextMessage, []byte("hello"))
+	var msg WebSocketMessagePayload
+	evt := nextEvent(t, sub, "net.websocket.message", &msg)
+	if evt.Metadata["principal"] != "alice" {
+		t.Errorf("Expected principal on message events, got %v", evt.Metadata)
+	}
+}
+
+func TestServerAdapter_ListenError(t *testing.T) {
+	// Occupy the port so the server fails to listen
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	adapter := NewServerAdapter(ln.Addr().String())
+	if err := adapter.Start(context.Background(), nil, nil); err == nil {
+		adapter.Stop()
+		t.Fatal("Expected Start to fail on a port in use")
+	}
+	if adapter.Addr() != nil {
+		t.Errorf("Expected no address after a failed Start, got %v", adapter.Addr())
+	}
+}
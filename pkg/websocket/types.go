@@ -0,0 +1,132 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Message types (RFC 6455 opcodes)
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+)
+
+// WebSocketOpenPayload represents a "net.websocket.open" event
+type WebSocketOpenPayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for connection
+
+	// Handshake data
+	Path        string            `json:"path"`        // /ws
+	Headers     map[string]string `json:"headers"`     // Upgrade request headers
+	Subprotocol string            `json:"subprotocol"` // Negotiated subprotocol, if any
+	Compressed  bool              `json:"compressed"`  // permessage-deflate negotiated?
+
+	// Set when the handshake was handed over by the HTTP ServerAdapter
+	RequestID string `json:"request_id,omitempty"` // HTTP request ID of the handshake
+	Principal string `json:"principal,omitempty"`  // Authenticated caller
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Client IP:port
+	LocalAddr  string `json:"local_addr"`  // Server IP:port
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When opened
+}
+
+// WebSocketMessagePayload represents a "net.websocket.message" event
+type WebSocketMessagePayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for connection
+	MessageID    string `json:"message_id"`    // UUID for message
+
+	// Message data
+	MessageType int    `json:"message_type"` // TextMessage or BinaryMessage
+	Data        []byte `json:"data"`         // Message content
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Client IP:port
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When received
+}
+
+// WebSocketClosePayload represents a "net.websocket.close" event
+type WebSocketClosePayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for connection
+
+	// Close data
+	Code   int    `json:"code"`   // Close code, 1006 when the connection dropped without one
+	Reason string `json:"reason"` // Close reason sent by the peer
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Client IP:port
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When closed
+}
+
+// WebSocketSendPayload represents a "net.websocket.send" event.
+// Exactly one target is used: Broadcast, then Room, then ConnectionID.
+type WebSocketSendPayload struct {
+	// Target
+	ConnectionID string   `json:"connection_id,omitempty"` // Single connection
+	Room         string   `json:"room,omitempty"`          // All members of a room
+	Broadcast    bool     `json:"broadcast,omitempty"`     // All open connections
+	Exclude      []string `json:"exclude,omitempty"`       // Connection IDs to skip (e.g. the sender)
+
+	// Message data
+	MessageType int    `json:"message_type"` // TextMessage or BinaryMessage (default TextMessage)
+	Data        []byte `json:"data"`         // Message content
+}
+
+// WebSocketRoomPayload represents a "net.websocket.join" or "net.websocket.leave" event
+type WebSocketRoomPayload struct {
+	ConnectionID string `json:"connection_id"` // Connection joining or leaving
+	Room         string `json:"room"`          // Room name
+}
+
+// WebSocketDialPayload represents a "net.websocket.connected" or
+// "net.websocket.disconnected" event from a DialAdapter
+type WebSocketDialPayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for this connection (new after each reconnect)
+	URL          string `json:"url"`           // Dialed URL
+
+	// Reconnect state
+	Attempt int `json:"attempt"` // Dial attempts since the last successful connection
+
+	// Disconnect data
+	Code   int    `json:"code,omitempty"`   // Close code, 1006 when the connection dropped without one
+	Reason string `json:"reason,omitempty"` // Close reason or error
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When connected or disconnected
+}
+
+// ConnectionPayload represents a "net.connection.open" or
+// "net.connection.close" event, in the shape the HTTP ServerAdapter uses.
+// Counters are totals for the connection so far.
+type ConnectionPayload struct {
+	// Identity
+	ConnectionID string `json:"connection_id"` // UUID for connection
+
+	// State
+	State    string `json:"state"`    // new or closed
+	Protocol string `json:"protocol"` // websocket
+
+	// Network data
+	RemoteAddr string `json:"remote_addr"` // Client IP:port
+	LocalAddr  string `json:"local_addr"`  // Server IP:port
+	TLS        bool   `json:"tls"`         // Handshake over HTTPS?
+
+	// Traffic
+	BytesIn  uint64 `json:"bytes_in"`  // Message bytes received
+	BytesOut uint64 `json:"bytes_out"` // Message bytes written
+	Requests uint64 `json:"requests"`  // Messages received
+
+	// Metadata
+	Timestamp time.Time `json:"timestamp"` // When the state changed
+}